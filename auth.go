@@ -0,0 +1,142 @@
+package main
+
+import (
+	"encoding/base64"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const (
+	authNone   = "none"
+	authBearer = "bearer"
+	authBasic  = "basic"
+)
+
+var authTypes = []string{authNone, authBearer, authBasic}
+
+// AuthConfig is the auth helper's selection for a request. With a type
+// other than none it sets the Authorization header on send, replacing one
+// typed in the headers panel.
+type AuthConfig struct {
+	Type     string `json:"type"`
+	Token    string `json:"token,omitempty"`
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+}
+
+// header returns the Authorization value for the selection, or "" when
+// the headers panel should be left alone. resolve is applied to each
+// field so credentials can use {{VARIABLE}} placeholders.
+func (a *AuthConfig) header(resolve func(string) string) string {
+	if a == nil {
+		return ""
+	}
+	switch a.Type {
+	case authBearer:
+		return "Bearer " + resolve(a.Token)
+	case authBasic:
+		creds := resolve(a.Username) + ":" + resolve(a.Password)
+		return "Basic " + base64.StdEncoding.EncodeToString([]byte(creds))
+	}
+	return ""
+}
+
+// label names the selection for the headers panel title.
+func (a *AuthConfig) label() string {
+	if a == nil || a.Type == authNone || a.Type == "" {
+		return ""
+	}
+	return strings.ToUpper(a.Type[:1]) + a.Type[1:]
+}
+
+func (m Model) openAuth() Model {
+	m.showAuth = true
+	m.authCursor = 0
+	if m.auth != nil {
+		for i, t := range authTypes {
+			if t == m.auth.Type {
+				m.authCursor = i
+			}
+		}
+	}
+	return m
+}
+
+func (m Model) updateAuth(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, keys.Quit):
+		return m.quit()
+
+	case key.Matches(msg, keys.Cancel), key.Matches(msg, keys.Auth):
+		m.showAuth = false
+
+	case key.Matches(msg, keys.Up):
+		if m.authCursor > 0 {
+			m.authCursor--
+		}
+
+	case key.Matches(msg, keys.Down):
+		if m.authCursor < len(authTypes)-1 {
+			m.authCursor++
+		}
+
+	case key.Matches(msg, keys.Enter):
+		m.showAuth = false
+		switch authTypes[m.authCursor] {
+		case authBearer:
+			token := ""
+			if m.auth != nil {
+				token = m.auth.Token
+			}
+			return m.openPrompt(promptAuthToken, "Bearer token: ", token)
+		case authBasic:
+			username := ""
+			if m.auth != nil {
+				username = m.auth.Username
+			}
+			return m.openPrompt(promptAuthUsername, "Username: ", username)
+		default:
+			m.auth = nil
+			m.statusMessage = "Auth helper off"
+		}
+	}
+	return m, nil
+}
+
+// submitAuth handles the auth helper's prompts. Basic auth asks for the
+// username first and keeps it in pendingAuth until the password is in.
+func (m Model) submitAuth(kind int, value string) (tea.Model, tea.Cmd) {
+	switch kind {
+	case promptAuthToken:
+		m.auth = &AuthConfig{Type: authBearer, Token: strings.TrimSpace(value)}
+		m.statusMessage = "Using Bearer auth"
+
+	case promptAuthUsername:
+		m.pendingAuth = AuthConfig{Type: authBasic, Username: strings.TrimSpace(value)}
+		return m.openPrompt(promptAuthPassword, "Password: ", "")
+
+	case promptAuthPassword:
+		auth := m.pendingAuth
+		auth.Password = value
+		m.auth = &auth
+		m.pendingAuth = AuthConfig{}
+		m.statusMessage = "Using Basic auth as " + auth.Username
+	}
+	return m, nil
+}
+
+func (m Model) renderAuth() string {
+	var sb strings.Builder
+	sb.WriteString("Auth (↑/↓: select • enter: choose • esc: close):\n")
+	for i, t := range authTypes {
+		marker := "  "
+		if i == m.authCursor {
+			marker = "▸ "
+		}
+		name := strings.ToUpper(t[:1]) + t[1:]
+		sb.WriteString(marker + name + "\n")
+	}
+	return sb.String()
+}
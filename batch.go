@@ -0,0 +1,50 @@
+package main
+
+import "sync"
+
+// defaultMaxConcurrency caps batch operations when Config.MaxConcurrency is
+// unset. It is deliberately conservative so repeat runs and dashboards
+// don't hammer the target server.
+const defaultMaxConcurrency = 4
+
+// runBatch calls job for every index in [0, count) with at most limit calls
+// in flight. Results are returned in index order regardless of completion
+// order, so reports built from them are deterministic.
+func runBatch(count, limit int, job func(i int) Response) []Response {
+	if limit <= 0 {
+		limit = defaultMaxConcurrency
+	}
+
+	results := make([]Response, count)
+	sem := make(chan struct{}, limit)
+	var wg sync.WaitGroup
+
+	for i := 0; i < count; i++ {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			results[i] = job(i)
+		}(i)
+	}
+
+	wg.Wait()
+	return results
+}
+
+// maxConcurrency returns the configured batch concurrency limit, falling
+// back to defaultMaxConcurrency when unset or invalid.
+func (cm *ConfigManager) maxConcurrency() int {
+	if cm == nil {
+		return defaultMaxConcurrency
+	}
+
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+
+	if cm.Config.MaxConcurrency <= 0 {
+		return defaultMaxConcurrency
+	}
+	return cm.Config.MaxConcurrency
+}
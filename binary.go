@@ -0,0 +1,90 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// binarySample is how much of a body isBinaryBody inspects.
+const binarySample = 32 * 1024
+
+// isBinaryBody reports whether body is binary rather than text: it isn't
+// valid text as UTF-8, in a charset declared by contentType, or in any of
+// the encodings tryAlternativeEncodings knows.
+func isBinaryBody(contentType string, body []byte) bool {
+	if len(body) == 0 {
+		return false
+	}
+	if idx := strings.LastIndex(strings.ToLower(contentType), "charset="); idx != -1 {
+		charset := strings.ToLower(strings.Trim(strings.TrimSpace(contentType[idx+8:]), `"`))
+		if charset != "utf-8" && charset != "utf8" {
+			return false // decodeCharset handles it
+		}
+	}
+
+	sample := body[:min(len(body), binarySample)]
+	if bytes.IndexByte(sample, 0) != -1 {
+		return true
+	}
+	if looksLikeText(sample) {
+		return false
+	}
+	// tryAlternativeEncodings returns sample unchanged when nothing
+	// decodes it, which fails again
+	return !looksLikeText(tryAlternativeEncodings(sample))
+}
+
+// looksLikeText reports whether at most a tenth of b's characters are
+// control characters or invalid UTF-8. A character cut in half at the end
+// of b doesn't count.
+func looksLikeText(b []byte) bool {
+	total, bad := 0, 0
+	for len(b) > 0 {
+		r, size := utf8.DecodeRune(b)
+		b = b[size:]
+		total++
+		if r == utf8.RuneError && size == 1 && len(b) > 3 {
+			bad++
+		} else if isUnsafeControl(r) && r != '\r' && r != '\f' {
+			bad++
+		}
+	}
+	return bad*10 <= total
+}
+
+// isUnsafeControl reports whether r is a control character that would be
+// interpreted by the terminal, such as ESC, rather than shown.
+func isUnsafeControl(r rune) bool {
+	if r == '\n' || r == '\t' {
+		return false
+	}
+	return r < 0x20 || r == 0x7f || (r >= 0x80 && r < 0xa0)
+}
+
+// sanitizeForTerminal replaces control characters in a formatted text body
+// so a response can't move the cursor, change colors or retitle the
+// terminal. Carriage returns are dropped, which also tidies CRLF bodies.
+func sanitizeForTerminal(s string) string {
+	if !strings.ContainsFunc(s, isUnsafeControl) {
+		return s
+	}
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r == '\r':
+			return -1
+		case isUnsafeControl(r):
+			return '�'
+		}
+		return r
+	}, s)
+}
+
+// formatBinary describes a binary body instead of showing its bytes.
+func formatBinary(contentType string, size int) string {
+	if contentType == "" {
+		contentType = "none"
+	}
+	return fmt.Sprintf("Binary response, %s, Content-Type: %s (w saves it to a file)", formatBytes(int64(size)), contentType)
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+const (
+	contentTypeJSON = "application/json"
+	contentTypeForm = "application/x-www-form-urlencoded"
+)
+
+// jsonToForm converts a flat JSON object into a URL-encoded form body.
+// Nested objects and arrays can't be represented as form fields, so they
+// are rejected rather than silently flattened.
+func jsonToForm(body string) (string, error) {
+	dec := json.NewDecoder(strings.NewReader(body))
+	dec.UseNumber()
+
+	var obj map[string]interface{}
+	if err := dec.Decode(&obj); err != nil {
+		return "", fmt.Errorf("body is not a JSON object: %w", err)
+	}
+
+	values := url.Values{}
+	for k, v := range obj {
+		switch v := v.(type) {
+		case map[string]interface{}, []interface{}:
+			return "", fmt.Errorf("field %q is nested and can't be form-encoded", k)
+		case nil:
+			values.Set(k, "")
+		case string:
+			values.Set(k, v)
+		default:
+			values.Set(k, fmt.Sprint(v))
+		}
+	}
+	return values.Encode(), nil
+}
+
+// formToJSON converts a form body into a flat JSON object. Pairs may be
+// separated by & or by newlines. Values that look like JSON numbers or
+// booleans keep their type so a JSON -> form -> JSON round trip is stable.
+func formToJSON(body string) (string, error) {
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return "", errors.New("body is empty")
+	}
+
+	obj := make(map[string]interface{})
+	for _, line := range strings.Split(body, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		values, err := url.ParseQuery(line)
+		if err != nil {
+			return "", fmt.Errorf("body is not form-encoded: %w", err)
+		}
+		for k, v := range values {
+			obj[k] = formValueToJSON(v[len(v)-1])
+		}
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(obj); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
+
+func formValueToJSON(v string) interface{} {
+	switch v {
+	case "true":
+		return true
+	case "false":
+		return false
+	}
+
+	var n json.Number
+	if err := json.Unmarshal([]byte(v), &n); err == nil && n.String() == v {
+		return n
+	}
+	return v
+}
+
+// setHeaderLine replaces the value of name in a headers block, appending
+// the header when it isn't present. Header names match case-insensitively.
+func setHeaderLine(headers, name, value string) string {
+	lines := strings.Split(headers, "\n")
+	for i, line := range lines {
+		parts := strings.SplitN(line, ":", 2)
+		if len(parts) == 2 && strings.EqualFold(strings.TrimSpace(parts[0]), name) {
+			lines[i] = name + ": " + value
+			return strings.Join(lines, "\n")
+		}
+	}
+
+	if strings.TrimSpace(headers) == "" {
+		return name + ": " + value
+	}
+	return strings.TrimRight(headers, "\n") + "\n" + name + ": " + value
+}
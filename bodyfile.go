@@ -0,0 +1,50 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// bodyFilePath returns the file referenced by an @path body, with ~
+// expanded to the home directory. ok is false for ordinary bodies.
+func bodyFilePath(body string) (path string, ok bool) {
+	trimmed := strings.TrimSpace(body)
+	if !strings.HasPrefix(trimmed, "@") || len(trimmed) == 1 {
+		return "", false
+	}
+	return expandHome(trimmed[1:]), true
+}
+
+// expandHome replaces a leading ~ with the user's home directory.
+func expandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, path[1:])
+}
+
+// openBodyFile opens path for streaming as a request body and returns its
+// size for Content-Length.
+func openBodyFile(path string) (io.ReadCloser, int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, 0, fmt.Errorf("reading body file: %w", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, 0, fmt.Errorf("reading body file: %w", err)
+	}
+	if info.IsDir() {
+		f.Close()
+		return nil, 0, fmt.Errorf("reading body file: %s is a directory", path)
+	}
+	return f, info.Size(), nil
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Bookmark marks a line of the current response with an optional note.
+// Bookmarks live only as long as the response they were placed on.
+type Bookmark struct {
+	Line int
+	Note string
+}
+
+// addBookmark adds a bookmark for line, replacing the note of an existing
+// bookmark on the same line. The returned slice is sorted by line.
+func addBookmark(bookmarks []Bookmark, line int, note string) []Bookmark {
+	for i, b := range bookmarks {
+		if b.Line == line {
+			bookmarks[i].Note = note
+			return bookmarks
+		}
+	}
+
+	bookmarks = append(bookmarks, Bookmark{Line: line, Note: note})
+	sort.Slice(bookmarks, func(i, j int) bool {
+		return bookmarks[i].Line < bookmarks[j].Line
+	})
+	return bookmarks
+}
+
+// removeBookmark drops the bookmark on line, if any.
+func removeBookmark(bookmarks []Bookmark, line int) []Bookmark {
+	for i, b := range bookmarks {
+		if b.Line == line {
+			return append(bookmarks[:i], bookmarks[i+1:]...)
+		}
+	}
+	return bookmarks
+}
+
+// nextBookmark returns the first bookmark after line, wrapping around to the
+// first bookmark when line is past the last one.
+func nextBookmark(bookmarks []Bookmark, line int) (Bookmark, bool) {
+	if len(bookmarks) == 0 {
+		return Bookmark{}, false
+	}
+	for _, b := range bookmarks {
+		if b.Line > line {
+			return b, true
+		}
+	}
+	return bookmarks[0], true
+}
+
+// prevBookmark returns the last bookmark before line, wrapping around to the
+// last bookmark when line is before the first one.
+func prevBookmark(bookmarks []Bookmark, line int) (Bookmark, bool) {
+	if len(bookmarks) == 0 {
+		return Bookmark{}, false
+	}
+	for i := len(bookmarks) - 1; i >= 0; i-- {
+		if bookmarks[i].Line < line {
+			return bookmarks[i], true
+		}
+	}
+	return bookmarks[len(bookmarks)-1], true
+}
+
+// renderGutter prefixes every line of content with a gutter column that
+// shows a marker next to bookmarked lines. Content is returned unchanged
+// when there are no bookmarks.
+func renderGutter(st *styles, content string, bookmarks []Bookmark) string {
+	if len(bookmarks) == 0 {
+		return content
+	}
+
+	marked := make(map[int]bool, len(bookmarks))
+	for _, b := range bookmarks {
+		marked[b.Line] = true
+	}
+
+	lines := strings.Split(content, "\n")
+	var sb strings.Builder
+	for i, line := range lines {
+		if marked[i] {
+			sb.WriteString(st.bookmarkMarker.Render("▌ "))
+		} else {
+			sb.WriteString("  ")
+		}
+		sb.WriteString(line)
+		if i < len(lines)-1 {
+			sb.WriteString("\n")
+		}
+	}
+	return sb.String()
+}
+
+// bookmarksReport renders the bookmarks as a plain-text list, quoting the
+// bookmarked line of content so the report stands on its own.
+func bookmarksReport(bookmarks []Bookmark, content string) string {
+	if len(bookmarks) == 0 {
+		return "No bookmarks"
+	}
+
+	lines := strings.Split(content, "\n")
+	var sb strings.Builder
+	sb.WriteString("Bookmarks:\n")
+	for _, b := range bookmarks {
+		text := ""
+		if b.Line < len(lines) {
+			text = strings.TrimSpace(lines[b.Line])
+		}
+		note := b.Note
+		if note == "" {
+			note = "(no note)"
+		}
+		sb.WriteString(fmt.Sprintf("Line %d: %s\n    %s\n", b.Line+1, note, truncateLine(text, 80)))
+	}
+	return sb.String()
+}
+
+func truncateLine(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + "…"
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// bundleVersion is bumped whenever the bundle layout changes in a way older
+// releases can't read.
+const bundleVersion = 1
+
+// Bundle is a single-file snapshot of everything in the config directory,
+// used for backups and moving between machines.
+type Bundle struct {
+	Version      int                    `json:"version"`
+	ExportedAt   time.Time              `json:"exported_at"`
+	Config       Config                 `json:"config"`
+	History      []RequestItem          `json:"history"`
+	Collections  map[string]Collection  `json:"collections"`
+	Environments map[string]Environment `json:"environments"`
+}
+
+// ExportBundle writes the config, history, collections and environments to
+// a single JSON file at path.
+func (cm *ConfigManager) ExportBundle(path string) error {
+	cm.mu.RLock()
+	bundle := Bundle{
+		Version:      bundleVersion,
+		ExportedAt:   time.Now(),
+		Config:       cm.Config,
+		History:      cm.History,
+		Collections:  cm.Collections,
+		Environments: cm.Environments,
+	}
+	bytes, err := json.MarshalIndent(bundle, "", "  ")
+	cm.mu.RUnlock()
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, bytes, 0644)
+}
+
+// ImportBundle loads a bundle written by ExportBundle. With merge set,
+// collections and environments from the bundle are added to the existing
+// ones (bundle entries win on name clashes), history is appended, and the
+// local config is kept. Without merge everything is replaced.
+func (cm *ConfigManager) ImportBundle(path string, merge bool) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var bundle Bundle
+	if err := json.Unmarshal(data, &bundle); err != nil {
+		return fmt.Errorf("invalid bundle: %w", err)
+	}
+	if bundle.Version == 0 {
+		return fmt.Errorf("invalid bundle: missing version")
+	}
+	if bundle.Version > bundleVersion {
+		return fmt.Errorf("bundle version %d is newer than supported version %d", bundle.Version, bundleVersion)
+	}
+
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	if merge {
+		for name, collection := range bundle.Collections {
+			existing, ok := cm.Collections[name]
+			if !ok {
+				cm.Collections[name] = collection
+				continue
+			}
+			for _, req := range collection.Requests {
+				existing.Requests = mergeRequest(existing.Requests, req)
+			}
+			cm.Collections[name] = existing
+		}
+		for name, env := range bundle.Environments {
+			cm.Environments[name] = env
+		}
+		for _, req := range bundle.History {
+			if !containsRequest(cm.History, req) {
+				cm.History = append(cm.History, req)
+			}
+		}
+	} else {
+		cm.Config = bundle.Config
+		cm.History = bundle.History
+		cm.Collections = bundle.Collections
+		cm.Environments = bundle.Environments
+		if cm.Collections == nil {
+			cm.Collections = make(map[string]Collection)
+		}
+		if cm.Environments == nil {
+			cm.Environments = make(map[string]Environment)
+		}
+		if err := cm.saveConfigLocked(); err != nil {
+			return err
+		}
+	}
+
+	if err := cm.saveJSONLocked(historyFile, cm.History); err != nil {
+		return err
+	}
+	if err := cm.saveJSONLocked(collectionsFile, cm.Collections); err != nil {
+		return err
+	}
+	return cm.saveJSONLocked(envFile, cm.Environments)
+}
+
+// mergeRequest replaces the request with the same URL and method, or appends
+// req when there is none, matching addToCollection's dedup rule.
+func mergeRequest(requests []RequestItem, req RequestItem) []RequestItem {
+	for i, item := range requests {
+		if item.URL == req.URL && item.Method == req.Method {
+			requests[i] = req
+			return requests
+		}
+	}
+	return append(requests, req)
+}
+
+func containsRequest(requests []RequestItem, req RequestItem) bool {
+	for _, item := range requests {
+		if item.URL == req.URL && item.Method == req.Method {
+			return true
+		}
+	}
+	return false
+}
+
+// saveJSONLocked writes v to name inside the config directory. The caller
+// must hold cm.mu.
+func (cm *ConfigManager) saveJSONLocked(name string, v interface{}) error {
+	bytes, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(cm.configDir, name), bytes, 0644)
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// errRequestCancelled is the error of a request aborted with Esc.
+var errRequestCancelled = errors.New("request cancelled")
+
+// errorText is err as shown in the status line and response panel. A
+// cancelled request isn't a failure, so it's not labelled as an error.
+func errorText(err error) string {
+	if errors.Is(err, errRequestCancelled) {
+		return "Request cancelled"
+	}
+	return "Error: " + err.Error()
+}
+
+// newRequestContext returns the context for a request about to be sent and
+// keeps its cancel func so Esc can abort the request.
+func (m *Model) newRequestContext() context.Context {
+	if m.cancelRequest != nil {
+		m.cancelRequest()
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	m.cancelRequest = cancel
+	return ctx
+}
+
+// sleepContext waits for d, returning early with ctx's error if it's
+// cancelled first.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
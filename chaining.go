@@ -0,0 +1,75 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"regexp"
+	"strings"
+)
+
+// responseRefPattern matches {{response.path}} placeholders, which refer
+// to a value in the last JSON response.
+var responseRefPattern = regexp.MustCompile(`\{\{\s*response\.([^{}]+?)\s*\}\}`)
+
+// parseResponseJSON decodes body for {{response.*}} lookups. It returns
+// nil when the body isn't JSON.
+func parseResponseJSON(body string) interface{} {
+	dec := json.NewDecoder(bytes.NewReader([]byte(body)))
+	dec.UseNumber()
+	var doc interface{}
+	if err := dec.Decode(&doc); err != nil {
+		return nil
+	}
+	return doc
+}
+
+// replaceResponseRefs fills in {{response.path}} placeholders from doc.
+// Placeholders are left as they are when doc is nil or the path misses.
+func replaceResponseRefs(input string, doc interface{}) string {
+	if !strings.Contains(input, "{{") {
+		return input
+	}
+	return responseRefPattern.ReplaceAllStringFunc(input, func(ref string) string {
+		if doc == nil {
+			return ref
+		}
+		path := responseRefPattern.FindStringSubmatch(ref)[1]
+		value, err := lookupJSONPath(doc, path)
+		if err != nil {
+			return ref
+		}
+		return value
+	})
+}
+
+// unresolvedResponseRefs lists the {{response.*}} placeholders still in
+// parts, each once.
+func unresolvedResponseRefs(parts ...string) []string {
+	var refs []string
+	seen := make(map[string]bool)
+	for _, p := range parts {
+		for _, ref := range responseRefPattern.FindAllString(p, -1) {
+			if !seen[ref] {
+				seen[ref] = true
+				refs = append(refs, ref)
+			}
+		}
+	}
+	return refs
+}
+
+// resolveVars substitutes {{VARIABLE}} placeholders from the active
+// environment, then {{response.*}} ones from the last JSON response.
+func (m Model) resolveVars(s string) string {
+	if m.configManager != nil {
+		s = m.configManager.replaceEnvVars(s)
+	}
+	return replaceResponseRefs(s, m.lastJSON)
+}
+
+// resolveForSend is resolveVars followed by {{$name}} dynamic values. Those
+// are only expanded when a request is actually sent, so previews keep the
+// placeholder instead of showing a value that will change.
+func (m Model) resolveForSend(s string) string {
+	return resolveTemplates(m.resolveVars(s))
+}
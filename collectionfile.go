@@ -0,0 +1,96 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+const (
+	// collectionFileFormat identifies a file written by ExportCollection.
+	collectionFileFormat = "api-client-tui/collection"
+	// collectionFileVersion is bumped whenever the layout changes in a way
+	// older releases can't read.
+	collectionFileVersion = 1
+)
+
+// CollectionFile is a single collection exported for sharing.
+type CollectionFile struct {
+	Format     string     `json:"format"`
+	Version    int        `json:"version"`
+	ExportedAt time.Time  `json:"exported_at"`
+	Collection Collection `json:"collection"`
+}
+
+// ExportCollection writes the collection called name to a standalone file
+// at path.
+func (cm *ConfigManager) ExportCollection(name, path string) error {
+	cm.mu.RLock()
+	collection, ok := cm.Collections[name]
+	cm.mu.RUnlock()
+	if !ok {
+		return fmt.Errorf("collection %s not found", name)
+	}
+
+	bytes, err := json.MarshalIndent(CollectionFile{
+		Format:     collectionFileFormat,
+		Version:    collectionFileVersion,
+		ExportedAt: time.Now(),
+		Collection: collection,
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, bytes, 0644)
+}
+
+// ImportCollection adds a collection written by ExportCollection. When a
+// collection with the same name already exists the imported one is renamed
+// rather than merged, e.g. to "Default (2)".
+func (cm *ConfigManager) ImportCollection(path string) error {
+	_, err := cm.importCollection(path)
+	return err
+}
+
+// importCollection is ImportCollection, also returning the name the
+// collection was added under.
+func (cm *ConfigManager) importCollection(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	var file CollectionFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return "", fmt.Errorf("invalid collection file: %w", err)
+	}
+	if file.Format != collectionFileFormat {
+		return "", fmt.Errorf("invalid collection file: format is %q, expected %q", file.Format, collectionFileFormat)
+	}
+	if file.Version > collectionFileVersion {
+		return "", fmt.Errorf("collection file version %d is newer than supported version %d", file.Version, collectionFileVersion)
+	}
+
+	collection := file.Collection
+	if collection.Name == "" {
+		return "", fmt.Errorf("invalid collection file: missing collection name")
+	}
+	if collection.Requests == nil {
+		collection.Requests = []RequestItem{}
+	}
+
+	cm.mu.Lock()
+	name := collection.Name
+	for i := 2; ; i++ {
+		if _, exists := cm.Collections[name]; !exists {
+			break
+		}
+		name = fmt.Sprintf("%s (%d)", collection.Name, i)
+	}
+	collection.Name = name
+	cm.Collections[name] = collection
+	cm.mu.Unlock()
+
+	return name, cm.saveCollections()
+}
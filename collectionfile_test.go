@@ -0,0 +1,65 @@
+package main
+
+import (
+	"net/url"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCollectionFileRoundTrip(t *testing.T) {
+	created := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	req := RequestItem{
+		ID:            "1",
+		Name:          "Create user",
+		URL:           "{{BASE_URL}}/users/{id}",
+		Method:        "POST",
+		Headers:       map[string]string{"Content-Type": "application/json"},
+		Body:          `{"name": "ada"}`,
+		CreatedAt:     created,
+		LastUsed:      created.Add(time.Hour),
+		Collections:   []string{"Users"},
+		Timeout:       15,
+		TrailingSlash: trailingSlashAdd,
+		Summary:       "{{.id}}",
+		AllowGetBody:  true,
+		Tags:          []string{"staging"},
+		PathParams:    map[string]string{"id": "42"},
+		Auth:          &AuthConfig{Type: "basic", Username: "ada", Password: "{{PASSWORD}}"},
+		Query:         url.Values{"verbose": {"true"}, "tag": {"a", "b"}},
+		GraphQL:       true,
+		Variables:     `{"first": 10}`,
+		SchemaPath:    "~/schemas/user.json",
+	}
+
+	cm := newTestConfigManager(t)
+	cm.Collections["Users"] = Collection{Name: "Users", Requests: []RequestItem{req}, Tags: []string{"api"}}
+	path := filepath.Join(t.TempDir(), "users.json")
+	if err := cm.ExportCollection("Users", path); err != nil {
+		t.Fatalf("ExportCollection: %v", err)
+	}
+
+	// Importing next to the original renames the copy
+	name, err := cm.importCollection(path)
+	if err != nil {
+		t.Fatalf("importCollection: %v", err)
+	}
+	if name != "Users (2)" {
+		t.Errorf("imported as %q, want %q", name, "Users (2)")
+	}
+	imported := cm.Collections[name]
+	if len(imported.Requests) != 1 {
+		t.Fatalf("imported %d requests, want 1", len(imported.Requests))
+	}
+	if got := imported.Requests[0]; !reflect.DeepEqual(got, req) {
+		t.Errorf("request changed in the round trip:\n got %+v\nwant %+v", got, req)
+	}
+	if !reflect.DeepEqual(imported.Tags, []string{"api"}) {
+		t.Errorf("tags = %v, want [api]", imported.Tags)
+	}
+
+	if name, err := cm.importCollection(path); err != nil || name != "Users (3)" {
+		t.Errorf("second import = %q, %v, want %q", name, err, "Users (3)")
+	}
+}
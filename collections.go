@@ -0,0 +1,208 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// collectionItem is a collection as listed in the collections panel.
+type collectionItem struct {
+	Name     string
+	Requests int
+	Tags     []string
+}
+
+func (c collectionItem) Title() string { return c.Name }
+
+func (c collectionItem) Description() string {
+	desc := fmt.Sprintf("%d request(s)", c.Requests)
+	if len(c.Tags) > 0 {
+		desc += " [" + strings.Join(c.Tags, ", ") + "]"
+	}
+	return desc
+}
+
+func (c collectionItem) FilterValue() string { return c.Name + " " + strings.Join(c.Tags, " ") }
+
+// collectionSummaries lists every collection, sorted by name.
+func (cm *ConfigManager) collectionSummaries() []collectionItem {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+
+	names := cm.collectionNamesLocked()
+	items := make([]collectionItem, len(names))
+	for i, name := range names {
+		c := cm.Collections[name]
+		items[i] = collectionItem{Name: name, Requests: len(c.Requests), Tags: c.Tags}
+	}
+	return items
+}
+
+// collectionRequests lists the requests in the named collection, in the
+// order they were saved.
+func (cm *ConfigManager) collectionRequests(name string) []savedRequest {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+
+	c := cm.Collections[name]
+	result := make([]savedRequest, len(c.Requests))
+	for i, req := range c.Requests {
+		tags := append(append([]string{}, c.Tags...), req.Tags...)
+		result[i] = savedRequest{Collection: name, Request: req, Tags: tags}
+	}
+	return result
+}
+
+// createCollection adds an empty collection.
+func (cm *ConfigManager) createCollection(name string) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return fmt.Errorf("collection name can't be empty")
+	}
+
+	cm.mu.Lock()
+	if _, exists := cm.Collections[name]; exists {
+		cm.mu.Unlock()
+		return fmt.Errorf("collection %q already exists", name)
+	}
+	cm.Collections[name] = Collection{Name: name, Requests: []RequestItem{}}
+	cm.mu.Unlock()
+
+	return cm.saveCollections()
+}
+
+// renameCollection gives a collection a new name, keeping its requests.
+func (cm *ConfigManager) renameCollection(oldName, newName string) error {
+	newName = strings.TrimSpace(newName)
+	if newName == "" {
+		return fmt.Errorf("collection name can't be empty")
+	}
+
+	cm.mu.Lock()
+	collection, exists := cm.Collections[oldName]
+	if !exists {
+		cm.mu.Unlock()
+		return fmt.Errorf("collection %q not found", oldName)
+	}
+	if newName == oldName {
+		cm.mu.Unlock()
+		return nil
+	}
+	if _, taken := cm.Collections[newName]; taken {
+		cm.mu.Unlock()
+		return fmt.Errorf("collection %q already exists", newName)
+	}
+	delete(cm.Collections, oldName)
+	collection.Name = newName
+	cm.Collections[newName] = collection
+	cm.mu.Unlock()
+
+	return cm.saveCollections()
+}
+
+// deleteCollection removes a collection and the requests saved in it.
+func (cm *ConfigManager) deleteCollection(name string) error {
+	cm.mu.Lock()
+	if _, exists := cm.Collections[name]; !exists {
+		cm.mu.Unlock()
+		return fmt.Errorf("collection %q not found", name)
+	}
+	delete(cm.Collections, name)
+	cm.mu.Unlock()
+
+	return cm.saveCollections()
+}
+
+// refreshCollections reloads the collections panel: the collections
+// themselves, or the requests in openCollection once one is opened.
+func (m *Model) refreshCollections() tea.Cmd {
+	m.collectionList.ResetFilter()
+	if m.openCollection == "" {
+		summaries := m.configManager.collectionSummaries()
+		items := make([]list.Item, len(summaries))
+		for i, c := range summaries {
+			items[i] = c
+		}
+		m.collectionList.Title = fmt.Sprintf("Collections (%d) • enter: open • n: new • r: rename • d: delete • esc: close", len(summaries))
+		return m.collectionList.SetItems(items)
+	}
+
+	requests := m.configManager.collectionRequests(m.openCollection)
+	items := make([]list.Item, len(requests))
+	for i, r := range requests {
+		items[i] = r
+	}
+	m.collectionList.Title = fmt.Sprintf("%s (%d) • enter: load • esc: back", m.openCollection, len(requests))
+	return m.collectionList.SetItems(items)
+}
+
+// updateCollections handles keys while the collections panel is open.
+func (m Model) updateCollections(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.collectionList.FilterState() == list.Filtering {
+		var cmd tea.Cmd
+		m.collectionList, cmd = m.collectionList.Update(msg)
+		return m, cmd
+	}
+
+	selected, _ := m.collectionList.SelectedItem().(collectionItem)
+	switch {
+	case key.Matches(msg, keys.Cancel) && m.collectionList.FilterState() == list.Unfiltered:
+		if m.openCollection != "" {
+			back := m.openCollection
+			m.openCollection = ""
+			cmd := m.refreshCollections()
+			for i, item := range m.collectionList.Items() {
+				if c, ok := item.(collectionItem); ok && c.Name == back {
+					m.collectionList.Select(i)
+				}
+			}
+			return m, cmd
+		}
+		m.showCollections = false
+		return m, nil
+
+	case key.Matches(msg, keys.Enter):
+		if m.openCollection == "" {
+			if selected.Name == "" {
+				return m, nil
+			}
+			m.openCollection = selected.Name
+			cmd := m.refreshCollections()
+			m.collectionList.Select(0)
+			return m, cmd
+		}
+		if s, ok := m.collectionList.SelectedItem().(savedRequest); ok {
+			m.showCollections = false
+			m.loadRequest(s.Request)
+			m.statusMessage = "Loaded " + s.Title() + " from " + s.Collection
+		}
+		return m, nil
+
+	case m.openCollection == "" && key.Matches(msg, keys.NewCollection):
+		return m.openPrompt(promptNewCollection, "New collection: ", "")
+
+	case m.openCollection == "" && key.Matches(msg, keys.RenameItem):
+		if selected.Name == "" {
+			return m, nil
+		}
+		m.collectionEdit = selected.Name
+		return m.openPrompt(promptRenameCollection, "Rename "+selected.Name+" to: ", selected.Name)
+
+	case m.openCollection == "" && key.Matches(msg, keys.DeleteItem):
+		if selected.Name == "" {
+			return m, nil
+		}
+		m.collectionEdit = selected.Name
+		m.confirmKind = confirmDeleteCollection
+		m.confirmText = fmt.Sprintf("Delete collection %q and its %d request(s)?", selected.Name, selected.Requests)
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.collectionList, cmd = m.collectionList.Update(msg)
+	return m, cmd
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+)
+
+// acceptEncodingValue is advertised when Config.AdvertiseCompression is set
+// and the user hasn't supplied their own Accept-Encoding header.
+const acceptEncodingValue = "gzip, deflate, br"
+
+// decompressBody decodes body according to the Content-Encoding header.
+// Unknown or empty encodings return the body unchanged. The decoded size is
+// capped at limit bytes so a small compressed payload can't expand past the
+// response size limit.
+func decompressBody(contentEncoding string, body []byte, limit int64) ([]byte, error) {
+	encoding := strings.ToLower(strings.TrimSpace(contentEncoding))
+
+	var reader io.Reader
+	switch encoding {
+	case "", "identity":
+		return body, nil
+	case "gzip", "x-gzip":
+		gz, err := gzip.NewReader(bytes.NewReader(body))
+		if err != nil {
+			return nil, fmt.Errorf("invalid gzip body: %w", err)
+		}
+		defer gz.Close()
+		reader = gz
+	case "deflate":
+		// Servers disagree on whether deflate means zlib-wrapped or raw
+		// DEFLATE data, so try zlib first.
+		if zr, err := zlib.NewReader(bytes.NewReader(body)); err == nil {
+			defer zr.Close()
+			reader = zr
+		} else {
+			fr := flate.NewReader(bytes.NewReader(body))
+			defer fr.Close()
+			reader = fr
+		}
+	case "br":
+		reader = brotli.NewReader(bytes.NewReader(body))
+	default:
+		return body, nil
+	}
+
+	var out bytes.Buffer
+	n, err := io.Copy(&out, io.LimitReader(reader, limit+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress %s body: %w", encoding, err)
+	}
+	if n > limit {
+		return nil, fmt.Errorf("decompressed response exceeds %.1f MB limit", float64(limit)/(1024*1024))
+	}
+	return out.Bytes(), nil
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// sessionJar is the cookie jar shared by every request in a session. It
+// wraps cookiejar.Jar, which can't list its contents, and remembers the
+// URLs cookies were set for so the jar can be shown. The mutex guards
+// replacing the jar on clear; cookiejar.Jar is itself safe for concurrent
+// use.
+type sessionJar struct {
+	mu   sync.Mutex
+	jar  *cookiejar.Jar
+	seen map[string]*url.URL
+}
+
+func newSessionJar() *sessionJar {
+	jar, _ := cookiejar.New(nil) // Only fails for a bad PublicSuffixList
+	return &sessionJar{jar: jar, seen: make(map[string]*url.URL)}
+}
+
+func (s *sessionJar) SetCookies(u *url.URL, cookies []*http.Cookie) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.jar.SetCookies(u, cookies)
+	origin := &url.URL{Scheme: u.Scheme, Host: u.Host, Path: u.Path}
+	s.seen[origin.String()] = origin
+}
+
+func (s *sessionJar) Cookies(u *url.URL) []*http.Cookie {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.jar.Cookies(u)
+}
+
+// clear drops every cookie.
+func (s *sessionJar) clear() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.jar, _ = cookiejar.New(nil)
+	s.seen = make(map[string]*url.URL)
+}
+
+// hostCookies returns the cookies still in the jar, as name=value pairs
+// per host. Expired and deleted cookies are left out by the jar itself.
+func (s *sessionJar) hostCookies() map[string][]string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	byHost := make(map[string][]string)
+	seen := make(map[string]bool)
+	for _, u := range s.seen {
+		for _, c := range s.jar.Cookies(u) {
+			pair := c.Name + "=" + c.Value
+			if seen[u.Host+"\x00"+pair] {
+				continue
+			}
+			seen[u.Host+"\x00"+pair] = true
+			byHost[u.Host] = append(byHost[u.Host], pair)
+		}
+	}
+	return byHost
+}
+
+// cookieJar returns the session jar for http.Client, or nil when there is
+// none so the client doesn't get a typed nil.
+func (m Model) cookieJar() http.CookieJar {
+	if m.cookies == nil {
+		return nil
+	}
+	return m.cookies
+}
+
+func (m Model) updateCookies(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, keys.Quit):
+		return m.quit()
+
+	case key.Matches(msg, keys.Cancel), key.Matches(msg, keys.Cookies):
+		m.showCookies = false
+
+	case key.Matches(msg, keys.ClearCookies):
+		if m.cookies != nil {
+			m.cookies.clear()
+		}
+		m.statusMessage = "Cleared cookies"
+	}
+	return m, nil
+}
+
+// renderCookies lists the session's cookies grouped by host.
+func (m Model) renderCookies() string {
+	var sb strings.Builder
+	sb.WriteString("Cookies (x: clear all • esc: close)\n\n")
+
+	var byHost map[string][]string
+	if m.cookies != nil {
+		byHost = m.cookies.hostCookies()
+	}
+	if len(byHost) == 0 {
+		sb.WriteString("No cookies yet")
+		return sb.String()
+	}
+
+	hosts := make([]string, 0, len(byHost))
+	for h := range byHost {
+		hosts = append(hosts, h)
+	}
+	sort.Strings(hosts)
+	for _, h := range hosts {
+		sb.WriteString(h + "\n")
+		pairs := byHost[h]
+		sort.Strings(pairs)
+		for _, p := range pairs {
+			sb.WriteString(fmt.Sprintf("  %s\n", p))
+		}
+	}
+	return strings.TrimSuffix(sb.String(), "\n")
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// corsHeaders are the preflight response headers the CORS summary lists.
+var corsHeaders = []string{
+	"Access-Control-Allow-Origin",
+	"Access-Control-Allow-Methods",
+	"Access-Control-Allow-Headers",
+	"Access-Control-Allow-Credentials",
+	"Access-Control-Max-Age",
+}
+
+// formatCORS summarises the CORS headers of a preflight (OPTIONS)
+// response, one per line, or says there are none.
+func formatCORS(st *styles, h http.Header) string {
+	var sb strings.Builder
+	sb.WriteString(st.summary.Render("CORS:") + "\n")
+	found := false
+	for _, name := range corsHeaders {
+		values := h.Values(name)
+		if len(values) == 0 {
+			continue
+		}
+		found = true
+		label := strings.TrimPrefix(name, "Access-Control-")
+		sb.WriteString(fmt.Sprintf("  %-18s%s\n", label+":", strings.Join(values, ", ")))
+	}
+	if !found {
+		sb.WriteString(st.error.Render("  No CORS headers present") + "\n")
+	}
+	return sb.String()
+}
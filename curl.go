@@ -0,0 +1,261 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// SentRequest is what actually went over the wire for a response: the URL
+// after substitution and every header, including ones added automatically.
+type SentRequest struct {
+	Method  string
+	URL     string
+	Headers http.Header
+	Body    string
+	HasBody bool
+	// BodyFile is set when the body was streamed from an @path file.
+	BodyFile string
+	// UnresolvedRefs lists {{response.*}} placeholders that were sent as
+	// is because there was no JSON response or the path missed.
+	UnresolvedRefs []string
+	// OSEnvRefs lists the placeholders filled from OS environment
+	// variables, see osEnvRefs.
+	OSEnvRefs []string
+}
+
+// buildCurl renders sent as a curl command that reproduces it, one option
+// per line. Headers are sorted so the output is stable.
+func buildCurl(sent SentRequest) string {
+	first := "curl"
+	switch {
+	case sent.Method == "HEAD":
+		first += " --head"
+	case sent.Method != "GET" || sent.HasBody:
+		first += " -X " + sent.Method
+	}
+	lines := []string{first + " " + shellQuote(sent.URL)}
+
+	keys := make([]string, 0, len(sent.Headers))
+	for k := range sent.Headers {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		for _, v := range sent.Headers[k] {
+			lines = append(lines, "-H "+shellQuote(k+": "+v))
+		}
+	}
+
+	switch {
+	case sent.BodyFile != "":
+		lines = append(lines, "--data-binary "+shellQuote("@"+sent.BodyFile))
+	case sent.HasBody:
+		lines = append(lines, "--data-raw "+shellQuote(sent.Body))
+	}
+	return strings.Join(lines, " \\\n  ")
+}
+
+// shellQuote wraps s in single quotes for POSIX shells.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// curlValueFlags are curl options that take an argument but have no
+// equivalent in the editor. They're skipped along with their argument so
+// the argument isn't mistaken for the URL.
+var curlValueFlags = map[string]bool{
+	"-o": true, "--output": true, "-b": true, "--cookie": true, "-c": true, "--cookie-jar": true,
+	"--connect-timeout": true, "-m": true, "--max-time": true, "--retry": true, "-x": true,
+	"--proxy": true, "-w": true, "--write-out": true, "-F": true, "--form": true, "--cacert": true,
+	"-E": true, "--cert": true, "--key": true, "-T": true, "--upload-file": true, "--resolve": true,
+	"--limit-rate": true, "-r": true, "--range": true, "--max-redirs": true, "--json": true,
+}
+
+// looksLikeCurl reports whether text is a curl command rather than a body.
+func looksLikeCurl(text string) bool {
+	trimmed := strings.TrimSpace(text)
+	return trimmed == "curl" || strings.HasPrefix(trimmed, "curl ") || strings.HasPrefix(trimmed, "curl\t")
+}
+
+// parseCurl turns a curl command line into a request. It understands -X,
+// -H, -d/--data/--data-raw/--data-binary, -u, -A, -e and --head; other
+// options are ignored. Like curl, data without -X makes the request a POST
+// and repeated -d values are joined with &.
+func parseCurl(cmd string) (RequestItem, error) {
+	args, err := splitShellWords(cmd)
+	if err != nil {
+		return RequestItem{}, err
+	}
+	if len(args) == 0 || args[0] != "curl" {
+		return RequestItem{}, errors.New("not a curl command")
+	}
+
+	req := RequestItem{Headers: make(map[string]string)}
+	var data []string
+	head := false
+	for i := 1; i < len(args); i++ {
+		arg, attached := args[i], ""
+		// Short options may have their value attached, as in -XPOST.
+		if len(arg) > 2 && arg[0] == '-' && arg[1] != '-' && strings.ContainsRune("XHduAe", rune(arg[1])) {
+			arg, attached = arg[:2], arg[2:]
+		}
+		value := func() (string, error) {
+			if attached != "" {
+				return attached, nil
+			}
+			if i+1 >= len(args) {
+				return "", fmt.Errorf("curl option %s needs a value", arg)
+			}
+			i++
+			return args[i], nil
+		}
+
+		switch {
+		case arg == "-X" || arg == "--request":
+			v, err := value()
+			if err != nil {
+				return RequestItem{}, err
+			}
+			req.Method = strings.ToUpper(v)
+
+		case arg == "-H" || arg == "--header":
+			v, err := value()
+			if err != nil {
+				return RequestItem{}, err
+			}
+			if name, val, ok := strings.Cut(v, ":"); ok {
+				req.Headers[strings.TrimSpace(name)] = strings.TrimSpace(val)
+			}
+
+		case arg == "-d" || arg == "--data" || arg == "--data-raw" || arg == "--data-binary" || arg == "--data-ascii":
+			v, err := value()
+			if err != nil {
+				return RequestItem{}, err
+			}
+			data = append(data, v)
+
+		case arg == "-u" || arg == "--user":
+			v, err := value()
+			if err != nil {
+				return RequestItem{}, err
+			}
+			username, password, _ := strings.Cut(v, ":")
+			req.Auth = &AuthConfig{Type: authBasic, Username: username, Password: password}
+
+		case arg == "-A" || arg == "--user-agent":
+			v, err := value()
+			if err != nil {
+				return RequestItem{}, err
+			}
+			req.Headers["User-Agent"] = v
+
+		case arg == "-e" || arg == "--referer":
+			v, err := value()
+			if err != nil {
+				return RequestItem{}, err
+			}
+			req.Headers["Referer"] = v
+
+		case arg == "-I" || arg == "--head":
+			head = true
+
+		case arg == "--url":
+			v, err := value()
+			if err != nil {
+				return RequestItem{}, err
+			}
+			req.URL = v
+
+		case curlValueFlags[arg]:
+			i++
+
+		case strings.HasPrefix(arg, "-"):
+			// Unknown switch, ignored
+
+		default:
+			if req.URL == "" {
+				req.URL = arg
+			}
+		}
+	}
+
+	if req.URL == "" {
+		return RequestItem{}, errors.New("no URL in curl command")
+	}
+	req.Body = strings.Join(data, "&")
+	switch {
+	case req.Method != "":
+	case head:
+		req.Method = "HEAD"
+	case len(data) > 0:
+		req.Method = "POST"
+	default:
+		req.Method = "GET"
+	}
+	req.Name = req.Method + " " + req.URL
+	return req, nil
+}
+
+// splitShellWords splits a command line the way a POSIX shell would for
+// the quoting curl commands use: single and double quotes, backslash
+// escapes and backslash-newline continuations.
+func splitShellWords(s string) ([]string, error) {
+	var words []string
+	var cur strings.Builder
+	inWord := false
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '\\' && i+1 < len(s) && (s[i+1] == '\n' || s[i+1] == '\r'):
+			i++
+			if s[i] == '\r' && i+1 < len(s) && s[i+1] == '\n' {
+				i++
+			}
+
+		case c == '\\' && i+1 < len(s):
+			i++
+			cur.WriteByte(s[i])
+			inWord = true
+
+		case c == '\'':
+			end := strings.IndexByte(s[i+1:], '\'')
+			if end < 0 {
+				return nil, errors.New("unterminated ' in curl command")
+			}
+			cur.WriteString(s[i+1 : i+1+end])
+			i += end + 1
+			inWord = true
+
+		case c == '"':
+			i++
+			for ; i < len(s) && s[i] != '"'; i++ {
+				if s[i] == '\\' && i+1 < len(s) && strings.IndexByte("\"\\$`\n", s[i+1]) >= 0 {
+					i++
+				}
+				cur.WriteByte(s[i])
+			}
+			if i >= len(s) {
+				return nil, errors.New("unterminated \" in curl command")
+			}
+			inWord = true
+
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			if inWord {
+				words = append(words, cur.String())
+				cur.Reset()
+				inWord = false
+			}
+
+		default:
+			cur.WriteByte(c)
+			inWord = true
+		}
+	}
+	if inWord {
+		words = append(words, cur.String())
+	}
+	return words, nil
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// defaultDashboardInterval is used when Config.DashboardInterval is unset.
+const defaultDashboardInterval = 30
+
+// dashboardEntry is one monitored request and the outcome of its latest
+// check.
+type dashboardEntry struct {
+	Request RequestItem
+	Last    Response
+	Checked time.Time
+}
+
+// up reports whether the last check succeeded. Entries that haven't been
+// checked yet count as down.
+func (e dashboardEntry) up() bool {
+	return !e.Checked.IsZero() && e.Last.Error == nil && e.Last.StatusCode > 0 && e.Last.StatusCode < 400
+}
+
+// dashboardState holds the health-check dashboard for one collection.
+// generation is bumped whenever the dashboard is (re)opened or closed so
+// ticks and results from a previous run are ignored.
+type dashboardState struct {
+	collection string
+	entries    []dashboardEntry
+	cursor     int
+	paused     bool
+	running    bool
+	generation int
+	interval   time.Duration
+}
+
+type dashboardTickMsg struct {
+	generation int
+}
+
+type dashboardResultMsg struct {
+	generation int
+	results    []Response
+}
+
+func dashboardTick(generation int, interval time.Duration) tea.Cmd {
+	return tea.Tick(interval, func(time.Time) tea.Msg {
+		return dashboardTickMsg{generation: generation}
+	})
+}
+
+// checkDashboard sends every monitored request through the shared batch
+// runner and reports the results in entry order.
+func (m Model) checkDashboard() tea.Cmd {
+	generation := m.dashboard.generation
+	requests := make([]RequestItem, len(m.dashboard.entries))
+	for i, e := range m.dashboard.entries {
+		requests[i] = e.Request
+	}
+
+	return func() tea.Msg {
+		results := runBatch(len(requests), m.configManager.maxConcurrency(), func(i int) Response {
+			return m.executeRequest(context.Background(), requests[i], false)
+		})
+		return dashboardResultMsg{generation: generation, results: results}
+	}
+}
+
+// renderDashboard draws the dashboard grid: status indicator, name, last
+// status code and latency for every request.
+func renderDashboard(st *styles, d dashboardState, width int) string {
+	var sb strings.Builder
+
+	state := fmt.Sprintf("every %v", d.interval)
+	if d.paused {
+		state = "paused"
+	} else if d.running {
+		state = "checking..."
+	}
+	sb.WriteString(fmt.Sprintf("Health: %s (%s)\n", d.collection, state))
+	sb.WriteString(st.help.Render("↑/↓: select • enter: view response • p: pause/resume • r: check now • esc: close") + "\n\n")
+
+	if len(d.entries) == 0 {
+		sb.WriteString("No requests in this collection")
+		return sb.String()
+	}
+
+	nameWidth := max(width-40, 20)
+	for i, e := range d.entries {
+		cursor := "  "
+		if i == d.cursor {
+			cursor = "▸ "
+		}
+
+		indicator := st.statusError.Render("●")
+		if e.up() {
+			indicator = st.statusSuccess.Render("●")
+		}
+
+		status, latency, checked := "-", "-", "never"
+		statusStyle := st.help
+		if !e.Checked.IsZero() {
+			checked = e.Checked.Format("15:04:05")
+			latency = e.Last.ResponseTime.Round(time.Millisecond).String()
+			status = "ERR"
+			if e.Last.Error == nil {
+				status = fmt.Sprintf("%d", e.Last.StatusCode)
+			}
+			statusStyle = st.statusError
+			if e.up() {
+				statusStyle = st.statusSuccess
+			}
+		}
+		status = statusStyle.Render(fmt.Sprintf("%5s", status))
+
+		name := e.Request.Name
+		if name == "" {
+			name = e.Request.Method + " " + e.Request.URL
+		}
+		sb.WriteString(fmt.Sprintf("%s%s %-*s %s %10s  %s\n",
+			cursor, indicator, nameWidth, truncateLine(name, nameWidth), status, latency, checked))
+	}
+	return sb.String()
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// maxDiffCells caps the size of the table diffLines builds, the product of
+// the line counts left once the common start and end are trimmed.
+const maxDiffCells = 4_000_000
+
+// diffContext is the number of unchanged lines shown around each change.
+const diffContext = 3
+
+// diffLine is a line of a diff: ' ' unchanged, '+' added or '-' removed.
+type diffLine struct {
+	Op   byte
+	Text string
+}
+
+// diffLines compares a and b line by line using the longest common
+// subsequence. It returns false when the changed region is too large to
+// compare.
+func diffLines(a, b []string) ([]diffLine, bool) {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+	midA, midB := a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+	if len(midA)*len(midB) > maxDiffCells {
+		return nil, false
+	}
+
+	result := make([]diffLine, 0, len(a)+len(b)-prefix-suffix)
+	for _, line := range a[:prefix] {
+		result = append(result, diffLine{' ', line})
+	}
+
+	// lcs[i][j] is the length of the longest common subsequence of
+	// midA[i:] and midB[j:]
+	n, m := len(midA), len(midB)
+	lcs := make([][]int32, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int32, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if midA[i] == midB[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] > lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+	i, j := 0, 0
+	for i < n || j < m {
+		switch {
+		case i < n && j < m && midA[i] == midB[j]:
+			result = append(result, diffLine{' ', midA[i]})
+			i++
+			j++
+		case i < n && (j == m || lcs[i+1][j] >= lcs[i][j+1]):
+			result = append(result, diffLine{'-', midA[i]})
+			i++
+		default:
+			result = append(result, diffLine{'+', midB[j]})
+			j++
+		}
+	}
+
+	for _, line := range a[len(a)-suffix:] {
+		result = append(result, diffLine{' ', line})
+	}
+	return result, true
+}
+
+// diffable reports why prev and cur can't be compared, or "" when they
+// can: both must be responses with the same content type.
+func diffable(prev, cur Response) string {
+	if prev.StatusCode == 0 {
+		return "No previous response to compare with"
+	}
+	if cur.StatusCode == 0 || cur.Error != nil {
+		return "No response to compare"
+	}
+	prevType := strings.ToLower(strings.TrimSpace(strings.Split(prev.Headers.Get("Content-Type"), ";")[0]))
+	curType := strings.ToLower(strings.TrimSpace(strings.Split(cur.Headers.Get("Content-Type"), ";")[0]))
+	if prevType != curType {
+		return fmt.Sprintf("Can't diff %s against the previous %s response", orNone(curType), orNone(prevType))
+	}
+	return ""
+}
+
+func orNone(contentType string) string {
+	if contentType == "" {
+		return "untyped"
+	}
+	return contentType
+}
+
+// formatDiff shows how cur's formatted body differs from prev's: added
+// lines in green, removed lines in red, with a few unchanged lines around
+// each change.
+func formatDiff(st *styles, prev, cur Response) string {
+	lines, ok := diffLines(strings.Split(prev.FormattedBody, "\n"), strings.Split(cur.FormattedBody, "\n"))
+	if !ok {
+		return st.help.Render("The bodies differ too much to compare")
+	}
+
+	added, removed := 0, 0
+	for _, l := range lines {
+		switch l.Op {
+		case '+':
+			added++
+		case '-':
+			removed++
+		}
+	}
+	if added == 0 && removed == 0 {
+		return st.help.Render("Body unchanged since the previous response (D to hide)")
+	}
+
+	// Keep the lines within diffContext of a change
+	keep := make([]bool, len(lines))
+	for i, l := range lines {
+		if l.Op == ' ' {
+			continue
+		}
+		for k := max(i-diffContext, 0); k <= min(i+diffContext, len(lines)-1); k++ {
+			keep[k] = true
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Diff against the previous response (%s, %s; D to hide):\n",
+		st.diffAdd.Render(fmt.Sprintf("+%d", added)), st.diffRemove.Render(fmt.Sprintf("-%d", removed))))
+	skipped := false
+	for i, l := range lines {
+		if !keep[i] {
+			skipped = true
+			continue
+		}
+		if skipped {
+			sb.WriteString(st.help.Render("  ⋯") + "\n")
+			skipped = false
+		}
+		switch l.Op {
+		case '+':
+			sb.WriteString(st.diffAdd.Render("+ "+l.Text) + "\n")
+		case '-':
+			sb.WriteString(st.diffRemove.Render("- "+l.Text) + "\n")
+		default:
+			sb.WriteString("  " + l.Text + "\n")
+		}
+	}
+	if skipped {
+		sb.WriteString(st.help.Render("  ⋯") + "\n")
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// responseExtensions maps common media types to the extension used when
+// saving a response body. Types not listed fall back to the mime package.
+var responseExtensions = map[string]string{
+	"application/json":         ".json",
+	"application/xml":          ".xml",
+	"text/xml":                 ".xml",
+	"application/yaml":         ".yaml",
+	"application/x-yaml":       ".yaml",
+	"text/yaml":                ".yaml",
+	"text/html":                ".html",
+	"text/plain":               ".txt",
+	"text/csv":                 ".csv",
+	"application/pdf":          ".pdf",
+	"image/png":                ".png",
+	"image/jpeg":               ".jpg",
+	"image/gif":                ".gif",
+	"image/svg+xml":            ".svg",
+	"application/zip":          ".zip",
+	"application/octet-stream": ".bin",
+}
+
+// responseExtension picks a file extension for contentType.
+func responseExtension(contentType string) string {
+	mediaType := strings.ToLower(strings.TrimSpace(strings.Split(contentType, ";")[0]))
+	if ext, ok := responseExtensions[mediaType]; ok {
+		return ext
+	}
+	switch {
+	case isJSONContentType(mediaType):
+		return ".json"
+	case isXMLContentType(mediaType):
+		return ".xml"
+	case isYAMLContentType(mediaType):
+		return ".yaml"
+	}
+	if exts, err := mime.ExtensionsByType(mediaType); err == nil && len(exts) > 0 {
+		return exts[0]
+	}
+	if strings.HasPrefix(mediaType, "text/") {
+		return ".txt"
+	}
+	return ".bin"
+}
+
+// downloadDir is where saved responses go: Config.DownloadDir, or the
+// current directory when unset.
+func (cm *ConfigManager) downloadDir() string {
+	if cm == nil {
+		return "."
+	}
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+	if cm.Config.DownloadDir == "" {
+		return "."
+	}
+	return expandHome(cm.Config.DownloadDir)
+}
+
+// saveResponseBody writes body to a new response-<timestamp> file in dir
+// and returns its path. A numeric suffix is added rather than overwrite a
+// file saved in the same second.
+func saveResponseBody(dir, contentType, body string, now time.Time) (string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+
+	base := "response-" + now.Format("20060102-150405")
+	ext := responseExtension(contentType)
+	for i := 0; i < 100; i++ {
+		name := base + ext
+		if i > 0 {
+			name = fmt.Sprintf("%s-%d%s", base, i, ext)
+		}
+		path := filepath.Join(dir, name)
+
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if errors.Is(err, os.ErrExist) {
+			continue
+		} else if err != nil {
+			return "", err
+		}
+		if _, err := f.WriteString(body); err != nil {
+			f.Close()
+			return "", err
+		}
+		return path, f.Close()
+	}
+	return "", fmt.Errorf("too many responses saved as %s in %s", base, dir)
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"strings"
+)
+
+// dryRun renders the editor request exactly as a send would resolve it,
+// without sending it: environment, OS and {{response.*}} placeholders and
+// dynamic values substituted, and the headers the client adds included.
+func (m Model) dryRun() (string, error) {
+	reqItem := m.currentRequest()
+	reqItem.Body = m.lastBody
+	spec, err := m.buildRequestSpec(reqItem)
+	if err != nil {
+		return "", err
+	}
+	sent := SentRequest{
+		Method:  spec.Method,
+		URL:     spec.URL,
+		Headers: spec.Headers,
+		Body:    spec.Body,
+		HasBody: spec.HasBody,
+	}
+	if path, ok := bodyFilePath(spec.Body); ok && spec.HasBody {
+		sent.BodyFile = path
+	}
+
+	var sb strings.Builder
+	sb.WriteString(m.styles.insecureBanner.Render("DRY RUN: nothing was sent") + " " + m.styles.help.Render("esc to return") + "\n")
+	if m.configManager != nil {
+		if env := m.configManager.currentEnvName(); env != "" {
+			line := "Environment: " + env
+			if m.configManager.isProtectedEnv(env) {
+				line += " " + m.styles.confirm.Render("(protected)")
+			}
+			sb.WriteString(line + "\n")
+		}
+	}
+	sb.WriteString("\n" + formatSentRequest(&m.styles, sent) + "\n")
+
+	var notes []string
+	parts := []string{spec.URL, spec.Body}
+	for _, values := range spec.Headers {
+		parts = append(parts, values...)
+	}
+	if refs := unresolvedResponseRefs(parts...); len(refs) > 0 {
+		notes = append(notes, "Unresolved: "+strings.Join(refs, ", "))
+	}
+	if refs := m.configManager.osEnvRefs(requestTemplateParts(reqItem)...); len(refs) > 0 {
+		notes = append(notes, "From OS environment: "+strings.Join(refs, ", "))
+	}
+	for _, part := range requestTemplateParts(reqItem) {
+		if strings.Contains(part, "{{$") {
+			notes = append(notes, "Dynamic values such as {{$uuid}} get new values when the request is sent")
+			break
+		}
+	}
+	notes = append(notes, "Host, Content-Length and cookies from the jar are added when the request is sent")
+	sb.WriteString("\n")
+	for _, note := range notes {
+		sb.WriteString(m.styles.help.Render(note) + "\n")
+	}
+	return strings.TrimRight(sb.String(), "\n"), nil
+}
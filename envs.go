@@ -0,0 +1,366 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// secretSuffixes mark a variable as secret when its name ends with one of
+// them, ignoring case.
+var secretSuffixes = []string{"_KEY", "_SECRET", "_TOKEN", "PASSWORD"}
+
+const secretMask = "••••••••"
+
+// isSecretName reports whether a variable's value should be masked.
+func isSecretName(name string) bool {
+	upper := strings.ToUpper(name)
+	for _, suffix := range secretSuffixes {
+		if strings.HasSuffix(upper, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// displayValue is how the environments panel shows a variable. Masking
+// only affects display; replaceEnvVars always uses the real value.
+func displayValue(name, value string, mask bool) string {
+	if mask && isSecretName(name) {
+		return secretMask
+	}
+	return value
+}
+
+// openEnvs shows the environment switcher with the cursor on the active
+// environment.
+func (m Model) openEnvs() Model {
+	m.showEnvs = true
+	m.showHistory = false // Close other panels
+	m.envNames = nil
+	m.envCursor = 0
+	m.envVarFocus = false
+	m.envVarCursor = 0
+	m.revealSecrets = false
+	if m.configManager == nil {
+		return m
+	}
+
+	m.envNames = m.configManager.GetAvailableEnvironments()
+	current := m.configManager.currentEnvName()
+	for i, name := range m.envNames {
+		if name == current {
+			m.envCursor = i
+		}
+	}
+	return m
+}
+
+// refreshEnvs reloads the environment names after one is created or
+// deleted, keeping the cursor on selected when it still exists.
+func (m *Model) refreshEnvs(selected string) {
+	m.envNames = m.configManager.GetAvailableEnvironments()
+	m.envCursor = min(m.envCursor, max(len(m.envNames)-1, 0))
+	for i, name := range m.envNames {
+		if name == selected {
+			m.envCursor = i
+		}
+	}
+	m.envVarCursor = min(m.envVarCursor, max(len(m.selectedEnvVars())-1, 0))
+}
+
+// selectedEnv is the environment under the cursor, or "" when there are
+// none.
+func (m Model) selectedEnv() string {
+	if m.envCursor >= len(m.envNames) {
+		return ""
+	}
+	return m.envNames[m.envCursor]
+}
+
+// selectedEnvVars lists the variable names of the environment under the
+// cursor, sorted.
+func (m Model) selectedEnvVars() []string {
+	if m.configManager == nil || m.selectedEnv() == "" {
+		return nil
+	}
+	vars := m.configManager.environmentVariables(m.selectedEnv())
+	names := make([]string, 0, len(vars))
+	for k := range vars {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (m Model) updateEnvs(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	env := m.selectedEnv()
+	vars := m.selectedEnvVars()
+	switch {
+	case key.Matches(msg, keys.Quit):
+		return m.quit()
+
+	case key.Matches(msg, keys.Cancel) && m.envVarFocus, key.Matches(msg, keys.Tab), key.Matches(msg, keys.ShiftTab):
+		m.envVarFocus = !m.envVarFocus && env != ""
+		m.envVarCursor = 0
+
+	case key.Matches(msg, keys.Cancel), key.Matches(msg, keys.ToggleEnvs):
+		m.showEnvs = false
+
+	case key.Matches(msg, keys.Up):
+		if m.envVarFocus {
+			m.envVarCursor = max(m.envVarCursor-1, 0)
+		} else if m.envCursor > 0 {
+			m.envCursor--
+		}
+
+	case key.Matches(msg, keys.Down):
+		if m.envVarFocus {
+			m.envVarCursor = max(min(m.envVarCursor+1, len(vars)-1), 0)
+		} else if m.envCursor < len(m.envNames)-1 {
+			m.envCursor++
+		}
+
+	case key.Matches(msg, keys.RevealSecrets):
+		m.revealSecrets = !m.revealSecrets
+
+	case m.configManager == nil:
+		return m, nil
+
+	case key.Matches(msg, keys.NewEnv):
+		return m.openPrompt(promptNewEnv, "New environment: ", "")
+
+	case key.Matches(msg, keys.AddVariable) && env != "":
+		m.envVarEdit = ""
+		return m.openPrompt(promptSetEnvVar, "Add to "+env+" (NAME=value): ", "")
+
+	case m.envVarFocus && key.Matches(msg, keys.Enter):
+		if m.envVarCursor >= len(vars) {
+			return m, nil
+		}
+		name := vars[m.envVarCursor]
+		m.envVarEdit = name
+		value := m.configManager.environmentVariables(env)[name]
+		if !m.revealSecrets && m.configManager.maskSecrets() && isSecretName(name) {
+			// Don't reveal a masked secret just by editing it
+			value = ""
+		}
+		return m.openPrompt(promptSetEnvVar, "Edit in "+env+" (NAME=value): ", name+"="+value)
+
+	case m.envVarFocus && key.Matches(msg, keys.DeleteItem):
+		if m.envVarCursor >= len(vars) {
+			return m, nil
+		}
+		m.envVarEdit = vars[m.envVarCursor]
+		m.confirmKind = confirmDeleteEnvVar
+		m.confirmText = fmt.Sprintf("Delete %s from %q?", m.envVarEdit, env)
+
+	case key.Matches(msg, keys.DeleteItem) && env != "":
+		m.confirmKind = confirmDeleteEnv
+		m.confirmText = fmt.Sprintf("Delete environment %q and its %d variable(s)?", env, len(vars))
+		if env == m.configManager.currentEnvName() {
+			if next := nextCurrentEnv(m.envNames, env); next != "" {
+				m.confirmText += fmt.Sprintf(" %q becomes the active environment.", next)
+			} else {
+				m.confirmText += " No environment will be active."
+			}
+		}
+
+	case key.Matches(msg, keys.Enter):
+		if env == "" {
+			return m, nil
+		}
+		if err := m.configManager.SetCurrentEnv(env); err != nil {
+			m.requestError = err
+			return m, nil
+		}
+		m.statusMessage = "Switched to environment " + env
+	}
+	return m, nil
+}
+
+// nextCurrentEnv picks the environment that becomes active when the active
+// one, deleted, is removed from names: the first of the others.
+func nextCurrentEnv(names []string, deleted string) string {
+	for _, name := range names {
+		if name != deleted {
+			return name
+		}
+	}
+	return ""
+}
+
+// renderEnvs lists the environments with the active one marked, followed
+// by the variables of the one under the cursor.
+func (m Model) renderEnvs() string {
+	if m.configManager == nil || len(m.envNames) == 0 {
+		return "No environments configured"
+	}
+
+	var sb strings.Builder
+	mask := !m.revealSecrets && m.configManager.maskSecrets()
+	help := "↑/↓: select • enter: activate • n: new • d: delete • a: add variable • tab: variables • esc: close"
+	if m.envVarFocus {
+		help = "↑/↓: select • enter: edit • a: add • d: delete • tab/esc: environments"
+	}
+	if m.configManager.maskSecrets() {
+		if mask {
+			help += " • v: reveal secrets"
+		} else {
+			help += " • v: hide secrets"
+		}
+	}
+	sb.WriteString("Environments (" + help + "):\n")
+	current := m.configManager.currentEnvName()
+	for i, name := range m.envNames {
+		cursor := "  "
+		if i == m.envCursor {
+			cursor = "> "
+		}
+		if name == current {
+			sb.WriteString(cursor + m.styles.activeEnv.Render("▸ "+name) + "\n")
+		} else {
+			sb.WriteString(cursor + "  " + name + "\n")
+		}
+	}
+
+	if m.configManager.useOSEnv() {
+		sb.WriteString(m.styles.help.Render("Placeholders not defined here are read from the OS environment (use_os_env)") + "\n")
+	}
+
+	vars := m.configManager.environmentVariables(m.selectedEnv())
+	names := m.selectedEnvVars()
+	if len(names) == 0 {
+		if m.envVarFocus {
+			sb.WriteString("\nNo variables (a: add)\n")
+		}
+		return sb.String()
+	}
+	sb.WriteString("\nVariables:\n")
+	for i, k := range names {
+		line := fmt.Sprintf("%s: %s", k, displayValue(k, vars[k], mask))
+		if m.envVarFocus {
+			cursor := "  "
+			if i == m.envVarCursor {
+				cursor = "> "
+			}
+			line = cursor + line
+		}
+		sb.WriteString(line + "\n")
+	}
+	return sb.String()
+}
+
+func (cm *ConfigManager) maskSecrets() bool {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+	return cm.Config.MaskSecrets
+}
+
+// currentEnvName returns the configured current environment.
+func (cm *ConfigManager) currentEnvName() string {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+	return cm.Config.CurrentEnv
+}
+
+func (cm *ConfigManager) environmentVariables(name string) map[string]string {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+	return cm.Environments[name].Variables
+}
+
+// CreateEnvironment adds an environment with no variables.
+func (cm *ConfigManager) CreateEnvironment(name string) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return fmt.Errorf("environment name can't be empty")
+	}
+
+	cm.mu.Lock()
+	if _, exists := cm.Environments[name]; exists {
+		cm.mu.Unlock()
+		return fmt.Errorf("environment %q already exists", name)
+	}
+	if cm.Environments == nil {
+		cm.Environments = make(map[string]Environment)
+	}
+	cm.Environments[name] = Environment{Name: name, Variables: map[string]string{}}
+	cm.mu.Unlock()
+
+	return cm.saveEnvironments()
+}
+
+// DeleteEnvironment removes an environment and its variables. Deleting the
+// active environment makes the first remaining one, by name, active so
+// current_env never names a missing environment.
+func (cm *ConfigManager) DeleteEnvironment(name string) error {
+	cm.mu.Lock()
+	if _, exists := cm.Environments[name]; !exists {
+		cm.mu.Unlock()
+		return fmt.Errorf("environment %q not found", name)
+	}
+	delete(cm.Environments, name)
+	if cm.Config.CurrentEnv == name {
+		remaining := make([]string, 0, len(cm.Environments))
+		for n := range cm.Environments {
+			remaining = append(remaining, n)
+		}
+		sort.Strings(remaining)
+		cm.Config.CurrentEnv = nextCurrentEnv(remaining, name)
+		if err := cm.saveConfigLocked(); err != nil {
+			cm.mu.Unlock()
+			return err
+		}
+	}
+	cm.mu.Unlock()
+
+	return cm.saveEnvironments()
+}
+
+// SetEnvVar adds a variable to an environment or changes its value.
+func (cm *ConfigManager) SetEnvVar(env, key, value string) error {
+	key = strings.TrimSpace(key)
+	if key == "" {
+		return fmt.Errorf("variable name can't be empty")
+	}
+	if strings.ContainsAny(key, " \t{}") {
+		return fmt.Errorf("variable name %q can't contain spaces or braces", key)
+	}
+
+	cm.mu.Lock()
+	e, exists := cm.Environments[env]
+	if !exists {
+		cm.mu.Unlock()
+		return fmt.Errorf("environment %q not found", env)
+	}
+	if e.Variables == nil {
+		e.Variables = make(map[string]string)
+	}
+	e.Variables[key] = value
+	cm.Environments[env] = e
+	cm.mu.Unlock()
+
+	return cm.saveEnvironments()
+}
+
+// DeleteEnvVar removes a variable from an environment.
+func (cm *ConfigManager) DeleteEnvVar(env, key string) error {
+	cm.mu.Lock()
+	e, exists := cm.Environments[env]
+	if !exists {
+		cm.mu.Unlock()
+		return fmt.Errorf("environment %q not found", env)
+	}
+	if _, exists := e.Variables[key]; !exists {
+		cm.mu.Unlock()
+		return fmt.Errorf("variable %s not found in %q", key, env)
+	}
+	delete(e.Variables, key)
+	cm.mu.Unlock()
+
+	return cm.saveEnvironments()
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDisplayValue(t *testing.T) {
+	long := strings.Repeat("x", 200)
+	tests := []struct {
+		name     string
+		variable string
+		value    string
+		mask     bool
+		want     string
+	}{
+		{"short secret", "API_KEY", "k", true, secretMask},
+		{"empty secret", "DB_PASSWORD", "", true, secretMask},
+		{"long secret keeps a fixed width", "AUTH_TOKEN", long, true, secretMask},
+		{"suffix ignores case", "client_secret", "s3cret", true, secretMask},
+		{"not a secret", "BASE_URL", "https://example.com", true, "https://example.com"},
+		{"suffix must end the name", "KEY_ID", "abc", true, "abc"},
+		{"masking off", "API_KEY", "k", false, "k"},
+		{"masking off, long secret", "AUTH_TOKEN", long, false, long},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := displayValue(tt.variable, tt.value, tt.mask); got != tt.want {
+				t.Errorf("displayValue(%q, %q, %v) = %q, want %q", tt.variable, tt.value, tt.mask, got, tt.want)
+			}
+		})
+	}
+}
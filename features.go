@@ -4,8 +4,11 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -18,60 +21,156 @@ const (
 	historyFile      = "history.json"
 	configFile       = "config.json"
 	defaultHistLimit = 100
+	configDirEnvVar  = "API_CLIENT_TUI_HOME"
 )
 
 type RequestItem struct {
-	ID          string            `json:"id"`
-	Name        string            `json:"name"`
-	URL         string            `json:"url"`
-	Method      string            `json:"method"`
-	Headers     map[string]string `json:"headers"`
-	Body        string            `json:"body"`
-	CreatedAt   time.Time         `json:"created_at"`
-	LastUsed    time.Time         `json:"last_used"`
-	Collections []string          `json:"collections,omitempty"`
+	ID            string            `json:"id"`
+	Name          string            `json:"name"`
+	URL           string            `json:"url"`
+	Method        string            `json:"method"`
+	Headers       map[string]string `json:"headers"`
+	Body          string            `json:"body"`
+	CreatedAt     time.Time         `json:"created_at"`
+	LastUsed      time.Time         `json:"last_used"`
+	Collections   []string          `json:"collections,omitempty"`
+	Timeout       int               `json:"timeout,omitempty"`
+	TrailingSlash string            `json:"trailing_slash,omitempty"`
+	Summary       string            `json:"summary_template,omitempty"`
+	// SchemaPath is a JSON Schema file responses are validated against.
+	SchemaPath    string            `json:"schema_path,omitempty"`
+	AllowGetBody  bool              `json:"allow_get_body,omitempty"`
+	Tags          []string          `json:"tags,omitempty"`
+	PathParams    map[string]string `json:"path_params,omitempty"`
+	Auth          *AuthConfig       `json:"auth,omitempty"`
+	Query         url.Values        `json:"query,omitempty"`
+	// GraphQL requests send Body as the query, with Variables, as a JSON
+	// POST.
+	GraphQL       bool              `json:"graphql,omitempty"`
+	Variables     string            `json:"graphql_variables,omitempty"`
 }
 
 type Collection struct {
 	Name     string        `json:"name"`
 	Requests []RequestItem `json:"requests"`
+	Tags     []string      `json:"tags,omitempty"`
 }
 
 type Environment struct {
 	Name      string            `json:"name"`
 	Variables map[string]string `json:"variables"`
+	Timeout   int               `json:"timeout,omitempty"`
 }
 
 type Config struct {
-	Theme             string `json:"theme"`
-	Timeout           int    `json:"timeout"`
-	HistoryLimit      int    `json:"history_limit"`
-	AutoFormatJSON    bool   `json:"auto_format_json"`
-	SaveHistory       bool   `json:"save_history"`
-	CurrentEnv        string `json:"current_env"`
-	ShowResponseTime  bool   `json:"show_response_time"`
-	TruncateResponse  int    `json:"truncate_response"`
-	SyntaxHighlighting bool   `json:"syntax_highlighting"`
+	Theme                string               `json:"theme"`
+	Timeout              int                  `json:"timeout"`
+	HistoryLimit         int                  `json:"history_limit"`
+	AutoFormatJSON       bool                 `json:"auto_format_json"`
+	SaveHistory          bool                 `json:"save_history"`
+	CurrentEnv           string               `json:"current_env"`
+	ShowResponseTime     bool                 `json:"show_response_time"`
+	TruncateResponse     int                  `json:"truncate_response"`
+	SyntaxHighlighting   bool                 `json:"syntax_highlighting"`
+	AdvertiseCompression bool                 `json:"advertise_compression"`
+	RedactSharedSecrets  bool                 `json:"redact_shared_secrets"`
+	MaxConcurrency       int                  `json:"max_concurrency"`
+	ProtectedEnvs        []string             `json:"protected_environments"`
+	AutoFocusResponse    bool                 `json:"auto_focus_response"`
+	DashboardInterval    int                  `json:"dashboard_interval"`
+	TrailingSlash        string               `json:"trailing_slash"`
+	ShowInformational    bool                 `json:"show_informational"`
+	SummaryTemplate      string               `json:"summary_template"`
+	AllowGetBody         bool                 `json:"allow_get_body"`
+	PromptSaveOnSuccess  bool                 `json:"prompt_save_on_success"`
+	IdleConnTimeout      int                  `json:"idle_conn_timeout"`
+	MaxIdleConnsPerHost  int                  `json:"max_idle_conns_per_host"`
+	StatusHandlers       map[int]StatusAction `json:"status_handlers,omitempty"`
+	CopySummaryFields    []string             `json:"copy_summary_fields,omitempty"`
+	HistoryDisplayLimit  int                  `json:"history_display_limit"`
+	FollowRedirects      bool                 `json:"follow_redirects"`
+	MaxRedirects         int                  `json:"max_redirects"`
+	DownloadDir          string               `json:"download_dir"`
+	MaskSecrets          bool                 `json:"mask_secrets"`
+	RecordHAR            bool                 `json:"record_har"`
+	InsecureSkipVerify   bool                 `json:"insecure_skip_verify"`
+	ProxyURL             string               `json:"proxy_url,omitempty"`
+	NoProxy              []string             `json:"no_proxy,omitempty"`
+	// Keybindings maps action names to the keys that replace their
+	// defaults, see applyKeybindings.
+	Keybindings          map[string][]string  `json:"keybindings,omitempty"`
+	// Colors overrides individual colors of the theme, see resolvePalette.
+	Colors               map[string]string    `json:"colors,omitempty"`
+	// MaxResponseBytes limits the response body size, see
+	// maxResponseBytes. TruncateResponse is the display limit in KB.
+	MaxResponseBytes     int64                `json:"max_response_bytes"`
+	// UseOSEnv fills placeholders the current environment doesn't define
+	// from OS environment variables, see replaceOSEnvVars.
+	UseOSEnv             bool                 `json:"use_os_env"`
+	// ConfirmDestructive asks before sending DELETE, PUT and PATCH
+	// requests in any environment, see confirmDestructive.
+	ConfirmDestructive   bool                 `json:"confirm_destructive"`
+	// Layout is saved on quit and restored on start, see restoreLayout.
+	Layout               LayoutState          `json:"layout"`
 }
 
 type ConfigManager struct {
-	Config      Config
-	History     []RequestItem
-	Collections map[string]Collection
-	Environments map[string]Environment
-	configDir   string
-	mu          sync.RWMutex
+	Config        Config
+	History       []RequestItem
+	Collections   map[string]Collection
+	Environments  map[string]Environment
+	configDir     string
+	mu            sync.RWMutex
+	transport     *http.Transport
+	transportOnce sync.Once
+	conns         connStats
+	// historyQueue feeds historyWorker, see queueHistory.
+	historyQueue   chan RequestItem
+	historyOnce    sync.Once
+	historyPending sync.WaitGroup
 }
 
-func NewConfigManager() (*ConfigManager, error) {
-	homeDir, err := os.UserHomeDir()
+// resolveConfigDir picks the config directory: an explicit override (from
+// --config-dir) wins, then $API_CLIENT_TUI_HOME, then ~/.api-client-tui.
+// The directory is created if needed and must be writable.
+func resolveConfigDir(override string) (string, error) {
+	dir := override
+	if dir == "" {
+		dir = os.Getenv(configDirEnvVar)
+	}
+	if dir == "" {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to get home directory: %w", err)
+		}
+		dir = filepath.Join(homeDir, configDir)
+	}
+
+	dir, err := filepath.Abs(dir)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get home directory: %w", err)
+		return "", fmt.Errorf("invalid config directory %q: %w", dir, err)
 	}
 
-	configDir := filepath.Join(homeDir, configDir)
-	if err := os.MkdirAll(configDir, 0755); err != nil {
-		return nil, fmt.Errorf("failed to create config directory: %w", err)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create config directory %s: %w", dir, err)
+	}
+
+	probe, err := os.CreateTemp(dir, ".write-test-*")
+	if err != nil {
+		return "", fmt.Errorf("config directory %s is not writable: %w", dir, err)
+	}
+	probe.Close()
+	os.Remove(probe.Name())
+
+	return dir, nil
+}
+
+// NewConfigManager loads the configuration from dir, or from the default
+// location resolved by resolveConfigDir when dir is empty.
+func NewConfigManager(dir string) (*ConfigManager, error) {
+	configDir, err := resolveConfigDir(dir)
+	if err != nil {
+		return nil, err
 	}
 
 	cm := &ConfigManager{
@@ -87,6 +186,17 @@ func NewConfigManager() (*ConfigManager, error) {
 			ShowResponseTime:  true,
 			TruncateResponse:  1000,
 			SyntaxHighlighting: true,
+			AdvertiseCompression: true,
+			RedactSharedSecrets: true,
+			MaxConcurrency:    defaultMaxConcurrency,
+			ProtectedEnvs:     []string{"prod"},
+			TrailingSlash:     trailingSlashLeave,
+			ShowInformational: true,
+			HistoryDisplayLimit: defaultHistoryDisplayLimit,
+			FollowRedirects:   true,
+			MaxRedirects:      defaultMaxRedirects,
+			MaskSecrets:       true,
+			MaxResponseBytes:  defaultMaxResponseBytes,
 		},
 	}
 
@@ -167,7 +277,10 @@ func (cm *ConfigManager) loadHistory() error {
 func (cm *ConfigManager) saveHistory() error {
 	cm.mu.Lock()
 	defer cm.mu.Unlock()
-	
+	return cm.saveHistoryLocked()
+}
+
+func (cm *ConfigManager) saveHistoryLocked() error {
 	if !cm.Config.SaveHistory {
 		return nil
 	}
@@ -182,7 +295,30 @@ func (cm *ConfigManager) saveHistory() error {
 		return err
 	}
 
-	return os.WriteFile(historyPath, bytes, 0644)
+	return writeFileAtomic(historyPath, bytes, 0644)
+}
+
+// writeFileAtomic writes data to a temporary file next to path and renames
+// it into place, so a crash mid-write can't leave path truncated.
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // No-op once renamed
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(perm); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
 }
 
 func (cm *ConfigManager) addToHistory(req RequestItem) error {
@@ -191,11 +327,15 @@ func (cm *ConfigManager) addToHistory(req RequestItem) error {
 	
 	for i, item := range cm.History {
 		if item.URL == req.URL && item.Method == req.Method {
-			cm.History[i].LastUsed = time.Now()
-			if i > 0 {
-				cm.History = append([]RequestItem{cm.History[i]}, append(cm.History[:i], cm.History[i+1:]...)...)
-			}
-			return cm.saveHistory()
+			// Build a new slice rather than shifting in place, so
+			// snapshots taken by historyItems aren't changed under them
+			history := make([]RequestItem, 0, len(cm.History))
+			history = append(history, item)
+			history[0].LastUsed = time.Now()
+			history = append(history, cm.History[:i]...)
+			history = append(history, cm.History[i+1:]...)
+			cm.History = history
+			return cm.saveHistoryLocked()
 		}
 	}
 
@@ -203,7 +343,7 @@ func (cm *ConfigManager) addToHistory(req RequestItem) error {
 	req.LastUsed = time.Now()
 	cm.History = append([]RequestItem{req}, cm.History...)
 
-	return cm.saveHistory()
+	return cm.saveHistoryLocked()
 }
 
 func (cm *ConfigManager) loadCollections() error {
@@ -352,27 +492,114 @@ func (cm *ConfigManager) getCurrentEnvironment() Environment {
 	cm.mu.RLock()
 	defer cm.mu.RUnlock()
 	
-	env, exists := cm.Environments[cm.Config.CurrentEnv]
-	if !exists && len(cm.Environments) > 0 {
-		for _, e := range cm.Environments {
-			return e
+	name := cm.Config.CurrentEnv
+	if _, exists := cm.Environments[name]; !exists && len(cm.Environments) > 0 {
+		// Fall back to the first environment by name, so the choice is
+		// the same on every call
+		names := make([]string, 0, len(cm.Environments))
+		for n := range cm.Environments {
+			names = append(names, n)
 		}
+		sort.Strings(names)
+		name = names[0]
+	}
+	env := cm.Environments[name]
+	if env.Name == "" && len(cm.Environments) > 0 {
+		env.Name = name
 	}
 	return env
 }
 
+// isProtectedEnv reports whether requests in the named environment need an
+// explicit confirmation. Entries in Config.ProtectedEnvs match any
+// environment whose name contains them, ignoring case, so the default
+// "prod" covers both "prod" and "production".
+func (cm *ConfigManager) isProtectedEnv(name string) bool {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+
+	if name == "" {
+		return false
+	}
+	for _, protected := range cm.Config.ProtectedEnvs {
+		if protected != "" && strings.Contains(strings.ToLower(name), strings.ToLower(protected)) {
+			return true
+		}
+	}
+	return false
+}
+
+// confirmDestructive reports whether sending with method needs an explicit
+// confirmation because Config.ConfirmDestructive is set and the method
+// changes or deletes data.
+func (cm *ConfigManager) confirmDestructive(method string) bool {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+
+	if !cm.Config.ConfirmDestructive {
+		return false
+	}
+	switch strings.ToUpper(method) {
+	case http.MethodDelete, http.MethodPut, http.MethodPatch:
+		return true
+	}
+	return false
+}
+
+// requestTimeout resolves the timeout for a request in seconds. The active
+// environment's override wins, then the request's own override, then
+// Config.Timeout.
+func (cm *ConfigManager) requestTimeout(perRequest int) int {
+	if env := cm.getCurrentEnvironment(); env.Timeout > 0 {
+		return env.Timeout
+	}
+	if perRequest > 0 {
+		return perRequest
+	}
+
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+	return cm.Config.Timeout
+}
+
+// trailingSlashMode returns the trailing-slash handling for a request: its
+// own setting if it has one, otherwise the configured default.
+func (cm *ConfigManager) trailingSlashMode(perRequest string) string {
+	if perRequest != "" {
+		return perRequest
+	}
+
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+	return cm.Config.TrailingSlash
+}
+
+// allowGetBody reports whether a GET request should carry its body, either
+// because the request opts in or because it is enabled globally.
+func (cm *ConfigManager) allowGetBody(perRequest bool) bool {
+	if perRequest || cm == nil {
+		return perRequest
+	}
+
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+	return cm.Config.AllowGetBody
+}
+
+// replaceEnvVars substitutes {{VARIABLE}} placeholders from the current
+// environment, then, with use_os_env, from the OS environment.
 func (cm *ConfigManager) replaceEnvVars(input string) string {
 	// We use getCurrentEnvironment which already has RLock
 	env := cm.getCurrentEnvironment()
-	if env.Variables == nil {
-		return input
-	}
 
 	result := input
 	for key, value := range env.Variables {
 		placeholder := fmt.Sprintf("{{%s}}", key)
 		result = strings.ReplaceAll(result, placeholder, value)
 	}
+	if cm.useOSEnv() {
+		result = replaceOSEnvVars(result)
+	}
 	return result
 }
 
@@ -402,6 +629,7 @@ func (cm *ConfigManager) GetAvailableEnvironments() []string {
 	for name := range cm.Environments {
 		envs = append(envs, name)
 	}
+	sort.Strings(envs)
 	
 	return envs
 }
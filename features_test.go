@@ -0,0 +1,129 @@
+package main
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRequestTimeoutPrecedence(t *testing.T) {
+	tests := []struct {
+		name       string
+		config     int
+		env        int
+		perRequest int
+		want       int
+	}{
+		{"config only", 30, 0, 0, 30},
+		{"request overrides config", 30, 0, 5, 5},
+		{"environment overrides request", 30, 60, 5, 60},
+		{"environment overrides config", 30, 60, 0, 60},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cm := newTestConfigManager(t)
+			cm.Config.Timeout = tt.config
+			cm.Environments = map[string]Environment{"dev": {Name: "dev", Timeout: tt.env}}
+			cm.Config.CurrentEnv = "dev"
+			if got := cm.requestTimeout(tt.perRequest); got != tt.want {
+				t.Errorf("requestTimeout(%d) = %d, want %d", tt.perRequest, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildRequestSpecSubstitutesEnvironment(t *testing.T) {
+	m := initialModel(t.TempDir())
+	m.configManager.Environments = map[string]Environment{"dev": {Name: "dev", Variables: map[string]string{
+		"BASE_URL": "https://dev.example.com",
+		"TOKEN":    "secret",
+		"NAME":     "ada",
+	}}}
+	m.configManager.Config.CurrentEnv = "dev"
+
+	spec, err := m.buildRequestSpec(RequestItem{
+		Method:  "POST",
+		URL:     "{{BASE_URL}}/users",
+		Headers: map[string]string{"Authorization": "Bearer {{TOKEN}}", "X-Trace": "{{UNKNOWN}}"},
+		Body:    `{"name": "{{NAME}}"}`,
+	})
+	if err != nil {
+		t.Fatalf("buildRequestSpec: %v", err)
+	}
+	if spec.URL != "https://dev.example.com/users" {
+		t.Errorf("URL = %q", spec.URL)
+	}
+	if got := spec.Headers.Get("Authorization"); got != "Bearer secret" {
+		t.Errorf("Authorization = %q", got)
+	}
+	if got := spec.Headers.Get("X-Trace"); got != "{{UNKNOWN}}" {
+		t.Errorf("unknown variable should be left alone, got %q", got)
+	}
+	if spec.Body != `{"name": "ada"}` {
+		t.Errorf("body = %q", spec.Body)
+	}
+}
+
+func TestGetBodySentOnlyWhenAllowed(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(w, r.Body)
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		name       string
+		global     bool
+		perRequest bool
+		want       string
+	}{
+		{"off", false, false, ""},
+		{"per request", false, true, `{"query": 1}`},
+		{"global", true, false, `{"query": 1}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := initialModel(t.TempDir())
+			m.configManager.Config.AllowGetBody = tt.global
+			spec, err := m.buildRequestSpec(RequestItem{Method: "GET", URL: srv.URL, Body: `{"query": 1}`, AllowGetBody: tt.perRequest})
+			if err != nil {
+				t.Fatalf("buildRequestSpec: %v", err)
+			}
+			res := doRequest(context.Background(), srv.Client(), spec)
+			if res.Error != nil {
+				t.Fatalf("doRequest: %v", res.Error)
+			}
+			if res.Body != tt.want {
+				t.Errorf("server received %q, want %q", res.Body, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetCurrentEnvironmentFallback(t *testing.T) {
+	cm := newTestConfigManager(t)
+	cm.Environments = map[string]Environment{
+		"staging":     {Name: "staging"},
+		"production":  {Name: "production"},
+		"development": {Name: "development"},
+		"unnamed":     {},
+	}
+
+	cm.Config.CurrentEnv = ""
+	for i := 0; i < 100; i++ {
+		if got := cm.getCurrentEnvironment().Name; got != "development" {
+			t.Fatalf("call %d: fallback = %q, want the first by name", i, got)
+		}
+	}
+
+	cm.Config.CurrentEnv = "missing"
+	if got := cm.getCurrentEnvironment().Name; got != "development" {
+		t.Errorf("unknown current_env: fallback = %q, want development", got)
+	}
+
+	cm.Config.CurrentEnv = "unnamed"
+	if got := cm.getCurrentEnvironment().Name; got != "unnamed" {
+		t.Errorf("environment without a name field = %q, want its key", got)
+	}
+}
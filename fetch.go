@@ -0,0 +1,62 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// jsString quotes s as a JavaScript string literal. JSON strings are valid
+// JS, and encoding/json escapes U+2028 and U+2029, which JS doesn't allow
+// unescaped in older engines.
+func jsString(s string) string {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(s)
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// requestToFetch renders a request as a browser fetch() call. Headers are
+// a JSON object with sorted keys; the body is left out for GET and HEAD,
+// which fetch rejects with one.
+func requestToFetch(method, url string, headers map[string]string, body string) string {
+	var opts []string
+	opts = append(opts, "  method: "+jsString(method))
+	if len(headers) > 0 {
+		var buf bytes.Buffer
+		enc := json.NewEncoder(&buf)
+		enc.SetEscapeHTML(false)
+		enc.SetIndent("  ", "  ")
+		_ = enc.Encode(headers)
+		opts = append(opts, "  headers: "+strings.TrimSuffix(buf.String(), "\n"))
+	}
+	if body != "" && method != http.MethodGet && method != http.MethodHead {
+		opts = append(opts, "  body: "+jsString(body))
+	}
+	return "fetch(" + jsString(url) + ", {\n" + strings.Join(opts, ",\n") + "\n});"
+}
+
+// fetchSnippet is the editor request as a fetch() call, with environment
+// and {{response.*}} placeholders substituted like the review layout.
+// Dynamic values such as {{$uuid}} are left as written.
+func (m Model) fetchSnippet() (string, error) {
+	req := m.currentRequest()
+	method, body := req.Method, m.resolveVars(m.lastBody)
+	if req.GraphQL {
+		wrapped, err := graphQLBody(body, m.resolveVars(req.Variables))
+		if err != nil {
+			return "", err
+		}
+		method, body = http.MethodPost, wrapped
+	}
+	headers := make(map[string]string, len(req.Headers)+1)
+	for k, v := range req.Headers {
+		headers[k] = m.resolveVars(v)
+	}
+	if authHeader := req.Auth.header(m.resolveVars); authHeader != "" {
+		headers["Authorization"] = authHeader
+	}
+	return requestToFetch(method, m.effectiveURL(req), headers, body), nil
+}
@@ -0,0 +1,57 @@
+package main
+
+import "testing"
+
+func TestRequestToFetch(t *testing.T) {
+	tests := []struct {
+		name    string
+		method  string
+		url     string
+		headers map[string]string
+		body    string
+		want    string
+	}{
+		{
+			name:   "get drops the body",
+			method: "GET",
+			url:    "https://example.com/users?q=a b",
+			body:   "ignored",
+			want: "fetch(\"https://example.com/users?q=a b\", {\n" +
+				"  method: \"GET\"\n" +
+				"});",
+		},
+		{
+			name:    "quotes, newlines and backslashes",
+			method:  "POST",
+			url:     "https://example.com/it's",
+			headers: map[string]string{"X-Quote": `say "hi"`, "X-Path": `C:\tmp`, "Content-Type": "application/json"},
+			body:    "{\"msg\": \"line1\\nline2\", \"path\": \"C:\\\\dir\"}\nnext 'line'",
+			want: "fetch(\"https://example.com/it's\", {\n" +
+				"  method: \"POST\",\n" +
+				"  headers: {\n" +
+				"    \"Content-Type\": \"application/json\",\n" +
+				"    \"X-Path\": \"C:\\\\tmp\",\n" +
+				"    \"X-Quote\": \"say \\\"hi\\\"\"\n" +
+				"  },\n" +
+				"  body: \"{\\\"msg\\\": \\\"line1\\\\nline2\\\", \\\"path\\\": \\\"C:\\\\\\\\dir\\\"}\\nnext 'line'\"\n" +
+				"});",
+		},
+		{
+			name:   "line and paragraph separators and html are safe",
+			method: "PUT",
+			url:    "https://example.com/</script>",
+			body:   "a\u2028b\u2029c<&>",
+			want: "fetch(\"https://example.com/</script>\", {\n" +
+				"  method: \"PUT\",\n" +
+				"  body: \"a\\u2028b\\u2029c<&>\"\n" +
+				"});",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := requestToFetch(tt.method, tt.url, tt.headers, tt.body); got != tt.want {
+				t.Errorf("requestToFetch =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,267 @@
+package main
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// isJSONContentType reports whether contentType is JSON or a JSON-based
+// type such as application/problem+json or application/x-ndjson.
+func isJSONContentType(contentType string) bool {
+	mediaType := strings.ToLower(strings.TrimSpace(strings.Split(contentType, ";")[0]))
+	return mediaType == "application/json" ||
+		strings.HasSuffix(mediaType, "+json") ||
+		isNDJSONContentType(mediaType)
+}
+
+// isNDJSONContentType reports whether contentType is newline-delimited
+// JSON: application/x-ndjson, application/jsonl and the like.
+func isNDJSONContentType(contentType string) bool {
+	mediaType := strings.ToLower(strings.TrimSpace(strings.Split(contentType, ";")[0]))
+	return strings.HasSuffix(mediaType, "ndjson") || strings.HasSuffix(mediaType, "jsonl")
+}
+
+// jsonlDocs pretty-prints each non-blank line of data as a JSON document
+// of its own. It fails on the first line that isn't valid JSON, giving
+// its line number.
+func jsonlDocs(data []byte, indent string) ([]string, error) {
+	var docs []string
+	for i, line := range bytes.Split(data, []byte("\n")) {
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+
+		var pretty bytes.Buffer
+		if err := json.Indent(&pretty, line, "", indent); err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+		docs = append(docs, pretty.String())
+	}
+	return docs, nil
+}
+
+// formatJSONL pretty-prints newline-delimited JSON, one document per line.
+// It reports false when data isn't JSONL: a blank body, a single document,
+// or any non-blank line that isn't valid JSON on its own.
+func formatJSONL(data []byte, indent string) (string, bool) {
+	docs, err := jsonlDocs(data, indent)
+	if err != nil || len(docs) < 2 {
+		return "", false
+	}
+	return strings.Join(docs, "\n"), true
+}
+
+// ndjsonSeparator goes between the documents of a formatted NDJSON body.
+var ndjsonSeparator = strings.Repeat("─", 8)
+
+// formatNDJSON formats a body served as NDJSON: each line as its own
+// indented block, with a separator between them. Unlike formatJSONL a
+// single document is fine, and a line that doesn't parse is reported.
+func formatNDJSON(body []byte) (string, error) {
+	docs, err := jsonlDocs(body, "  ")
+	if err != nil {
+		return "", err
+	}
+	if len(docs) == 0 {
+		return "", fmt.Errorf("no JSON documents")
+	}
+	return strings.Join(docs, "\n"+ndjsonSeparator+"\n"), nil
+}
+
+// looksLikeNDJSON reports whether body is JSONL (see formatJSONL) made of
+// objects or arrays, for NDJSON served as text/plain or application/json.
+func looksLikeNDJSON(body []byte) bool {
+	for _, line := range bytes.Split(body, []byte("\n")) {
+		line = bytes.TrimSpace(line)
+		if len(line) > 0 && line[0] != '{' && line[0] != '[' {
+			return false
+		}
+	}
+	_, ok := formatJSONL(body, "")
+	return ok
+}
+
+// statusStyleFor colors a status code by class: green for 2xx, yellow for
+// 3xx, orange for 4xx and red for 5xx.
+func statusStyleFor(st *styles, code int) lipgloss.Style {
+	switch code / 100 {
+	case 2:
+		return st.status2xx
+	case 3:
+		return st.status3xx
+	case 4:
+		return st.status4xx
+	case 5:
+		return st.status5xx
+	}
+	return st.statusOther
+}
+
+// statusReason returns the reason phrase the server sent, or the canonical
+// one for the code when it sent none.
+func statusReason(code int, status string) string {
+	reason := strings.TrimSpace(strings.TrimPrefix(status, strconv.Itoa(code)))
+	if reason == "" {
+		reason = http.StatusText(code)
+	}
+	return reason
+}
+
+// formatMetadata renders the diagnostic details of a response as an aligned
+// label/value block. Rows without a value are left out.
+func formatMetadata(r Response) string {
+	rows := [][2]string{
+		{"Protocol", r.Proto},
+		{"Time", r.ResponseTime.String()},
+		{"Phases", formatTimingsInline(r.Timings)},
+	}
+
+	rows = append(rows, [2]string{"Size", formatSize(r)})
+
+	if r.TLS != nil {
+		rows = append(rows, [2]string{"TLS", fmt.Sprintf("%s, %s", tls.VersionName(r.TLS.Version), tls.CipherSuiteName(r.TLS.CipherSuite))})
+		if r.TLS.NegotiatedProtocol != "" {
+			rows = append(rows, [2]string{"ALPN", r.TLS.NegotiatedProtocol})
+		}
+	}
+
+	if server := r.Headers.Get("Server"); server != "" {
+		rows = append(rows, [2]string{"Server", server})
+	}
+
+	var sb strings.Builder
+	for _, row := range rows {
+		if row[1] == "" {
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("%-10s%s\n", row[0]+":", row[1]))
+	}
+	return sb.String()
+}
+
+// formatInformational lists 1xx responses with their headers, e.g. the
+// Link headers of 103 Early Hints.
+func formatInformational(responses []InformationalResponse) string {
+	var sb strings.Builder
+	for _, r := range responses {
+		sb.WriteString(fmt.Sprintf("%d %s\n", r.StatusCode, http.StatusText(r.StatusCode)))
+		keys := make([]string, 0, len(r.Headers))
+		for k := range r.Headers {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			for _, v := range r.Headers[k] {
+				sb.WriteString(fmt.Sprintf("  %s: %s\n", k, v))
+			}
+		}
+	}
+	return sb.String()
+}
+
+// formatHeaders lists response headers one per line, sorted by name so the
+// same response always renders the same way. Names are canonicalized, and
+// values of names that only differed in case are joined.
+func formatHeaders(h http.Header) string {
+	// Merge names that differ only in case in a fixed order, so their
+	// values are joined the same way every time
+	names := make([]string, 0, len(h))
+	for k := range h {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	canonical := make(map[string][]string, len(h))
+	for _, k := range names {
+		name := http.CanonicalHeaderKey(k)
+		canonical[name] = append(canonical[name], h[k]...)
+	}
+	keys := make([]string, 0, len(canonical))
+	for k := range canonical {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var sb strings.Builder
+	for _, k := range keys {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", k, strings.Join(canonical[k], ", ")))
+	}
+	return sb.String()
+}
+
+// formatSize describes the body size of r. For compressed responses both
+// the size on the wire and the decoded size are shown.
+func formatSize(r Response) string {
+	if r.ContentEncoding == "" || strings.EqualFold(r.ContentEncoding, "identity") {
+		if r.DecodedSize == 0 && r.ContentLength > 0 {
+			return formatBytes(r.ContentLength)
+		}
+		return formatBytes(r.DecodedSize)
+	}
+
+	wire := "unknown"
+	if r.WireSize >= 0 {
+		wire = formatBytes(r.WireSize)
+	}
+	return fmt.Sprintf("%s %s on the wire, %s decoded", wire, r.ContentEncoding, formatBytes(r.DecodedSize))
+}
+
+func formatBytes(n int64) string {
+	switch {
+	case n >= 1024*1024:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1024*1024))
+	case n >= 1024:
+		return fmt.Sprintf("%.1f KB", float64(n)/1024)
+	default:
+		return fmt.Sprintf("%d B", n)
+	}
+}
+
+// defaultCopySummaryFields are used when Config.CopySummaryFields is unset.
+var defaultCopySummaryFields = []string{"status", "time", "content-type", "content-length", "location"}
+
+// statusSummary builds a one-line summary of r from the given fields:
+// "status", "time", "size", "url", or any header name. Empty fields are
+// skipped.
+func statusSummary(r Response, fields []string) string {
+	var parts []string
+	for _, field := range fields {
+		value := ""
+		switch strings.ToLower(strings.TrimSpace(field)) {
+		case "status":
+			value = r.Status
+			if value == "" && r.Error != nil {
+				value = "Error: " + r.Error.Error()
+			}
+		case "time":
+			value = r.ResponseTime.Round(time.Millisecond).String()
+		case "size":
+			value = formatSize(r)
+		case "url":
+			value = r.Sent.URL
+		case "content-length":
+			value = r.Headers.Get("Content-Length")
+			if value == "" {
+				value = fmt.Sprintf("%d", r.DecodedSize)
+			}
+			value = "Content-Length: " + value
+		default:
+			if v := r.Headers.Get(field); v != "" {
+				value = http.CanonicalHeaderKey(field) + ": " + v
+			}
+		}
+		if value != "" {
+			parts = append(parts, value)
+		}
+	}
+	return strings.Join(parts, " | ")
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestFormatJSONL(t *testing.T) {
+	tests := []struct {
+		name   string
+		data   string
+		want   string
+		wantOK bool
+	}{
+		{"two documents", "{\"a\":1}\n\n[true]\n", "{\n  \"a\": 1\n}\n[\n  true\n]", true},
+		{"single document", `{"a":1}`, "", false},
+		{"blank", "\n \n", "", false},
+		{"invalid line", "{\"a\":1}\n{bad\n", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := formatJSONL([]byte(tt.data), "  ")
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("formatJSONL = %q, %v, want %q, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestFormatNDJSON(t *testing.T) {
+	got, err := formatNDJSON([]byte("{\"a\":1}\n[2]\n"))
+	want := "{\n  \"a\": 1\n}\n" + ndjsonSeparator + "\n[\n  2\n]"
+	if err != nil || got != want {
+		t.Errorf("formatNDJSON = %q, %v, want %q", got, err, want)
+	}
+
+	if got, err := formatNDJSON([]byte(`{"a":1}`)); err != nil || got != "{\n  \"a\": 1\n}" {
+		t.Errorf("single document = %q, %v", got, err)
+	}
+	if _, err := formatNDJSON([]byte("{\"a\":1}\n{bad\n")); err == nil || err.Error()[:7] != "line 2:" {
+		t.Errorf("invalid line error = %v, want it to name line 2", err)
+	}
+	if _, err := formatNDJSON(nil); err == nil {
+		t.Error("empty body should fail")
+	}
+}
+
+func TestLooksLikeNDJSON(t *testing.T) {
+	tests := map[string]bool{
+		"{\"a\":1}\n{\"b\":2}\n": true,
+		"[1]\n\n{\"b\":2}":       true,
+		`{"a":1}`:                false,
+		"1\n2\n":                 false,
+		"{\"a\":1}\nplain text":  false,
+	}
+	for body, want := range tests {
+		if got := looksLikeNDJSON([]byte(body)); got != want {
+			t.Errorf("looksLikeNDJSON(%q) = %v, want %v", body, got, want)
+		}
+	}
+}
+
+func TestFormatSize(t *testing.T) {
+	tests := []struct {
+		name string
+		r    Response
+		want string
+	}{
+		{"uncompressed", Response{DecodedSize: 512}, "512 B"},
+		{"identity", Response{ContentEncoding: "identity", DecodedSize: 2048}, "2.0 KB"},
+		{"content length only", Response{ContentLength: 300}, "300 B"},
+		{"compressed", Response{ContentEncoding: "gzip", WireSize: 1024, DecodedSize: 4 * 1024 * 1024}, "1.0 KB gzip on the wire, 4.0 MB decoded"},
+		{"decompressed by the transport", Response{ContentEncoding: "gzip", WireSize: -1, DecodedSize: 4096}, "unknown gzip on the wire, 4.0 KB decoded"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatSize(tt.r); got != tt.want {
+				t.Errorf("formatSize = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatHeadersOrder(t *testing.T) {
+	h := http.Header{
+		"X-Request-Id":  {"abc"},
+		"content-type":  {"application/json"},
+		"Cache-Control": {"no-cache"},
+		"Set-Cookie":    {"a=1", "b=2"},
+		"set-cookie":    {"c=3"},
+	}
+	want := "Cache-Control: no-cache\n" +
+		"Content-Type: application/json\n" +
+		"Set-Cookie: a=1, b=2, c=3\n" +
+		"X-Request-Id: abc\n"
+	// Map order is random, so check it comes out the same every time
+	for i := 0; i < 20; i++ {
+		if got := formatHeaders(h); got != want {
+			t.Fatalf("formatHeaders =\n%s\nwant\n%s", got, want)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// graphQLBody wraps a query and its variables in the JSON envelope GraphQL
+// servers expect. Blank variables are left out.
+func graphQLBody(query, variables string) (string, error) {
+	payload := struct {
+		Query     string          `json:"query"`
+		Variables json.RawMessage `json:"variables,omitempty"`
+	}{Query: query}
+
+	if vars := strings.TrimSpace(variables); vars != "" {
+		if !json.Valid([]byte(vars)) {
+			return "", fmt.Errorf("GraphQL variables aren't valid JSON")
+		}
+		payload.Variables = json.RawMessage(vars)
+	}
+
+	encoded, err := json.Marshal(payload)
+	if err != nil {
+		return "", err
+	}
+	return string(encoded), nil
+}
+
+// formatGraphQLErrors lists the entries of a GraphQL response's errors
+// array, one per line with the path they apply to. It's empty when body
+// has no errors.
+func formatGraphQLErrors(st *styles, body string) string {
+	var resp struct {
+		Errors []struct {
+			Message string        `json:"message"`
+			Path    []interface{} `json:"path"`
+		} `json:"errors"`
+	}
+	if err := json.Unmarshal([]byte(body), &resp); err != nil || len(resp.Errors) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString(st.error.Render(fmt.Sprintf("GraphQL errors (%d):", len(resp.Errors))) + "\n")
+	for _, e := range resp.Errors {
+		line := "  • " + e.Message
+		if len(e.Path) > 0 {
+			parts := make([]string, len(e.Path))
+			for i, p := range e.Path {
+				parts[i] = fmt.Sprint(p)
+			}
+			line += " (at " + strings.Join(parts, ".") + ")"
+		}
+		sb.WriteString(st.error.Render(line) + "\n")
+	}
+	return sb.String()
+}
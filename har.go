@@ -0,0 +1,251 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// harRecord is a completed request kept for the session's HAR file.
+type harRecord struct {
+	Started  time.Time
+	Response Response
+}
+
+// The har* types follow the HAR 1.2 spec, so the file opens in browser
+// devtools and proxies such as Charles.
+type harLog struct {
+	Log struct {
+		Version string     `json:"version"`
+		Creator harCreator `json:"creator"`
+		Entries []harEntry `json:"entries"`
+	} `json:"log"`
+}
+
+type harCreator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type harEntry struct {
+	StartedDateTime string      `json:"startedDateTime"`
+	Time            float64     `json:"time"`
+	Request         harRequest  `json:"request"`
+	Response        harResponse `json:"response"`
+	Cache           struct{}    `json:"cache"`
+	Timings         harTimings  `json:"timings"`
+	Comment         string      `json:"comment,omitempty"`
+}
+
+type harNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type harRequest struct {
+	Method      string         `json:"method"`
+	URL         string         `json:"url"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []harNameValue `json:"cookies"`
+	Headers     []harNameValue `json:"headers"`
+	QueryString []harNameValue `json:"queryString"`
+	PostData    *harPostData   `json:"postData,omitempty"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+type harPostData struct {
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+}
+
+type harResponse struct {
+	Status      int            `json:"status"`
+	StatusText  string         `json:"statusText"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []harNameValue `json:"cookies"`
+	Headers     []harNameValue `json:"headers"`
+	Content     harContent     `json:"content"`
+	RedirectURL string         `json:"redirectURL"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+type harContent struct {
+	Size     int    `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+}
+
+// harTimings are in milliseconds; -1 means the phase doesn't apply.
+type harTimings struct {
+	Blocked float64 `json:"blocked"`
+	DNS     float64 `json:"dns"`
+	Connect float64 `json:"connect"`
+	SSL     float64 `json:"ssl"`
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+}
+
+// encodeHAR renders records as a HAR 1.2 document.
+func encodeHAR(records []harRecord) ([]byte, error) {
+	var doc harLog
+	doc.Log.Version = "1.2"
+	doc.Log.Creator = harCreator{Name: "api-client-tui", Version: "1.0"}
+	doc.Log.Entries = make([]harEntry, 0, len(records))
+	for _, rec := range records {
+		doc.Log.Entries = append(doc.Log.Entries, harEntryFor(rec))
+	}
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+func harEntryFor(rec harRecord) harEntry {
+	r := rec.Response
+	sent := r.Sent
+
+	entry := harEntry{
+		StartedDateTime: rec.Started.Format(time.RFC3339Nano),
+		Time:            harMillis(r.ResponseTime),
+		Timings:         harPhases(r.Timings, r.ResponseTime),
+		Request: harRequest{
+			Method:      sent.Method,
+			URL:         sent.URL,
+			HTTPVersion: harProto(r.Proto),
+			Cookies:     []harNameValue{},
+			Headers:     harHeaders(sent.Headers),
+			QueryString: []harNameValue{},
+			HeadersSize: -1,
+			BodySize:    0,
+		},
+		Response: harResponse{
+			Status:      r.StatusCode,
+			StatusText:  http.StatusText(r.StatusCode),
+			HTTPVersion: harProto(r.Proto),
+			Cookies:     []harNameValue{},
+			Headers:     harHeaders(r.Headers),
+			Content: harContent{
+				Size:     len(r.Body),
+				MimeType: r.Headers.Get("Content-Type"),
+				Text:     r.Body,
+			},
+			RedirectURL: r.Headers.Get("Location"),
+			HeadersSize: -1,
+			BodySize:    -1,
+		},
+	}
+	if r.WireSize >= 0 && r.StatusCode > 0 {
+		entry.Response.BodySize = int(r.WireSize)
+	}
+	if r.Error != nil {
+		entry.Comment = r.Error.Error()
+	}
+
+	if u, err := url.Parse(sent.URL); err == nil {
+		for _, k := range sortedKeys(u.Query()) {
+			for _, v := range u.Query()[k] {
+				entry.Request.QueryString = append(entry.Request.QueryString, harNameValue{Name: k, Value: v})
+			}
+		}
+	}
+	if sent.HasBody {
+		entry.Request.BodySize = len(sent.Body)
+		entry.Request.PostData = &harPostData{MimeType: sent.Headers.Get("Content-Type"), Text: sent.Body}
+		if sent.BodyFile != "" {
+			// The file was streamed, so only its name is known
+			entry.Request.BodySize = -1
+			entry.Request.PostData.Text = "@" + sent.BodyFile
+		}
+	}
+	return entry
+}
+
+// harPhases maps Timings onto HAR's phases. HAR counts the TLS handshake
+// as part of connect, and the phases add up to the total response time.
+func harPhases(t Timings, total time.Duration) harTimings {
+	phase := func(d time.Duration) float64 {
+		if d <= 0 {
+			return -1
+		}
+		return harMillis(d)
+	}
+
+	h := harTimings{
+		Blocked: -1,
+		DNS:     phase(t.DNS),
+		Connect: phase(t.Connect + t.TLS),
+		SSL:     phase(t.TLS),
+		Wait:    harMillis(total),
+	}
+	if t.TTFB > 0 && t.TTFB <= total {
+		wait := t.TTFB - t.DNS - t.Connect - t.TLS
+		if wait < 0 {
+			wait = 0
+		}
+		h.Wait = harMillis(wait)
+		h.Receive = harMillis(total - t.TTFB)
+	}
+	return h
+}
+
+func harMillis(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
+
+// harProto returns the protocol as HAR writes it, e.g. HTTP/1.1.
+func harProto(proto string) string {
+	if proto == "" {
+		return "HTTP/1.1"
+	}
+	return proto
+}
+
+func harHeaders(h http.Header) []harNameValue {
+	out := []harNameValue{}
+	for _, k := range sortedKeys(url.Values(h)) {
+		for _, v := range h[k] {
+			out = append(out, harNameValue{Name: k, Value: v})
+		}
+	}
+	return out
+}
+
+func sortedKeys(values url.Values) []string {
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// recordHAR reports whether completed requests should be kept for a HAR
+// file.
+func (cm *ConfigManager) recordHAR() bool {
+	if cm == nil {
+		return false
+	}
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+	return cm.Config.RecordHAR
+}
+
+// writeHAR saves records to session-<timestamp>.har in dir, named after
+// the first request's start, and returns the path. records must not be
+// empty.
+func writeHAR(dir string, records []harRecord) (string, error) {
+	data, err := encodeHAR(records)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	name := "session-" + records[0].Started.Format("20060102-150405") + ".har"
+	path := filepath.Join(dir, name)
+	return path, os.WriteFile(path, data, 0644)
+}
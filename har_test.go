@@ -0,0 +1,103 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestEncodeHAR(t *testing.T) {
+	started := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	records := []harRecord{
+		{
+			Started: started,
+			Response: Response{
+				StatusCode:   http.StatusCreated,
+				Proto:        "HTTP/2.0",
+				Headers:      http.Header{"Content-Type": {"application/json"}},
+				Body:         `{"id":1}`,
+				WireSize:     8,
+				ResponseTime: 100 * time.Millisecond,
+				Timings: Timings{
+					DNS:     10 * time.Millisecond,
+					Connect: 20 * time.Millisecond,
+					TLS:     30 * time.Millisecond,
+					TTFB:    80 * time.Millisecond,
+				},
+				Sent: SentRequest{
+					Method:  http.MethodPost,
+					URL:     "https://example.com/users?b=2&a=1",
+					Headers: http.Header{"Content-Type": {"application/json"}, "Accept": {"*/*"}},
+					Body:    `{"name":"ada"}`,
+					HasBody: true,
+				},
+			},
+		},
+		{
+			Started:  started.Add(time.Second),
+			Response: Response{Error: errors.New("connection refused"), WireSize: -1, Sent: SentRequest{Method: http.MethodGet, URL: "http://localhost:1"}},
+		},
+	}
+
+	data, err := encodeHAR(records)
+	if err != nil {
+		t.Fatalf("encodeHAR: %v", err)
+	}
+	var doc harLog
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("output doesn't decode: %v", err)
+	}
+
+	if doc.Log.Version != "1.2" || doc.Log.Creator.Name == "" {
+		t.Errorf("log version %q, creator %+v", doc.Log.Version, doc.Log.Creator)
+	}
+	if len(doc.Log.Entries) != 2 {
+		t.Fatalf("got %d entries, want 2", len(doc.Log.Entries))
+	}
+
+	e := doc.Log.Entries[0]
+	if e.StartedDateTime != "2024-03-01T12:00:00Z" {
+		t.Errorf("startedDateTime = %q", e.StartedDateTime)
+	}
+	if e.Time != 100 {
+		t.Errorf("time = %v, want 100", e.Time)
+	}
+	if e.Request.Method != "POST" || e.Request.HTTPVersion != "HTTP/2.0" {
+		t.Errorf("request = %s %s", e.Request.Method, e.Request.HTTPVersion)
+	}
+	wantQuery := []harNameValue{{Name: "a", Value: "1"}, {Name: "b", Value: "2"}}
+	if len(e.Request.QueryString) != 2 || e.Request.QueryString[0] != wantQuery[0] || e.Request.QueryString[1] != wantQuery[1] {
+		t.Errorf("queryString = %+v, want %+v", e.Request.QueryString, wantQuery)
+	}
+	if len(e.Request.Headers) != 2 || e.Request.Headers[0].Name != "Accept" {
+		t.Errorf("request headers not sorted: %+v", e.Request.Headers)
+	}
+	if e.Request.PostData == nil || e.Request.PostData.Text != `{"name":"ada"}` || e.Request.BodySize != 14 {
+		t.Errorf("postData = %+v, bodySize %d", e.Request.PostData, e.Request.BodySize)
+	}
+	if e.Response.Status != 201 || e.Response.StatusText != "Created" || e.Response.BodySize != 8 {
+		t.Errorf("response = %d %q, bodySize %d", e.Response.Status, e.Response.StatusText, e.Response.BodySize)
+	}
+	if e.Response.Content.MimeType != "application/json" || e.Response.Content.Text != `{"id":1}` {
+		t.Errorf("content = %+v", e.Response.Content)
+	}
+
+	// Connect includes the TLS handshake, and the phases add up to the total
+	want := harTimings{Blocked: -1, DNS: 10, Connect: 50, SSL: 30, Wait: 20, Receive: 20}
+	if e.Timings != want {
+		t.Errorf("timings = %+v, want %+v", e.Timings, want)
+	}
+	if sum := e.Timings.DNS + e.Timings.Connect + e.Timings.Send + e.Timings.Wait + e.Timings.Receive; sum != e.Time {
+		t.Errorf("phases add up to %v, want %v", sum, e.Time)
+	}
+
+	failed := doc.Log.Entries[1]
+	if failed.Comment != "connection refused" || failed.Response.BodySize != -1 || failed.Request.PostData != nil {
+		t.Errorf("failed entry = %+v", failed)
+	}
+	if failed.Timings.DNS != -1 || failed.Timings.Connect != -1 {
+		t.Errorf("unknown phases should be -1: %+v", failed.Timings)
+	}
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// styleWrap splits what style.Render adds around text into a prefix and a
+// suffix, so tokens can be colored without a Render call each.
+func styleWrap(style lipgloss.Style) (prefix, suffix string) {
+	const marker = "\x00"
+	before, after, ok := strings.Cut(style.Render(marker), marker)
+	if !ok {
+		return "", ""
+	}
+	return before, after
+}
+
+// highlightJSON colors keys, strings, numbers, booleans and null in
+// pretty-printed JSON. It's a single pass over s and tolerates invalid
+// input, which is passed through uncolored.
+func highlightJSON(st *styles, s string) string {
+	keyOpen, keyClose := styleWrap(st.jsonKey)
+	strOpen, strClose := styleWrap(st.jsonString)
+	numOpen, numClose := styleWrap(st.jsonNumber)
+	boolOpen, boolClose := styleWrap(st.jsonBool)
+	nullOpen, nullClose := styleWrap(st.jsonNull)
+
+	var sb strings.Builder
+	sb.Grow(len(s) * 2)
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == '"':
+			end := i + 1
+			for end < len(s) && s[end] != '"' && s[end] != '\n' {
+				if s[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end < len(s) && s[end] == '"' {
+				end++
+			}
+			if end > len(s) {
+				end = len(s)
+			}
+
+			// A string followed by a colon is an object key
+			next := end
+			for next < len(s) && (s[next] == ' ' || s[next] == '\t') {
+				next++
+			}
+			if next < len(s) && s[next] == ':' {
+				sb.WriteString(keyOpen + s[i:end] + keyClose)
+			} else {
+				sb.WriteString(strOpen + s[i:end] + strClose)
+			}
+			i = end
+
+		case c == '-' || (c >= '0' && c <= '9'):
+			end := i + 1
+			for end < len(s) && strings.IndexByte("0123456789.eE+-", s[end]) >= 0 {
+				end++
+			}
+			sb.WriteString(numOpen + s[i:end] + numClose)
+			i = end
+
+		case strings.HasPrefix(s[i:], "true"):
+			sb.WriteString(boolOpen + "true" + boolClose)
+			i += 4
+
+		case strings.HasPrefix(s[i:], "false"):
+			sb.WriteString(boolOpen + "false" + boolClose)
+			i += 5
+
+		case strings.HasPrefix(s[i:], "null"):
+			sb.WriteString(nullOpen + "null" + nullClose)
+			i += 4
+
+		default:
+			sb.WriteByte(c)
+			i++
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/list"
+)
+
+// historyDetailBodyLines caps how much of the body the detail view shows.
+const historyDetailBodyLines = 8
+
+// defaultHistoryDisplayLimit is used when Config.HistoryDisplayLimit is
+// unset.
+const defaultHistoryDisplayLimit = 50
+
+// historyQueueSize is how many completed requests can wait for the history
+// worker before senders block.
+const historyQueueSize = 64
+
+// historyEntry is a history item as shown in the searchable history panel.
+// Filtering matches the method, URL and name.
+type historyEntry struct {
+	RequestItem
+}
+
+func (h historyEntry) Title() string { return h.Method + " " + h.URL }
+
+func (h historyEntry) Description() string {
+	when := "never"
+	if !h.LastUsed.IsZero() {
+		when = h.LastUsed.Format("Jan 2 15:04")
+	} else if !h.CreatedAt.IsZero() {
+		when = h.CreatedAt.Format("Jan 2 15:04")
+	}
+	if h.Name != "" && h.Name != h.Title() {
+		return fmt.Sprintf("%s • %s", h.Name, when)
+	}
+	return when
+}
+
+func (h historyEntry) FilterValue() string { return h.Method + " " + h.URL + " " + h.Name }
+
+// historyDisplayLimit is how many of the most recent history entries the
+// panel lists.
+func (cm *ConfigManager) historyDisplayLimit() int {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+	if cm.Config.HistoryDisplayLimit > 0 {
+		return cm.Config.HistoryDisplayLimit
+	}
+	return defaultHistoryDisplayLimit
+}
+
+// historySnapshot returns a copy of the history that's safe to use while
+// requests keep completing.
+func (cm *ConfigManager) historySnapshot() []RequestItem {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+	return append([]RequestItem(nil), cm.History...)
+}
+
+// queueHistory adds req to the history in the background. All writes go
+// through one worker in the order requests complete, so rapid or
+// concurrent sends never race to save history.json.
+func (cm *ConfigManager) queueHistory(req RequestItem) {
+	cm.historyOnce.Do(func() {
+		cm.historyQueue = make(chan RequestItem, historyQueueSize)
+		go cm.historyWorker()
+	})
+	cm.historyPending.Add(1)
+	cm.historyQueue <- req
+}
+
+func (cm *ConfigManager) historyWorker() {
+	for req := range cm.historyQueue {
+		_ = cm.addToHistory(req)
+		cm.historyPending.Done()
+	}
+}
+
+// flushHistory waits for queued history writes so none are lost on quit.
+func (cm *ConfigManager) flushHistory() {
+	cm.historyPending.Wait()
+}
+
+// refreshHistory loads the history panel list from the current history.
+func (m *Model) refreshHistory() {
+	entries := m.historyItems()
+	items := make([]list.Item, len(entries))
+	for i, e := range entries {
+		items[i] = historyEntry{e}
+	}
+	m.historyList.ResetFilter()
+	m.historyList.SetItems(items)
+	m.historyList.Select(0)
+	m.historyList.Title = fmt.Sprintf("History (%d) • enter: load • R: load and send • r: replay • e: override • /: search", len(entries))
+}
+
+// formatHistoryDetail shows the headers and the start of the body of the
+// selected history entry.
+func formatHistoryDetail(req RequestItem) string {
+	var sb strings.Builder
+	sb.WriteString(req.Method + " " + req.URL + "\n")
+
+	keys := make([]string, 0, len(req.Headers))
+	for k := range req.Headers {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", k, req.Headers[k]))
+	}
+
+	if req.Body != "" {
+		lines := strings.Split(req.Body, "\n")
+		if len(lines) > historyDetailBodyLines {
+			lines = append(lines[:historyDetailBodyLines], fmt.Sprintf("… (%d more lines)", len(lines)-historyDetailBodyLines))
+		}
+		sb.WriteString("\n" + strings.Join(lines, "\n") + "\n")
+	}
+	return sb.String()
+}
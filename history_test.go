@@ -0,0 +1,59 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+// newTestConfigManager returns a ConfigManager backed by a temporary
+// directory.
+func newTestConfigManager(t *testing.T) *ConfigManager {
+	t.Helper()
+	cm, err := NewConfigManager(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewConfigManager: %v", err)
+	}
+	return cm
+}
+
+func TestQueueHistoryConcurrent(t *testing.T) {
+	cm := newTestConfigManager(t)
+	const senders = 200
+	cm.Config.HistoryLimit = senders
+
+	var wg sync.WaitGroup
+	for i := 0; i < senders; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			cm.queueHistory(RequestItem{Method: "GET", URL: fmt.Sprintf("https://example.com/%d", i)})
+		}(i)
+	}
+	wg.Wait()
+	cm.flushHistory()
+
+	data, err := os.ReadFile(filepath.Join(cm.configDir, historyFile))
+	if err != nil {
+		t.Fatalf("reading history: %v", err)
+	}
+	var saved []RequestItem
+	if err := json.Unmarshal(data, &saved); err != nil {
+		t.Fatalf("history.json doesn't parse: %v", err)
+	}
+	if len(saved) != senders {
+		t.Fatalf("history has %d entries, want %d", len(saved), senders)
+	}
+	seen := map[string]bool{}
+	for _, req := range saved {
+		seen[req.URL] = true
+	}
+	for i := 0; i < senders; i++ {
+		if url := fmt.Sprintf("https://example.com/%d", i); !seen[url] {
+			t.Errorf("missing %s", url)
+		}
+	}
+}
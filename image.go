@@ -0,0 +1,84 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	_ "image/gif" // Registers the decoders image.DecodeConfig uses
+	_ "image/jpeg"
+	_ "image/png"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+// Thumbnails fit in thumbnailCols × thumbnailRows cells, each cell showing
+// two pixels stacked with a half block. Images with more than
+// maxThumbnailPixels aren't decoded for one.
+const (
+	thumbnailCols      = 48
+	thumbnailRows      = 20
+	maxThumbnailPixels = 40_000_000
+)
+
+// isImageContentType reports whether contentType is a bitmap image. SVG is
+// XML and is formatted as such.
+func isImageContentType(contentType string) bool {
+	mediaType := strings.ToLower(strings.TrimSpace(strings.Split(contentType, ";")[0]))
+	return strings.HasPrefix(mediaType, "image/") && !strings.HasSuffix(mediaType, "+xml")
+}
+
+// formatImage describes an image body instead of showing its bytes, e.g.
+// "Image response: 640×480 PNG, 12.0 KB", followed by a thumbnail when the
+// terminal has colors and the format can be decoded (PNG, JPEG, GIF).
+func formatImage(contentType string, body []byte) string {
+	size := formatBytes(int64(len(body)))
+	config, format, err := image.DecodeConfig(bytes.NewReader(body))
+	if err != nil {
+		mediaType := strings.TrimSpace(strings.Split(contentType, ";")[0])
+		return fmt.Sprintf("Image response: %s, %s (w saves it to a file)", mediaType, size)
+	}
+
+	summary := fmt.Sprintf("Image response: %d×%d %s, %s (w saves it to a file)",
+		config.Width, config.Height, strings.ToUpper(format), size)
+	if lipgloss.ColorProfile() == termenv.Ascii || config.Width*config.Height > maxThumbnailPixels {
+		return summary
+	}
+	img, _, err := image.Decode(bytes.NewReader(body))
+	if err != nil {
+		return summary
+	}
+	return summary + "\n\n" + renderThumbnail(img, thumbnailCols, thumbnailRows)
+}
+
+// renderThumbnail scales img to fit cols × rows cells, keeping its aspect
+// ratio, and draws it with upper half blocks: the foreground color is the
+// top pixel and the background the bottom one. Pixels are sampled, not
+// averaged, which is plenty for a preview.
+func renderThumbnail(img image.Image, cols, rows int) string {
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	if w == 0 || h == 0 {
+		return ""
+	}
+	scale := min(float64(cols)/float64(w), float64(rows*2)/float64(h), 1)
+	outW, outH := max(int(float64(w)*scale), 1), max(int(float64(h)*scale), 1)
+
+	pixel := func(x, y int) lipgloss.Color {
+		r, g, b, _ := img.At(bounds.Min.X+x*w/outW, bounds.Min.Y+y*h/outH).RGBA()
+		return lipgloss.Color(fmt.Sprintf("#%02x%02x%02x", r>>8, g>>8, b>>8))
+	}
+	var sb strings.Builder
+	for y := 0; y < outH; y += 2 {
+		for x := 0; x < outW; x++ {
+			style := lipgloss.NewStyle().Foreground(pixel(x, y))
+			if y+1 < outH {
+				style = style.Background(pixel(x, y+1))
+			}
+			sb.WriteString(style.Render("▀"))
+		}
+		sb.WriteString("\n")
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
@@ -0,0 +1,210 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var identifierPattern = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+// flattenJSON walks a JSON document and returns one `path = value` line per
+// leaf, e.g. `$.user.name = "Alice"` or `$.items[0].id = 7`. Object keys are
+// visited in sorted order so the output is stable.
+func flattenJSON(body []byte) ([]string, error) {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+
+	var doc interface{}
+	if err := dec.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+
+	var lines []string
+	walkJSON("$", doc, func(path string, value interface{}) {
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			encoded = []byte(fmt.Sprint(value))
+		}
+		lines = append(lines, path+" = "+string(encoded))
+	})
+	return lines, nil
+}
+
+func walkJSON(path string, value interface{}, leaf func(path string, value interface{})) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if len(v) == 0 {
+			leaf(path, v)
+			return
+		}
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			walkJSON(joinJSONPath(path, k), v[k], leaf)
+		}
+	case []interface{}:
+		if len(v) == 0 {
+			leaf(path, v)
+			return
+		}
+		for i, elem := range v {
+			walkJSON(fmt.Sprintf("%s[%d]", path, i), elem, leaf)
+		}
+	default:
+		leaf(path, v)
+	}
+}
+
+// joinJSONPath appends key to path using dot notation when the key is a
+// plain identifier and bracket notation otherwise.
+func joinJSONPath(path, key string) string {
+	if identifierPattern.MatchString(key) {
+		return path + "." + key
+	}
+	quoted, _ := json.Marshal(key)
+	return path + "[" + string(quoted) + "]"
+}
+
+// pathItem is a flattened JSON leaf shown in the paths list.
+type pathItem struct {
+	path  string
+	value string
+}
+
+func newPathItem(line string) pathItem {
+	path, value, _ := strings.Cut(line, " = ")
+	return pathItem{path: path, value: value}
+}
+
+func (p pathItem) Title() string       { return p.path }
+func (p pathItem) Description() string { return p.value }
+func (p pathItem) FilterValue() string { return p.path }
+
+// extractJSONPath returns the value at path in a JSON document. Path
+// segments are object keys or array indices separated by dots, e.g.
+// `data.items.0.id`; the `$.items[0]["id"]` form shown in the paths list
+// works too. Strings are returned unquoted and anything else as compact
+// JSON.
+func extractJSONPath(body []byte, path string) (string, error) {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	var doc interface{}
+	if err := dec.Decode(&doc); err != nil {
+		return "", fmt.Errorf("invalid JSON: %w", err)
+	}
+	return lookupJSONPath(doc, path)
+}
+
+// lookupJSONPath is extractJSONPath for a document that has already been
+// decoded.
+func lookupJSONPath(doc interface{}, path string) (string, error) {
+	segments, err := splitJSONPath(path)
+	if err != nil {
+		return "", err
+	}
+
+	value := doc
+	at := "$"
+	for _, seg := range segments {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			next, ok := v[seg]
+			if !ok {
+				return "", fmt.Errorf("no key %q at %s", seg, at)
+			}
+			value = next
+			at = joinJSONPath(at, seg)
+		case []interface{}:
+			i, err := strconv.Atoi(seg)
+			if err != nil {
+				return "", fmt.Errorf("%s is an array, %q is not an index", at, seg)
+			}
+			if i < 0 || i >= len(v) {
+				return "", fmt.Errorf("index %d out of range at %s (length %d)", i, at, len(v))
+			}
+			value = v[i]
+			at = fmt.Sprintf("%s[%d]", at, i)
+		default:
+			return "", fmt.Errorf("%s is not an object or array, can't look up %q", at, seg)
+		}
+	}
+
+	if s, ok := value.(string); ok {
+		return s, nil
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	return string(encoded), nil
+}
+
+// splitJSONPath breaks a path into its keys and indices. A leading `$` is
+// optional and bracketed segments may be numbers or quoted keys.
+func splitJSONPath(path string) ([]string, error) {
+	p := strings.TrimSpace(path)
+	p = strings.TrimPrefix(p, "$")
+	if p == "" {
+		return nil, fmt.Errorf("empty path")
+	}
+
+	var segments []string
+	for len(p) > 0 {
+		switch p[0] {
+		case '.':
+			p = p[1:]
+			end := strings.IndexAny(p, ".[")
+			if end < 0 {
+				end = len(p)
+			}
+			if end == 0 {
+				return nil, fmt.Errorf("empty segment in path %q", path)
+			}
+			segments = append(segments, p[:end])
+			p = p[end:]
+
+		case '[':
+			end := strings.IndexByte(p, ']')
+			if strings.HasPrefix(p, `["`) {
+				// Quoted keys may themselves contain ']'
+				end = strings.Index(p, `"]`)
+				if end >= 0 {
+					end++
+				}
+			}
+			if end < 0 {
+				return nil, fmt.Errorf("unclosed [ in path %q", path)
+			}
+			inner := p[1:end]
+			if strings.HasPrefix(inner, `"`) {
+				var key string
+				if err := json.Unmarshal([]byte(inner), &key); err != nil {
+					return nil, fmt.Errorf("bad quoted key %s in path %q", inner, path)
+				}
+				segments = append(segments, key)
+			} else {
+				if _, err := strconv.Atoi(inner); err != nil {
+					return nil, fmt.Errorf("bad index [%s] in path %q", inner, path)
+				}
+				segments = append(segments, inner)
+			}
+			p = p[end+1:]
+
+		default:
+			if len(segments) > 0 {
+				return nil, fmt.Errorf("expected . or [ in path %q", path)
+			}
+			// A path without `$` starts with a bare key
+			p = "." + p
+		}
+	}
+	return segments, nil
+}
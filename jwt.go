@@ -0,0 +1,171 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// jwtPattern matches compact JWS tokens. Both the header and the payload of
+// a JWT are JSON objects, so their base64url encodings start with "eyJ".
+var jwtPattern = regexp.MustCompile(`eyJ[A-Za-z0-9_-]+\.eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`)
+
+// jwtTimeClaims are the registered claims holding NumericDate values.
+var jwtTimeClaims = map[string]bool{"exp": true, "iat": true, "nbf": true, "auth_time": true}
+
+// jwtCandidate is a token found in the request or response, along with
+// where it was found.
+type jwtCandidate struct {
+	Source string
+	Token  string
+}
+
+// decodeJWT decodes the header and payload of a JWT. The signature is not
+// verified and never decoded.
+func decodeJWT(token string) (header, payload map[string]interface{}, err error) {
+	parts := strings.Split(strings.TrimSpace(token), ".")
+	if len(parts) != 3 {
+		return nil, nil, fmt.Errorf("not a JWT: expected 3 segments, got %d", len(parts))
+	}
+
+	if header, err = decodeJWTSegment(parts[0]); err != nil {
+		return nil, nil, fmt.Errorf("invalid JWT header: %w", err)
+	}
+	if payload, err = decodeJWTSegment(parts[1]); err != nil {
+		return nil, nil, fmt.Errorf("invalid JWT payload: %w", err)
+	}
+	return header, payload, nil
+}
+
+func decodeJWTSegment(segment string) (map[string]interface{}, error) {
+	data, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(segment, "="))
+	if err != nil {
+		return nil, err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var claims map[string]interface{}
+	if err := dec.Decode(&claims); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// findJWTs collects tokens from the request headers, the response headers
+// and the response body, skipping duplicates.
+func findJWTs(reqHeaders map[string]string, respHeaders http.Header, body string) []jwtCandidate {
+	var found []jwtCandidate
+	seen := make(map[string]bool)
+	add := func(source, text string) {
+		for i, token := range jwtPattern.FindAllString(text, -1) {
+			if seen[token] {
+				continue
+			}
+			seen[token] = true
+			label := source
+			if i > 0 {
+				label = fmt.Sprintf("%s #%d", source, i+1)
+			}
+			found = append(found, jwtCandidate{Source: label, Token: token})
+		}
+	}
+
+	reqKeys := make([]string, 0, len(reqHeaders))
+	for k := range reqHeaders {
+		reqKeys = append(reqKeys, k)
+	}
+	sort.Strings(reqKeys)
+	for _, k := range reqKeys {
+		add("Request header "+k, reqHeaders[k])
+	}
+
+	respKeys := make([]string, 0, len(respHeaders))
+	for k := range respHeaders {
+		respKeys = append(respKeys, k)
+	}
+	sort.Strings(respKeys)
+	for _, k := range respKeys {
+		add("Response header "+k, strings.Join(respHeaders[k], "\n"))
+	}
+
+	add("Response body", body)
+	return found
+}
+
+// formatJWT renders decoded claims for display. Time claims get a
+// human-readable date next to the raw value, and exp notes whether the
+// token has expired.
+func formatJWT(header, payload map[string]interface{}, now time.Time) string {
+	var sb strings.Builder
+	sb.WriteString("Header:\n")
+	writeJWTClaims(&sb, header, now)
+	sb.WriteString("\nPayload:\n")
+	writeJWTClaims(&sb, payload, now)
+	sb.WriteString("\nSignature: not shown (not verified)")
+	return sb.String()
+}
+
+func writeJWTClaims(sb *strings.Builder, claims map[string]interface{}, now time.Time) {
+	keys := make([]string, 0, len(claims))
+	for k := range claims {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		encoded, err := json.Marshal(claims[k])
+		if err != nil {
+			encoded = []byte(fmt.Sprint(claims[k]))
+		}
+		sb.WriteString(fmt.Sprintf("  %s: %s", k, encoded))
+
+		if n, ok := claims[k].(json.Number); ok && jwtTimeClaims[k] {
+			if secs, err := n.Int64(); err == nil {
+				t := time.Unix(secs, 0)
+				sb.WriteString(fmt.Sprintf(" (%s", t.Local().Format(time.RFC1123)))
+				if k == "exp" {
+					if t.Before(now) {
+						sb.WriteString(", expired")
+					} else {
+						sb.WriteString(fmt.Sprintf(", expires in %v", t.Sub(now).Round(time.Second)))
+					}
+				}
+				sb.WriteString(")")
+			}
+		}
+		sb.WriteString("\n")
+	}
+}
+
+// renderJWTs lists the found tokens by source with the selected one's
+// decoded claims underneath.
+func renderJWTs(tokens []jwtCandidate, cursor int) string {
+	var sb strings.Builder
+	sb.WriteString("JWTs (↑/↓: select • esc: close):\n")
+	for i, c := range tokens {
+		marker := "  "
+		if i == cursor {
+			marker = "▸ "
+		}
+		sb.WriteString(marker + c.Source + "\n")
+	}
+
+	if cursor < 0 || cursor >= len(tokens) {
+		return sb.String()
+	}
+	sb.WriteString("\n")
+	header, payload, err := decodeJWT(tokens[cursor].Token)
+	if err != nil {
+		sb.WriteString(err.Error())
+		return sb.String()
+	}
+	sb.WriteString(formatJWT(header, payload, time.Now()))
+	return sb.String()
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// keyNames are the names Bubble Tea gives non-printable keys, such as
+// "enter", "ctrl+a" and "f5".
+var keyNames = func() map[string]bool {
+	names := map[string]bool{}
+	for k := tea.KeyF20; k <= tea.KeyBackspace; k++ {
+		if name := k.String(); name != "" && k != tea.KeyRunes {
+			names[name] = true
+		}
+	}
+	return names
+}()
+
+// validKeyName reports whether name is a key Bubble Tea can report: a
+// single character or a named key, optionally prefixed with "alt+".
+func validKeyName(name string) bool {
+	name = strings.TrimPrefix(name, "alt+")
+	return utf8.RuneCountInString(name) == 1 || keyNames[name]
+}
+
+// normalizeAction folds an action name so "toggle_history",
+// "toggle-history" and "ToggleHistory" all name keyMap.ToggleHistory.
+func normalizeAction(name string) string {
+	name = strings.NewReplacer("_", "", "-", "", " ", "").Replace(name)
+	return strings.ToLower(name)
+}
+
+// applyKeybindings returns km with the keys in overrides, which maps
+// action names to keys, in place of the defaults. Unknown actions and
+// invalid keys are reported as warnings, and the action keeps its default.
+func applyKeybindings(km keyMap, overrides map[string][]string) (keyMap, []string) {
+	v := reflect.ValueOf(&km).Elem()
+	fields := map[string]int{}
+	for i := 0; i < v.NumField(); i++ {
+		fields[normalizeAction(v.Type().Field(i).Name)] = i
+	}
+
+	actions := make([]string, 0, len(overrides))
+	for action := range overrides {
+		actions = append(actions, action)
+	}
+	sort.Strings(actions)
+
+	var warnings []string
+	for _, action := range actions {
+		i, ok := fields[normalizeAction(action)]
+		if !ok {
+			warnings = append(warnings, fmt.Sprintf("unknown action %q", action))
+			continue
+		}
+		keyList := overrides[action]
+		if len(keyList) == 0 {
+			warnings = append(warnings, fmt.Sprintf("no keys for %q, keeping the default", action))
+			continue
+		}
+		valid := true
+		for _, k := range keyList {
+			if !validKeyName(k) {
+				warnings = append(warnings, fmt.Sprintf("invalid key %q for %q, keeping the default", k, action))
+				valid = false
+			}
+		}
+		if !valid {
+			continue
+		}
+
+		binding := v.Field(i).Interface().(key.Binding)
+		binding.SetKeys(keyList...)
+		binding.SetHelp(strings.Join(keyList, "/"), binding.Help().Desc)
+		v.Field(i).Set(reflect.ValueOf(binding))
+	}
+	return km, warnings
+}
+
+// helpLine lists the main shortcuts for the help screen. It reads each
+// binding's help text, so keys changed in the config are shown as set.
+func (km keyMap) helpLine() string {
+	bindings := []key.Binding{
+		km.Tab, km.ShiftTab, km.Enter, km.ToggleHistory, km.Timeline,
+		km.SavedRequests, km.Connections, km.Review, km.RecordMacro,
+		km.PlayMacro, km.ToggleEnvs, km.Auth, km.Cookies, km.SaveRequest,
+		km.AddBookmark, km.DelBookmark, km.NextBookmark, km.PrevBookmark,
+		km.ShowBookmarks, km.ToggleMeta, km.ShowPaths, km.CopyTimings,
+		km.ExportTimings, km.DecodeJWT, km.EditLastSent, km.CopyCurl,
+		km.SaveResponse, km.CopySummary, km.ShareRequest, km.ImportShare,
+		km.ConvertBody, km.NormalizeURL, km.TrailingSlash, km.NewTab,
+		km.CloseTab, km.NextTab, km.PrevTab, km.Dashboard, km.Palette,
+		km.Quit, km.ToggleHelp,
+	}
+
+	entries := make([]string, 0, len(bindings))
+	for _, b := range bindings {
+		if !b.Enabled() {
+			continue
+		}
+		entries = append(entries, b.Help().Key+": "+b.Help().Desc)
+	}
+	return strings.Join(entries, " • ")
+}
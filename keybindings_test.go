@@ -0,0 +1,24 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHelpLineShowsOverrides(t *testing.T) {
+	km, warnings := applyKeybindings(keys, map[string][]string{"toggle_history": {"ctrl+k"}})
+	if len(warnings) != 0 {
+		t.Fatalf("warnings: %v", warnings)
+	}
+
+	line := km.helpLine()
+	if !strings.Contains(line, "ctrl+k: toggle history") {
+		t.Errorf("help line doesn't show the override: %s", line)
+	}
+	if strings.Contains(line, "ctrl+h: toggle history") {
+		t.Errorf("help line still shows the default: %s", line)
+	}
+	if !strings.Contains(line, "ctrl+t: new tab") {
+		t.Errorf("help line doesn't list the tab keys: %s", line)
+	}
+}
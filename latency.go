@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// latencySamples is how many response times are kept per endpoint.
+const latencySamples = 20
+
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// latencyKey identifies an endpoint in Model.latencies: the method and the
+// URL as sent.
+func latencyKey(r Response) string {
+	return r.Sent.Method + " " + r.Sent.URL
+}
+
+// recordLatency adds r's response time to its endpoint's samples, dropping
+// the oldest once there are latencySamples. Failed requests aren't timed.
+func (m *Model) recordLatency(r Response) {
+	if r.StatusCode == 0 || r.Error != nil || r.Sent.URL == "" {
+		return
+	}
+	if m.latencies == nil {
+		m.latencies = make(map[string][]time.Duration)
+	}
+	k := latencyKey(r)
+	samples := append(m.latencies[k], r.ResponseTime)
+	if len(samples) > latencySamples {
+		samples = samples[len(samples)-latencySamples:]
+	}
+	m.latencies[k] = samples
+}
+
+// latencyRange returns the smallest and largest of samples.
+func latencyRange(samples []time.Duration) (lo, hi time.Duration) {
+	lo, hi = samples[0], samples[0]
+	for _, s := range samples {
+		if s < lo {
+			lo = s
+		}
+		if s > hi {
+			hi = s
+		}
+	}
+	return lo, hi
+}
+
+// sparkline draws samples as block characters scaled between the smallest
+// and largest.
+func sparkline(samples []time.Duration) string {
+	lo, hi := latencyRange(samples)
+	var sb strings.Builder
+	for _, s := range samples {
+		i := len(sparkBlocks) / 2
+		if hi > lo {
+			i = int(int64(s-lo) * int64(len(sparkBlocks)-1) / int64(hi-lo))
+		}
+		sb.WriteRune(sparkBlocks[i])
+	}
+	return sb.String()
+}
+
+// formatLatencyTrend is a line like "Trend: ▁▂▄█ 98ms–1.2s over 4 calls"
+// for the current response's endpoint, or "" before its second call.
+func (m Model) formatLatencyTrend() string {
+	samples := m.latencies[latencyKey(m.response)]
+	if len(samples) < 2 {
+		return ""
+	}
+	lo, hi := latencyRange(samples)
+	return fmt.Sprintf("Trend: %s %v–%v over %d calls\n", sparkline(samples),
+		lo.Round(time.Millisecond), hi.Round(time.Millisecond), len(samples))
+}
@@ -0,0 +1,70 @@
+package main
+
+// panelNames are how panels are stored in Config.Layout.
+var panelNames = map[int]string{
+	urlPanel:       "url",
+	methodPanel:    "method",
+	queryPanel:     "query",
+	headersPanel:   "headers",
+	bodyPanel:      "body",
+	variablesPanel: "variables",
+	responsePanel:  "response",
+}
+
+// LayoutState is the focused panel and the toggled panels of the last
+// session, restored on the next start.
+type LayoutState struct {
+	Panel       string `json:"panel,omitempty"`
+	ShowHelp    bool   `json:"show_help,omitempty"`
+	ShowHistory bool   `json:"show_history,omitempty"`
+	ShowEnvs    bool   `json:"show_envs,omitempty"`
+}
+
+// layoutState captures the layout to save on quit.
+func (m Model) layoutState() LayoutState {
+	return LayoutState{
+		Panel:       panelNames[m.activePanel],
+		ShowHelp:    m.showHelp,
+		ShowHistory: m.showHistory,
+		ShowEnvs:    m.showEnvs,
+	}
+}
+
+func (cm *ConfigManager) saveLayout(l LayoutState) error {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+	if cm.Config.Layout == l {
+		return nil
+	}
+	cm.Config.Layout = l
+	return cm.saveConfigLocked()
+}
+
+// restoreLayout focuses the saved panel and reopens the saved panels.
+// Unknown panels fall back to the method panel and the variables panel to
+// the body outside GraphQL mode; the history and environments panels stay
+// closed when there's nothing to list.
+func (m Model) restoreLayout(l LayoutState) Model {
+	m.activePanel = methodPanel
+	for panel, name := range panelNames {
+		if name == l.Panel {
+			m.activePanel = panel
+		}
+	}
+	if m.activePanel == variablesPanel && !m.graphQL {
+		m.activePanel = bodyPanel
+	}
+	focused, _ := m.updateFocus()
+	m = focused.(Model)
+
+	m.showHelp = l.ShowHelp
+	switch {
+	case l.ShowEnvs:
+		m = m.openEnvs()
+		m.showEnvs = len(m.envNames) > 0
+	case l.ShowHistory:
+		m.refreshHistory()
+		m.showHistory = len(m.historyList.Items()) > 0
+	}
+	return m
+}
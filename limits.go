@@ -0,0 +1,74 @@
+package main
+
+import "fmt"
+
+const (
+	// defaultMaxResponseBytes is used when Config.MaxResponseBytes is
+	// negative, and is the default for new configs.
+	defaultMaxResponseBytes = 10 * 1024 * 1024
+	// maxResponseCeiling bounds "unlimited" (0) and oversized limits:
+	// bodies are held in memory several times over once formatted.
+	maxResponseCeiling = 256 * 1024 * 1024
+)
+
+// maxResponseBytes returns the largest response body that's read, from
+// Config.MaxResponseBytes: 0 means as much as maxResponseCeiling allows,
+// and invalid values fall back to defaultMaxResponseBytes.
+func (cm *ConfigManager) maxResponseBytes() int64 {
+	if cm == nil {
+		return defaultMaxResponseBytes
+	}
+
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+
+	switch limit := cm.Config.MaxResponseBytes; {
+	case limit < 0:
+		return defaultMaxResponseBytes
+	case limit == 0 || limit > maxResponseCeiling:
+		return maxResponseCeiling
+	default:
+		return limit
+	}
+}
+
+// displayLimit returns how many bytes of a body the response panel shows,
+// from Config.TruncateResponse in KB. 0 means the whole body.
+func (cm *ConfigManager) displayLimit() int64 {
+	if cm == nil {
+		return 0
+	}
+
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+
+	if cm.Config.TruncateResponse <= 0 {
+		return 0
+	}
+	return int64(cm.Config.TruncateResponse) * 1024
+}
+
+// truncateBody cuts body to at most limit bytes at a line break, so
+// formatting and highlighting stay intact. A limit of 0 keeps it whole.
+func truncateBody(body string, limit int64) (string, bool) {
+	if limit <= 0 || int64(len(body)) <= limit {
+		return body, false
+	}
+	cut := body[:limit]
+	for i := len(cut) - 1; i > 0; i-- {
+		if cut[i] == '\n' {
+			return cut[:i], true
+		}
+	}
+	return cut, true
+}
+
+// formatLimits describes the size limits in effect, for the response
+// footer.
+func (cm *ConfigManager) formatLimits() string {
+	display := "full body"
+	if limit := cm.displayLimit(); limit > 0 {
+		display = formatBytes(limit)
+	}
+	return fmt.Sprintf("Limits: read up to %s, display %s", formatBytes(cm.maxResponseBytes()), display)
+}
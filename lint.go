@@ -0,0 +1,96 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// jsonLintError is a JSON syntax error located in the body editor.
+type jsonLintError struct {
+	Line   int // 1-based
+	Column int // 1-based, in characters
+	Msg    string
+}
+
+func (e *jsonLintError) Error() string {
+	return fmt.Sprintf("%s (line %d, column %d)", e.Msg, e.Line, e.Column)
+}
+
+// lintJSON checks that body is valid JSON, returning a *jsonLintError with
+// the line and column of the first syntax error.
+func lintJSON(body string) error {
+	var v interface{}
+	err := json.Unmarshal([]byte(body), &v)
+	if err == nil {
+		return nil
+	}
+	var syntaxErr *json.SyntaxError
+	if !errors.As(err, &syntaxErr) {
+		return err
+	}
+
+	// Offset counts the byte that caused the error, so it sits just
+	// before it
+	offset := min(max(int(syntaxErr.Offset)-1, 0), len(body))
+	before := body[:offset]
+	line := strings.Count(before, "\n") + 1
+	column := utf8.RuneCountInString(before[strings.LastIndex(before, "\n")+1:]) + 1
+	return &jsonLintError{Line: line, Column: column, Msg: syntaxErr.Error()}
+}
+
+// lintBody checks the body about to be sent when its Content-Type is JSON.
+// Placeholders that only make sense once substituted, such as an unquoted
+// {{count}}, are allowed: the body only fails when it's invalid after
+// substitution too. Errors are located in the body as written.
+func (m Model) lintBody(reqItem RequestItem) error {
+	if reqItem.GraphQL || strings.TrimSpace(reqItem.Body) == "" {
+		return nil
+	}
+	if _, isFile := bodyFilePath(reqItem.Body); isFile {
+		return nil
+	}
+	if !sendsBody(reqItem.Method, m.configManager.allowGetBody(reqItem.AllowGetBody)) {
+		return nil
+	}
+	contentType := ""
+	for k, v := range reqItem.Headers {
+		if strings.EqualFold(k, "Content-Type") {
+			contentType = v
+		}
+	}
+	if !isJSONContentType(m.resolveVars(contentType)) {
+		return nil
+	}
+	err := lintJSON(reqItem.Body)
+	if err == nil || lintJSON(m.resolveForSend(reqItem.Body)) == nil {
+		return nil
+	}
+	return err
+}
+
+// moveBodyCursor puts the body editor's cursor on the given 1-based line
+// and column.
+func (m *Model) moveBodyCursor(line, column int) {
+	for i := 0; i < 10000 && m.bodyInput.Line() > line-1; i++ {
+		m.bodyInput.CursorUp()
+	}
+	for i := 0; i < 10000 && m.bodyInput.Line() < line-1; i++ {
+		m.bodyInput.CursorDown()
+	}
+	m.bodyInput.SetCursor(column - 1)
+}
+
+// showLintError focuses the body editor on a JSON syntax error.
+func (m Model) showLintError(err error) (tea.Model, tea.Cmd) {
+	var lintErr *jsonLintError
+	if errors.As(err, &lintErr) {
+		m.moveBodyCursor(lintErr.Line, lintErr.Column)
+	}
+	m.activePanel = bodyPanel
+	return m.updateFocus()
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const macrosFile = "macros.json"
+
+// MacroStep is one recorded action: either a named key such as "enter" or
+// "ctrl+s", or literal text typed one rune at a time. Text may contain
+// {{VAR}} placeholders that are resolved from the active environment on
+// replay, so secrets don't have to be stored in the macro.
+type MacroStep struct {
+	Key  string `json:"key,omitempty"`
+	Text string `json:"text,omitempty"`
+}
+
+// keyTypesByName maps bubbletea key names ("enter", "ctrl+s", ...) back to
+// their key types for replay.
+var keyTypesByName = func() map[string]tea.KeyType {
+	names := make(map[string]tea.KeyType)
+	for t := tea.KeyType(-128); t < 128; t++ {
+		if name := t.String(); name != "" && t != tea.KeyRunes {
+			names[name] = t
+		}
+	}
+	return names
+}()
+
+// recordKey appends msg to steps, merging plain typed characters into the
+// previous text step.
+func recordKey(steps []MacroStep, msg tea.KeyMsg) []MacroStep {
+	text := ""
+	switch {
+	case msg.Type == tea.KeyRunes && !msg.Alt && !msg.Paste:
+		text = string(msg.Runes)
+	case msg.Type == tea.KeySpace && !msg.Alt:
+		text = " "
+	}
+
+	if text != "" {
+		if n := len(steps); n > 0 && steps[n-1].Key == "" {
+			steps[n-1].Text += text
+			return steps
+		}
+		return append(steps, MacroStep{Text: text})
+	}
+	return append(steps, MacroStep{Key: msg.String()})
+}
+
+// macroKeyMsgs turns steps back into key messages. resolve expands
+// placeholders in text steps.
+func macroKeyMsgs(steps []MacroStep, resolve func(string) string) []tea.KeyMsg {
+	var msgs []tea.KeyMsg
+	for _, step := range steps {
+		if step.Key == "" {
+			for _, r := range resolve(step.Text) {
+				if r == ' ' {
+					msgs = append(msgs, tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}})
+					continue
+				}
+				msgs = append(msgs, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+			}
+			continue
+		}
+
+		name, alt := step.Key, false
+		if len(name) > 4 && name[:4] == "alt+" {
+			name, alt = name[4:], true
+		}
+		if t, ok := keyTypesByName[name]; ok {
+			msgs = append(msgs, tea.KeyMsg{Type: t, Alt: alt})
+		} else {
+			msgs = append(msgs, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(name), Alt: alt})
+		}
+	}
+	return msgs
+}
+
+// replayMacro re-injects the key messages in order.
+func replayMacro(msgs []tea.KeyMsg) tea.Cmd {
+	cmds := make([]tea.Cmd, len(msgs))
+	for i, msg := range msgs {
+		msg := msg
+		cmds[i] = func() tea.Msg { return msg }
+	}
+	return tea.Sequence(cmds...)
+}
+
+func (cm *ConfigManager) loadMacros() (map[string][]MacroStep, error) {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+
+	data, err := os.ReadFile(filepath.Join(cm.configDir, macrosFile))
+	if os.IsNotExist(err) {
+		return map[string][]MacroStep{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	macros := make(map[string][]MacroStep)
+	if err := json.Unmarshal(data, &macros); err != nil {
+		return nil, err
+	}
+	return macros, nil
+}
+
+// saveMacro stores steps under name, replacing values of the active
+// environment's variables in text steps with their placeholders.
+func (cm *ConfigManager) saveMacro(name string, steps []MacroStep) error {
+	macros, err := cm.loadMacros()
+	if err != nil {
+		return err
+	}
+
+	redact := secretRedactor(cm.getCurrentEnvironment().Variables)
+	saved := make([]MacroStep, len(steps))
+	for i, step := range steps {
+		saved[i] = MacroStep{Key: step.Key, Text: redact(step.Text)}
+	}
+	macros[name] = saved
+
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+	return cm.saveJSONLocked(macrosFile, macros)
+}
+
+func (cm *ConfigManager) macroNames() []string {
+	macros, err := cm.loadMacros()
+	if err != nil {
+		return nil
+	}
+	names := make([]string, 0, len(macros))
+	for name := range macros {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -3,26 +3,32 @@ package main
 import (
 	"bytes"
 	"context"
-	"encoding/json"
+	"crypto/tls"
 	"errors"
+	"flag"
 	"fmt"
 
 	"io"
 	"net/http"
 	"os"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 	"unicode/utf8"
 
 	"golang.org/x/text/encoding/htmlindex"
 	"golang.org/x/text/transform"
+	"github.com/atotto/clipboard"
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
 	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbles/textarea"
 	"github.com/charmbracelet/bubbles/textinput"
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
 )
 
 const (
@@ -31,6 +37,45 @@ const (
 	headersPanel
 	bodyPanel
 	responsePanel
+	queryPanel
+	variablesPanel
+)
+
+const (
+	promptNone = iota
+	promptBookmarkNote
+	promptImportShare
+	promptReplayOverride
+	promptDashboardCollection
+	promptSaveCollection
+	promptPathParam
+	promptMacroName
+	promptPlayMacro
+	promptAuthToken
+	promptAuthUsername
+	promptAuthPassword
+	promptSearch
+	promptExtract
+	promptRepeatCount
+	promptRepeatConcurrency
+	promptNewCollection
+	promptRenameCollection
+	promptSaveName
+	promptCopyHeader
+	promptNewEnv
+	promptSetEnvVar
+	promptSchemaPath
+)
+
+const (
+	confirmNone = iota
+	confirmProtectedSend
+	confirmRestoreRecovery
+	confirmSaveSuccess
+	confirmDeleteCollection
+	confirmInvalidJSON
+	confirmDeleteEnv
+	confirmDeleteEnvVar
 )
 
 var httpMethods = []string{
@@ -43,45 +88,6 @@ var httpMethods = []string{
 	"OPTIONS",
 }
 
-var (
-	primaryColor = lipgloss.Color("#4ECDC4")   // Teal
-	accentColor  = lipgloss.Color("#FF6B6B")    // Red
-	mutedColor   = lipgloss.Color("#999999")     // Gray
-	whiteColor   = lipgloss.Color("#FFFFFF")
-
-	baseStyle = lipgloss.NewStyle().
-			BorderStyle(lipgloss.RoundedBorder())
-
-	focusedStyle = baseStyle.
-			BorderForeground(accentColor)
-
-	blurredStyle = baseStyle.
-			BorderForeground(mutedColor)
-
-	helpStyle = lipgloss.NewStyle().
-			Foreground(mutedColor)
-
-	methodPanelStyle = baseStyle.
-				BorderForeground(primaryColor).
-				Padding(1)
-
-	errorStyle = lipgloss.NewStyle().
-			Foreground(accentColor)
-
-	statusSuccessStyle = lipgloss.NewStyle().
-			Foreground(primaryColor)
-
-	statusErrorStyle = lipgloss.NewStyle().
-			Foreground(accentColor)
-
-	statusStyle = lipgloss.NewStyle() 
-	headerStyle = lipgloss.NewStyle().
-			Bold(true).
-			Foreground(whiteColor).
-			Background(primaryColor).
-			Padding(0, 1)
-)
-
 type keyMap struct {
 	Up            key.Binding
 	Down          key.Binding
@@ -95,6 +101,71 @@ type keyMap struct {
 	ToggleHistory key.Binding
 	ToggleEnvs    key.Binding
 	SaveRequest   key.Binding
+	Cancel        key.Binding
+	AddBookmark   key.Binding
+	DelBookmark   key.Binding
+	NextBookmark  key.Binding
+	PrevBookmark  key.Binding
+	ShowBookmarks key.Binding
+	ShareRequest  key.Binding
+	ImportShare   key.Binding
+	ToggleMeta    key.Binding
+	RawView       key.Binding
+	FoldHeaders   key.Binding
+	DiffResponse  key.Binding
+	ConvertBody   key.Binding
+	LintBody      key.Binding
+	CopyAsFetch   key.Binding
+	DryRun        key.Binding
+	Palette       key.Binding
+	SchemaPath    key.Binding
+	ShowPaths     key.Binding
+	Replay        key.Binding
+	ReplayEdit    key.Binding
+	LoadAndSend   key.Binding
+	SaveResponse  key.Binding
+	Cookies       key.Binding
+	Repeat        key.Binding
+	GraphQL       key.Binding
+	Collections   key.Binding
+	NewCollection key.Binding
+	NewEnv        key.Binding
+	AddVariable   key.Binding
+	RenameItem    key.Binding
+	DeleteItem    key.Binding
+	RevealSecrets key.Binding
+	ClearCookies  key.Binding
+	NormalizeURL  key.Binding
+	Dashboard     key.Binding
+	Pause         key.Binding
+	CopyTimings   key.Binding
+	ExportTimings key.Binding
+	TrailingSlash key.Binding
+	DecodeJWT     key.Binding
+	Timeline      key.Binding
+	EditLastSent  key.Binding
+	SavedRequests key.Binding
+	CopyCurl      key.Binding
+	CopySummary   key.Binding
+	CopyBody      key.Binding
+	CopyHeader    key.Binding
+	Connections   key.Binding
+	RecordMacro   key.Binding
+	Review        key.Binding
+	PlayMacro     key.Binding
+	CloseIdle     key.Binding
+	ShowAll       key.Binding
+	Auth          key.Binding
+	Search        key.Binding
+	NextMatch     key.Binding
+	PrevMatch     key.Binding
+	Extract       key.Binding
+	NewTab        key.Binding
+	CloseTab      key.Binding
+	NextTab       key.Binding
+	PrevTab       key.Binding
+	ToggleTheme   key.Binding
+	SendMessage   key.Binding
 }
 
 var keys = keyMap{
@@ -146,40 +217,460 @@ var keys = keyMap{
 		key.WithKeys("ctrl+s"),
 		key.WithHelp("ctrl+s", "save request"),
 	),
+	Cancel: key.NewBinding(
+		key.WithKeys("esc"),
+		key.WithHelp("esc", "cancel"),
+	),
+	AddBookmark: key.NewBinding(
+		key.WithKeys("m"),
+		key.WithHelp("m", "bookmark line"),
+	),
+	DelBookmark: key.NewBinding(
+		key.WithKeys("M"),
+		key.WithHelp("M", "remove bookmark"),
+	),
+	NextBookmark: key.NewBinding(
+		key.WithKeys("]"),
+		key.WithHelp("]", "next bookmark"),
+	),
+	PrevBookmark: key.NewBinding(
+		key.WithKeys("["),
+		key.WithHelp("[", "previous bookmark"),
+	),
+	ShowBookmarks: key.NewBinding(
+		key.WithKeys("b"),
+		key.WithHelp("b", "toggle bookmarks"),
+	),
+	ShareRequest: key.NewBinding(
+		key.WithKeys("ctrl+g"),
+		key.WithHelp("ctrl+g", "copy share link"),
+	),
+	ImportShare: key.NewBinding(
+		key.WithKeys("ctrl+o"),
+		key.WithHelp("ctrl+o", "import share link"),
+	),
+	ToggleMeta: key.NewBinding(
+		key.WithKeys("i"),
+		key.WithHelp("i", "toggle metadata"),
+	),
+	RawView: key.NewBinding(
+		key.WithKeys("u"),
+		key.WithHelp("u", "toggle raw view"),
+	),
+	FoldHeaders: key.NewBinding(
+		key.WithKeys("f"),
+		key.WithHelp("f", "fold/unfold headers"),
+	),
+	DiffResponse: key.NewBinding(
+		key.WithKeys("D"),
+		key.WithHelp("D", "diff against previous response"),
+	),
+	ConvertBody: key.NewBinding(
+		key.WithKeys("ctrl+f"),
+		key.WithHelp("ctrl+f", "toggle JSON/form body"),
+	),
+	LintBody: key.NewBinding(
+		key.WithKeys("ctrl+l"),
+		key.WithHelp("ctrl+l", "check body is valid JSON"),
+	),
+	CopyAsFetch: key.NewBinding(
+		key.WithKeys("alt+j"),
+		key.WithHelp("alt+j", "show request as fetch()"),
+	),
+	DryRun: key.NewBinding(
+		key.WithKeys("ctrl+d"),
+		key.WithHelp("ctrl+d", "dry run: show the resolved request"),
+	),
+	Palette: key.NewBinding(
+		key.WithKeys("ctrl+p"),
+		key.WithHelp("ctrl+p", "command palette"),
+	),
+	SchemaPath: key.NewBinding(
+		key.WithKeys("alt+s"),
+		key.WithHelp("alt+s", "set response JSON Schema"),
+	),
+	ShowPaths: key.NewBinding(
+		key.WithKeys("p"),
+		key.WithHelp("p", "JSON paths"),
+	),
+	Replay: key.NewBinding(
+		key.WithKeys("r"),
+		key.WithHelp("r", "replay"),
+	),
+	RevealSecrets: key.NewBinding(
+		key.WithKeys("v"),
+		key.WithHelp("v", "reveal secrets"),
+	),
+	Cookies: key.NewBinding(
+		key.WithKeys("f6"),
+		key.WithHelp("f6", "cookies"),
+	),
+	Repeat: key.NewBinding(
+		key.WithKeys("f7"),
+		key.WithHelp("f7", "repeat request"),
+	),
+	GraphQL: key.NewBinding(
+		key.WithKeys("f8"),
+		key.WithHelp("f8", "toggle GraphQL mode"),
+	),
+	ClearCookies: key.NewBinding(
+		key.WithKeys("x"),
+		key.WithHelp("x", "clear cookies"),
+	),
+	SaveResponse: key.NewBinding(
+		key.WithKeys("w"),
+		key.WithHelp("w", "save response to file"),
+	),
+	Search: key.NewBinding(
+		key.WithKeys("/"),
+		key.WithHelp("/", "search response"),
+	),
+	NextMatch: key.NewBinding(
+		key.WithKeys("n"),
+		key.WithHelp("n", "next match"),
+	),
+	PrevMatch: key.NewBinding(
+		key.WithKeys("N"),
+		key.WithHelp("N", "previous match"),
+	),
+	NewTab: key.NewBinding(
+		key.WithKeys("ctrl+t"),
+		key.WithHelp("ctrl+t", "new tab"),
+	),
+	CloseTab: key.NewBinding(
+		key.WithKeys("ctrl+w"),
+		key.WithHelp("ctrl+w", "close tab"),
+	),
+	NextTab: key.NewBinding(
+		key.WithKeys("alt+n", "ctrl+pgdown"),
+		key.WithHelp("alt+n", "next tab"),
+	),
+	PrevTab: key.NewBinding(
+		key.WithKeys("alt+p", "ctrl+pgup"),
+		key.WithHelp("alt+p", "previous tab"),
+	),
+	ToggleTheme: key.NewBinding(
+		key.WithKeys("f10"),
+		key.WithHelp("f10", "switch theme"),
+	),
+	SendMessage: key.NewBinding(
+		key.WithKeys("alt+enter"),
+		key.WithHelp("alt+enter", "send WebSocket message"),
+	),
+	Extract: key.NewBinding(
+		key.WithKeys("x"),
+		key.WithHelp("x", "extract JSON value"),
+	),
+	LoadAndSend: key.NewBinding(
+		key.WithKeys("R"),
+		key.WithHelp("R", "load and send"),
+	),
+	ReplayEdit: key.NewBinding(
+		key.WithKeys("e"),
+		key.WithHelp("e", "override and replay"),
+	),
+	NormalizeURL: key.NewBinding(
+		key.WithKeys("ctrl+n"),
+		key.WithHelp("ctrl+n", "normalize URL"),
+	),
+	Dashboard: key.NewBinding(
+		key.WithKeys("ctrl+b"),
+		key.WithHelp("ctrl+b", "health dashboard"),
+	),
+	Pause: key.NewBinding(
+		key.WithKeys("p", " "),
+		key.WithHelp("p", "pause/resume"),
+	),
+	CopyTimings: key.NewBinding(
+		key.WithKeys("t"),
+		key.WithHelp("t", "copy timing breakdown"),
+	),
+	ExportTimings: key.NewBinding(
+		key.WithKeys("T"),
+		key.WithHelp("T", "copy timing breakdown as JSON"),
+	),
+	TrailingSlash: key.NewBinding(
+		key.WithKeys("alt+t"),
+		key.WithHelp("alt+t", "cycle trailing slash"),
+	),
+	DecodeJWT: key.NewBinding(
+		key.WithKeys("J"),
+		key.WithHelp("J", "decode JWTs"),
+	),
+	Timeline: key.NewBinding(
+		key.WithKeys("ctrl+r"),
+		key.WithHelp("ctrl+r", "session timeline"),
+	),
+	EditLastSent: key.NewBinding(
+		key.WithKeys("E"),
+		key.WithHelp("E", "edit last sent request"),
+	),
+	Review: key.NewBinding(
+		key.WithKeys("f4"),
+		key.WithHelp("f4", "side-by-side review"),
+	),
+	Auth: key.NewBinding(
+		key.WithKeys("f5"),
+		key.WithHelp("f5", "auth helper"),
+	),
+	RecordMacro: key.NewBinding(
+		key.WithKeys("f2"),
+		key.WithHelp("f2", "start/stop macro recording"),
+	),
+	PlayMacro: key.NewBinding(
+		key.WithKeys("f3"),
+		key.WithHelp("f3", "play macro"),
+	),
+	Connections: key.NewBinding(
+		key.WithKeys("ctrl+y"),
+		key.WithHelp("ctrl+y", "connection stats"),
+	),
+	CloseIdle: key.NewBinding(
+		key.WithKeys("x"),
+		key.WithHelp("x", "close idle connections"),
+	),
+	CopySummary: key.NewBinding(
+		key.WithKeys("s"),
+		key.WithHelp("s", "copy status summary"),
+	),
+	CopyCurl: key.NewBinding(
+		key.WithKeys("c"),
+		key.WithHelp("c", "copy sent request as curl"),
+	),
+	CopyBody: key.NewBinding(
+		key.WithKeys("y"),
+		key.WithHelp("y", "copy response body"),
+	),
+	CopyHeader: key.NewBinding(
+		key.WithKeys("Y"),
+		key.WithHelp("Y", "copy a response header"),
+	),
+	SavedRequests: key.NewBinding(
+		key.WithKeys("ctrl+x"),
+		key.WithHelp("ctrl+x", "saved requests"),
+	),
+	ShowAll: key.NewBinding(
+		key.WithKeys("a"),
+		key.WithHelp("a", "toggle environment filter"),
+	),
+	Collections: key.NewBinding(
+		key.WithKeys("f9"),
+		key.WithHelp("f9", "collections"),
+	),
+	NewCollection: key.NewBinding(
+		key.WithKeys("n"),
+		key.WithHelp("n", "new collection"),
+	),
+	NewEnv: key.NewBinding(
+		key.WithKeys("n"),
+		key.WithHelp("n", "new environment"),
+	),
+	AddVariable: key.NewBinding(
+		key.WithKeys("a"),
+		key.WithHelp("a", "add variable"),
+	),
+	RenameItem: key.NewBinding(
+		key.WithKeys("r"),
+		key.WithHelp("r", "rename"),
+	),
+	DeleteItem: key.NewBinding(
+		key.WithKeys("d"),
+		key.WithHelp("d", "delete"),
+	),
 }
 
 type Response struct {
-	StatusCode    int
-	Status        string
-	Headers       http.Header
-	Body          string
-	FormattedBody string
-	ResponseTime  time.Duration
-	Error         error
-	ContentLength int64
+	StatusCode      int
+	Status          string
+	Headers         http.Header
+	Body            string
+	FormattedBody   string
+	// JSONFormatted is set when FormattedBody is pretty-printed JSON.
+	JSONFormatted   bool
+	// Binary is set for image and other non-text bodies. FormattedBody
+	// describes them rather than showing the bytes.
+	Binary          bool
+	ResponseTime    time.Duration
+	Error           error
+	ContentLength   int64
+	// WireSize is the number of body bytes received before decompression,
+	// or -1 when the transport decompressed the body itself.
+	WireSize        int64
+	DecodedSize     int64
+	// Truncated is set when the body was cut short at max_response_bytes.
+	Truncated       bool
+	ContentEncoding string
+	Proto           string
+	TLS             *tls.ConnectionState
+	Timings         Timings
+	Informational   []InformationalResponse
+	Redirects       []RedirectHop
+	// Insecure is set when TLS certificates weren't verified.
+	Insecure        bool
+	Summary         string
+	// Schema is the result of validating the body against the request's
+	// JSON Schema, nil when it has none.
+	Schema          *SchemaResult
+	// Request is the request that produced this response, when known.
+	Request         RequestItem
+	// Sent is the request as it went out. Its Method tells formatResponse
+	// to leave out the body section of HEAD responses.
+	Sent            SentRequest
+	// Events are the server-sent events of a text/event-stream response,
+	// and StreamEnd how the stream finished: "ended", "stopped" or
+	// "interrupted: <error>".
+	Events          []sseEvent
+	StreamEnd       string
 }
 
 type Model struct {
-	urlInput      textinput.Model
-	methodList    list.Model
-	headersInput  textinput.Model
-	bodyInput     textinput.Model
-	responseView  viewport.Model
-	spinner       spinner.Model
-	activePanel   int
-	response      Response
-	loading       bool
-	width         int
-	height        int
-	showHelp      bool
-	showHistory   bool
-	showEnvs      bool
-	lastBody      string
-	configManager *ConfigManager
-	requestError  error
-}
-
-func initialModel() Model {
+	urlInput        textinput.Model
+	methodList      list.Model
+	queryInput      textarea.Model
+	headersInput    textarea.Model
+	bodyInput       textarea.Model
+	responseView    viewport.Model
+	spinner         spinner.Model
+	// styles are built from the configured theme by applyTheme.
+	styles          styles
+	activePanel     int
+	response        Response
+	loading         bool
+	width           int
+	height          int
+	showHelp        bool
+	showHistory     bool
+	showEnvs        bool
+	showAuth        bool
+	authCursor      int
+	auth            *AuthConfig
+	pendingAuth     AuthConfig
+	envNames        []string
+	envCursor       int
+	// envVarFocus moves the cursor to the variables of the environment
+	// under envCursor. envVarEdit is the variable being edited or deleted,
+	// "" when adding one.
+	envVarFocus     bool
+	envVarCursor    int
+	envVarEdit      string
+	revealSecrets   bool
+	lastBody        string
+	configManager   *ConfigManager
+	requestError    error
+	prompt          textinput.Model
+	promptKind      int
+	bookmarks       []Bookmark
+	showBookmarks   bool
+	searchQuery     string
+	searchMatches   []searchMatch
+	searchIndex     int
+	extractPath     string
+	// lastJSON is the body of the last response, decoded for
+	// {{response.*}} placeholders. It's nil when that body wasn't JSON.
+	lastJSON        interface{}
+	// harRecords are the session's completed requests, written to a HAR
+	// file on quit when record_har is set.
+	harRecords      []harRecord
+	// quitErr is a failure while quitting, such as the HAR file not being
+	// written, reported by main once the screen is restored.
+	quitErr         error
+	// repeatCount is the count of the current or last repeat run, and
+	// repeatReport its summary, shown in the response panel until the
+	// next response.
+	repeatCount     int
+	repeating       bool
+	repeatReport    string
+	// previewView is shown in the response panel instead of the response
+	// until esc or the next one: the editor request as a fetch() call, or
+	// a dry run.
+	previewView     string
+	// latencies are the recent response times of each endpoint, see
+	// recordLatency.
+	latencies       map[string][]time.Duration
+	// graphQL sends the body as a GraphQL query with variablesInput as its
+	// variables, see graphQLBody.
+	graphQL         bool
+	variablesInput  textarea.Model
+	// displayBody is the response body as shown, see renderBody.
+	// responseOffsets are the byte offsets of the response view's lines,
+	// for the scroll position in its title.
+	displayBody     string
+	responseOffsets []int
+	// ws is the open WebSocket connection, if any, and wsFrames the log
+	// of the current or last one, shown in place of the response.
+	ws              *wsSession
+	wsFrames        []wsFrame
+	// stream is the event stream being received, see sseStream.
+	stream          *sseStream
+	// cancelRequest aborts the request in flight, if any.
+	cancelRequest   context.CancelFunc
+	statusMessage   string
+	showMetadata    bool
+	// showRaw shows the exchange unformatted, see formatRawExchange.
+	showRaw         bool
+	// foldHeaders collapses the response headers to a one-line count.
+	foldHeaders     bool
+	// prevResponse is the response before the current one, and showDiff
+	// shows the current body as a diff against it, see formatDiff.
+	prevResponse    Response
+	showDiff        bool
+	requestTimeout  int
+	trailingSlash   string
+	summaryTemplate string
+	schemaPath      string
+	allowGetBody    bool
+	timeline        []timelineEntry
+	timelineList    list.Model
+	showTimeline    bool
+	lastSent        RequestItem
+	tags            []string
+	savedList       list.Model
+	showSaved       bool
+	// collectionList lists the collections, or the requests in
+	// openCollection once one is opened. collectionEdit is the collection
+	// being renamed or deleted.
+	collectionList  list.Model
+	showCollections bool
+	openCollection  string
+	collectionEdit  string
+	savedShowAll    bool
+	// pendingSave is the request being saved, to saveCollection once it
+	// has a name. saveCollection is kept as the default for the next save.
+	pendingSave     RequestItem
+	saveCollection  string
+	pathParams      map[string]string
+	pendingParams   []string
+	showConns       bool
+	showCookies     bool
+	cookies         *sessionJar
+	recording       bool
+	macroSteps      []MacroStep
+	handlerAttempts int
+	reviewMode      bool
+	reviewLeft      bool
+	requestView     viewport.Model
+	pathList        list.Model
+	showPaths       bool
+	confirmKind     int
+	confirmText     string
+	autoFocused     bool
+	returnPanel     int
+	historyList     list.Model
+	paletteList     list.Model
+	showPalette     bool
+	replayItem      RequestItem
+	showDashboard   bool
+	dashboard       dashboardState
+	recovery        RecoveryState
+	lastAutosave    RecoveryState
+	jwts            []jwtCandidate
+	jwtCursor       int
+	showJWTs        bool
+	tabs            []requestTab
+	activeTab       int
+}
+
+func initialModel(configDir string) Model {
 	urlInput := textinput.New()
 	urlInput.Placeholder = "https://api.example.com/endpoint"
 	urlInput.Width = 50
@@ -189,63 +680,92 @@ func initialModel() Model {
 	for i, method := range httpMethods {
 		methodItems[i] = item{title: method}
 	}
-	methodDelegate := list.NewDefaultDelegate()
-	methodDelegate.ShowDescription = false
-	methodDelegate.SetSpacing(1)
-	methodDelegate.Styles.SelectedTitle = methodDelegate.Styles.SelectedTitle.
-		Foreground(primaryColor).
-		Bold(true)
-
-	methodList := list.New(methodItems, methodDelegate, 35, 8)
+	methodList := list.New(methodItems, list.NewDefaultDelegate(), 35, 8)
 	methodList.Title = "HTTP Methods"
-	methodList.Styles.Title = methodList.Styles.Title.
-		Foreground(primaryColor).
-		Bold(true).
-		MarginLeft(1)
 	methodList.SetShowTitle(true)
 	methodList.SetFilteringEnabled(false)
-	methodList.Styles.NoItems = methodList.Styles.NoItems.
-		Foreground(accentColor)
 	methodList.Select(0) // Select GET by default
 
-	headersInput := textinput.New()
-	headersInput.Placeholder = "Content-Type: application/json\nAuthorization: Bearer token"
-	headersInput.Width = 50
-	headersInput.CharLimit = 0
-	headersInput.SetValue("")
-
-	bodyInput := textinput.New()
-	bodyInput.Placeholder = "{\n  \"key\": \"value\"\n}"
-	bodyInput.Width = 50
-	bodyInput.CharLimit = 0
-	bodyInput.SetValue("")
+	queryInput := newEditor("page=1\nsearch=hello world")
+	headersInput := newEditor("Content-Type: application/json\nAuthorization: Bearer token")
+	bodyInput := newEditor("{\n  \"key\": \"value\"\n}")
+	variablesInput := newEditor("{\n  \"id\": 1\n}")
 
 	responseView := viewport.New(0, 0)
-	responseView.Style = blurredStyle
+
+	prompt := textinput.New()
+	prompt.Width = 50
+
+	pathList := newPanelList("JSON Paths (enter: copy path, esc: close)")
+	timelineList := newPanelList("Session Timeline (enter: restore response, esc: close)")
+	savedList := newPanelList("Saved Requests")
+	collectionList := newPanelList("Collections")
+	historyList := newPanelList("History")
+	paletteList := newPanelList("Commands")
 
 	s := spinner.New()
 	s.Spinner = spinner.Dot
-	s.Style = lipgloss.NewStyle().Foreground(accentColor)
 
-	configManager, err := NewConfigManager()
+	configManager, err := NewConfigManager(configDir)
 	if err != nil {
-		fmt.Printf("Error initializing config: %v\n", err)
+		fmt.Fprintf(os.Stderr, "Error initializing config: %v\n", err)
+	}
+
+	m := Model{
+		tabs:           []requestTab{{}},
+		urlInput:       urlInput,
+		methodList:     methodList,
+		queryInput:     queryInput,
+		headersInput:   headersInput,
+		bodyInput:      bodyInput,
+		variablesInput: variablesInput,
+		responseView:   responseView,
+		spinner:        s,
+		activePanel:    methodPanel, // Start with method panel active
+		showHelp:       false,
+		showHistory:    false,
+		showEnvs:       false,
+		lastBody:       bodyInput.Value(),
+		configManager:  configManager,
+		prompt:         prompt,
+		showMetadata:   true,
+		pathList:       pathList,
+		timelineList:   timelineList,
+		savedList:      savedList,
+		collectionList: collectionList,
+		historyList:    historyList,
+		paletteList:    paletteList,
+		requestView:    viewport.New(0, 0),
+		cookies:        newSessionJar(),
+	}
+
+	// Builds m.styles and styles the widgets above
+	theme := defaultTheme
+	if configManager != nil {
+		theme = configManager.Config.Theme
 	}
+	warnings := m.applyTheme(theme)
+
+	if configManager != nil {
+		var keyWarnings []string
+		keys, keyWarnings = applyKeybindings(keys, configManager.Config.Keybindings)
+		warnings = append(warnings, keyWarnings...)
+		if len(warnings) > 0 {
+			m.statusMessage = "Config: " + strings.Join(warnings, "; ")
+		}
 
-	return Model{
-		urlInput:      urlInput,
-		methodList:    methodList,
-		headersInput:  headersInput,
-		bodyInput:     bodyInput,
-		responseView:  responseView,
-		spinner:       s,
-		activePanel:   methodPanel, // Start with method panel active
-		showHelp:      false,
-		showHistory:   false,
-		showEnvs:      false,
-		lastBody:      bodyInput.Value(),
-		configManager: configManager,
+		if state, ok := configManager.loadRecovery(); ok {
+			m.recovery = state
+			m.confirmKind = confirmRestoreRecovery
+			m.confirmText = fmt.Sprintf("Found an unsaved request from %s (%s %s). Restore it?",
+				state.SavedAt.Format("Jan 2 15:04"), state.Method, state.URL)
+		} else if draft, ok := configManager.loadDraft(); ok {
+			m.restoreRecovery(draft)
+		}
+		m = m.restoreLayout(configManager.Config.Layout)
 	}
+
+	return m
 }
 
 type item struct {
@@ -291,22 +811,125 @@ func truncateString(s string, n int) string {
 }
 
 func (m Model) Init() tea.Cmd {
-	return textinput.Blink
+	return tea.Batch(textinput.Blink, autosaveTick())
 }
 
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmd tea.Cmd
 	var cmds []tea.Cmd
 
-	m.requestError = nil
-
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		m.requestError = nil
+		m.statusMessage = ""
+
+		if key.Matches(msg, keys.RecordMacro) && m.promptKind == promptNone && m.confirmKind == confirmNone {
+			return m.toggleRecording()
+		}
+		if m.recording {
+			m.macroSteps = recordKey(m.macroSteps, msg)
+		}
+
+		if m.confirmKind != confirmNone {
+			return m.updateConfirm(msg)
+		}
+
+		if m.promptKind != promptNone {
+			return m.updatePrompt(msg)
+		}
+
+		if m.showPalette {
+			return m.updatePalette(msg)
+		}
+
+		if m.showPaths {
+			return m.updatePaths(msg)
+		}
+
+		if m.showTimeline {
+			return m.updateTimeline(msg)
+		}
+
+		if m.showSaved {
+			return m.updateSaved(msg)
+		}
+
+		if m.showCollections {
+			return m.updateCollections(msg)
+		}
+
+		if m.showConns {
+			return m.updateConns(msg)
+		}
+
+		if m.showCookies {
+			return m.updateCookies(msg)
+		}
+
+		if m.reviewMode {
+			return m.updateReview(msg)
+		}
+
+		if m.showHistory {
+			return m.updateHistory(msg)
+		}
+
+		if m.showEnvs {
+			return m.updateEnvs(msg)
+		}
+
+		if m.showAuth {
+			return m.updateAuth(msg)
+		}
+
+		if m.showJWTs {
+			return m.updateJWTs(msg)
+		}
+
+		if m.showDashboard {
+			return m.updateDashboard(msg)
+		}
+
+		if m.loading && m.cancelRequest != nil && key.Matches(msg, keys.Cancel) {
+			m.cancelRequest()
+			m.cancelRequest = nil
+			m.statusMessage = "Cancelling request..."
+			return m, nil
+		}
+
 		switch {
 		case key.Matches(msg, keys.Quit):
-			return m, tea.Quit
+			return m.quit()
+
+		case key.Matches(msg, keys.ToggleTheme):
+			theme := nextTheme(defaultTheme)
+			if m.configManager != nil {
+				theme = nextTheme(m.configManager.Config.Theme)
+				m.configManager.Config.Theme = theme
+				if err := m.configManager.saveConfig(); err != nil {
+					m.requestError = fmt.Errorf("failed to save theme: %w", err)
+				}
+			}
+			m.statusMessage = "Theme: " + theme
+			if warnings := m.applyTheme(theme); len(warnings) > 0 {
+				m.statusMessage += " (" + strings.Join(warnings, "; ") + ")"
+			}
+			return m, nil
+
+		case key.Matches(msg, keys.NewTab):
+			return m.newTab()
+
+		case key.Matches(msg, keys.CloseTab):
+			return m.closeTab()
+
+		case key.Matches(msg, keys.NextTab):
+			return m.switchTab(m.activeTab + 1)
+
+		case key.Matches(msg, keys.PrevTab):
+			return m.switchTab(m.activeTab - 1)
 
 		case key.Matches(msg, keys.Tab):
+			m.autoFocused = false
 			if m.activePanel == methodPanel {
 				m.activePanel = urlPanel
 				return m.updateFocus()
@@ -314,35 +937,54 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 			switch m.activePanel {
 			case urlPanel:
+				m.activePanel = queryPanel
+			case queryPanel:
 				m.activePanel = headersPanel
 			case headersPanel:
 				m.activePanel = bodyPanel
 			case bodyPanel:
 				m.activePanel = responsePanel
+				if m.graphQL {
+					m.activePanel = variablesPanel
+				}
+			case variablesPanel:
+				m.activePanel = responsePanel
 			default:
 				m.activePanel = methodPanel
 			}
 			return m.updateFocus()
 
 		case key.Matches(msg, keys.ShiftTab):
+			m.autoFocused = false
 			switch m.activePanel {
 			case methodPanel:
 				m.activePanel = responsePanel
 			case urlPanel:
 				m.activePanel = methodPanel
-			case headersPanel:
+			case queryPanel:
 				m.activePanel = urlPanel
+			case headersPanel:
+				m.activePanel = queryPanel
 			case bodyPanel:
 				m.activePanel = headersPanel
+			case variablesPanel:
+				m.activePanel = bodyPanel
 			case responsePanel:
 				m.activePanel = bodyPanel
+				if m.graphQL {
+					m.activePanel = variablesPanel
+				}
 			}
 			return m.updateFocus()
 
 		case key.Matches(msg, keys.Enter):
+			if m.activePanel == urlPanel && looksLikeCurl(m.urlInput.Value()) {
+				m.importCurl(m.urlInput.Value())
+				return m, nil
+			}
 			if m.activePanel == urlPanel && m.urlInput.Value() != "" {
-				m.loading = true
-				return m, m.sendRequest()
+				m.saveDraft()
+				return m.trySend()
 			}
 
 		case key.Matches(msg, keys.ToggleHelp):
@@ -352,125 +994,1726 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case key.Matches(msg, keys.ToggleHistory):
 			m.showHistory = !m.showHistory
 			m.showEnvs = false // Close other panels
+			m.refreshHistory()
 			return m, nil
 
 		case key.Matches(msg, keys.ToggleEnvs):
-			m.showEnvs = !m.showEnvs
-			m.showHistory = false // Close other panels
-			return m, nil
+			return m.openEnvs(), nil
+
+		case key.Matches(msg, keys.Auth):
+			return m.openAuth(), nil
 
 		case key.Matches(msg, keys.SaveRequest):
-			if m.configManager != nil && m.urlInput.Value() != "" {
-				headers := make(map[string]string)
-				headerLines := strings.SplitSeq(m.headersInput.Value(), "\n")
-				for line := range headerLines {
-					line = strings.TrimSpace(line)
-					if line == "" {
-						continue
-					}
-					parts := strings.SplitN(line, ":", 2)
-					if len(parts) == 2 {
-						headers[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
-					}
-				}
+			if m.configManager == nil || m.urlInput.Value() == "" {
+				return m, nil
+			}
+			m.pendingSave = m.currentRequest()
+			return m.promptSaveCollection()
 
-				method := httpMethods[0] // Default to GET
-				if i := m.methodList.Index(); i >= 0 && i < len(httpMethods) {
-					method = httpMethods[i]
-				}
+		case key.Matches(msg, keys.ShareRequest):
+			if m.urlInput.Value() == "" {
+				return m, nil
+			}
+			link, err := m.shareLink()
+			if err != nil {
+				m.requestError = err
+				return m, nil
+			}
+			m.copyToClipboard("Share link", link)
+			return m, nil
 
-				reqItem := RequestItem{
-					ID:      fmt.Sprintf("%d", time.Now().UnixNano()),
-					Name:    fmt.Sprintf("%s %s", method, m.urlInput.Value()),
-					URL:     m.urlInput.Value(),
-					Method:  method,
-					Headers: headers,
-					Body:    m.bodyInput.Value(),
-				}
+		case key.Matches(msg, keys.ImportShare):
+			return m.openPrompt(promptImportShare, "Paste share link: ", "")
+
+		case key.Matches(msg, keys.Dashboard):
+			if m.configManager == nil {
+				return m, nil
+			}
+			collection := m.dashboard.collection
+			if collection == "" {
+				collection = "Default"
+			}
+			return m.openPrompt(promptDashboardCollection, "Dashboard collection: ", collection)
 
-				_ = m.configManager.addToCollection("Default", reqItem)
+		case key.Matches(msg, keys.NormalizeURL):
+			if m.urlInput.Value() == "" {
+				return m, nil
 			}
+			normalized, err := normalizeURL(m.urlInput.Value())
+			if err != nil {
+				m.requestError = err
+				return m, nil
+			}
+			m.urlInput.SetValue(normalized)
+			m.urlInput.CursorEnd()
+			m.statusMessage = "URL normalized: " + normalized
 			return m, nil
-		}
 
-	case tea.WindowSizeMsg:
-		m.width = msg.Width
-		m.height = msg.Height
-		m.updatePanelSizes()
+		case key.Matches(msg, keys.Connections):
+			m.showConns = true
+			return m, nil
 
-	case Response:
-		m.response = msg
-		m.loading = false
-		if msg.Error != nil {
-			m.requestError = msg.Error
-		}
-	
-		m.bodyInput.SetValue(m.lastBody)
-		m.responseView.SetContent(m.formatResponse())
-		return m, nil
-	}
+		case key.Matches(msg, keys.Cookies):
+			m.showCookies = true
+			return m, nil
 
-	switch m.activePanel {
-	case urlPanel:
-		m.urlInput, cmd = m.urlInput.Update(msg)
-		cmds = append(cmds, cmd)
+		case key.Matches(msg, keys.GraphQL):
+			m.graphQL = !m.graphQL
+			if !m.graphQL {
+				if m.activePanel == variablesPanel {
+					m.activePanel = bodyPanel
+				}
+				m.updatePanelSizes()
+				m.statusMessage = "GraphQL mode off"
+				return m.updateFocus()
+			}
+			selectMethod(&m, http.MethodPost)
+			m.headersInput.SetValue(setHeaderLine(m.headersInput.Value(), "Content-Type", "application/json"))
+			m.updatePanelSizes()
+			m.statusMessage = "GraphQL mode: query in the body panel, variables next to it"
+			return m, nil
 
-	case methodPanel:
-		m.methodList, cmd = m.methodList.Update(msg)
-		cmds = append(cmds, cmd)
+		case key.Matches(msg, keys.Repeat):
+			if m.urlInput.Value() == "" {
+				return m, nil
+			}
+			if m.loading {
+				m.statusMessage = "Request in progress, wait for it to finish"
+				return m, nil
+			}
+			count := m.repeatCount
+			if count == 0 {
+				count = 10
+			}
+			return m.openPrompt(promptRepeatCount, "Repeat count: ", strconv.Itoa(count))
 
-	case headersPanel:
-		m.headersInput, cmd = m.headersInput.Update(msg)
-		cmds = append(cmds, cmd)
+		case key.Matches(msg, keys.Review):
+			return m.toggleReview()
 
-	case bodyPanel:
-		m.bodyInput, cmd = m.bodyInput.Update(msg)
-		m.lastBody = m.bodyInput.Value() // Update lastBody when body input changes
-		cmds = append(cmds, cmd)
+		case key.Matches(msg, keys.PlayMacro):
+			if m.configManager == nil {
+				return m, nil
+			}
+			if m.recording {
+				m.statusMessage = "Stop recording (f2) before playing a macro"
+				return m, nil
+			}
+			return m.openPrompt(promptPlayMacro, "Play macro: ", "")
 
-	case responsePanel:
-		m.responseView, cmd = m.responseView.Update(msg)
-		cmds = append(cmds, cmd)
-	}
+		case key.Matches(msg, keys.SavedRequests):
+			if m.configManager == nil {
+				return m, nil
+			}
+			m.savedList.ResetFilter()
+			m.showSaved = true
+			return m, m.refreshSaved()
 
-	if m.loading {
-		var spinnerCmd tea.Cmd
-		m.spinner, spinnerCmd = m.spinner.Update(msg)
-		cmds = append(cmds, spinnerCmd)
-	}
+		case key.Matches(msg, keys.Collections):
+			if m.configManager == nil {
+				return m, nil
+			}
+			m.showCollections = true
+			m.openCollection = ""
+			cmd := m.refreshCollections()
+			m.collectionList.Select(0)
+			return m, cmd
+
+		case key.Matches(msg, keys.Timeline):
+			if len(m.timeline) == 0 {
+				m.statusMessage = "No requests sent this session"
+				return m, nil
+			}
+			items := make([]list.Item, len(m.timeline))
+			for i, e := range m.timeline {
+				items[i] = e
+			}
+			m.timelineList.ResetFilter()
+			cmd := m.timelineList.SetItems(items)
+			m.timelineList.Select(len(items) - 1)
+			m.showTimeline = true
+			return m, cmd
+
+		case key.Matches(msg, keys.TrailingSlash):
+			m.trailingSlash = nextTrailingSlashMode(m.trailingSlash)
+			mode := m.trailingSlash
+			if mode == "" {
+				mode = "config default"
+			}
+			m.statusMessage = "Trailing slash: " + mode
+			return m, nil
 
-	return m, tea.Batch(cmds...)
-}
+		case key.Matches(msg, keys.Palette):
+			return m.openPalette()
 
-func (m Model) updateFocus() (tea.Model, tea.Cmd) {
-	var cmds []tea.Cmd
+		case key.Matches(msg, keys.SchemaPath):
+			return m.openPrompt(promptSchemaPath, "JSON Schema file (empty for none): ", m.schemaPath)
 
-	m.urlInput.Blur()
-	m.headersInput.Blur()
-	m.bodyInput.Blur()
+		case key.Matches(msg, keys.ConvertBody):
+			m.convertBody()
+			return m, nil
 
-	switch m.activePanel {
-	case methodPanel:
-		return m, nil
+		case key.Matches(msg, keys.CopyAsFetch):
+			if m.urlInput.Value() == "" {
+				m.statusMessage = "Enter a URL first"
+				return m, nil
+			}
+			snippet, err := m.fetchSnippet()
+			if err != nil {
+				m.requestError = err
+				return m, nil
+			}
+			m.previewView = "fetch() (esc to return to the response):\n\n" + snippet
+			m.refreshResponseView()
+			m.responseView.GotoTop()
+			m.copyToClipboard("fetch() snippet", snippet)
+			return m, nil
 
-	case urlPanel:
-		m.urlInput.Focus()
-		cmds = append(cmds, textinput.Blink)
+		case key.Matches(msg, keys.DryRun):
+			if m.urlInput.Value() == "" {
+				m.statusMessage = "Enter a URL first"
+				return m, nil
+			}
+			preview, err := m.dryRun()
+			if err != nil {
+				m.requestError = err
+				return m, nil
+			}
+			m.requestError = nil
+			m.previewView = preview
+			m.refreshResponseView()
+			m.responseView.GotoTop()
+			m.statusMessage = "Dry run: nothing was sent"
+			return m, nil
 
-	case headersPanel:
-		m.headersInput.Focus()
-		cmds = append(cmds, textinput.Blink)
+		case m.previewView != "" && key.Matches(msg, keys.Cancel):
+			m.previewView = ""
+			m.refreshResponseView()
+			return m, nil
 
-	case bodyPanel:
-		m.bodyInput.Focus()
-		cmds = append(cmds, textinput.Blink)
-	}
+		case key.Matches(msg, keys.LintBody):
+			if strings.TrimSpace(m.bodyInput.Value()) == "" {
+				m.statusMessage = "Body is empty"
+				return m, nil
+			}
+			if err := lintJSON(m.bodyInput.Value()); err != nil {
+				m.statusMessage = "Invalid JSON: " + err.Error()
+				return m.showLintError(err)
+			}
+			m.statusMessage = "Body is valid JSON"
+			return m, nil
 
-	if len(cmds) > 0 {
-		return m, tea.Batch(cmds...)
-	}
-	return m, nil
-}
+		case m.activePanel == responsePanel && m.searchQuery != "" && key.Matches(msg, keys.Cancel):
+			m.clearSearch()
+			m.statusMessage = ""
+			return m, nil
+
+		case m.activePanel == responsePanel && m.autoFocused && key.Matches(msg, keys.Cancel):
+			m.autoFocused = false
+			m.activePanel = m.returnPanel
+			return m.updateFocus()
+
+		case m.ws != nil && key.Matches(msg, keys.Cancel):
+			m.statusMessage = "Closing WebSocket..."
+			session := m.ws
+			return m, func() tea.Msg {
+				session.close()
+				return nil
+			}
+
+		case key.Matches(msg, keys.SendMessage):
+			if m.ws == nil {
+				m.statusMessage = "No WebSocket connection: enter a ws:// or wss:// URL and send it to connect"
+				return m, nil
+			}
+			return m, sendWebSocket(m.ws, m.resolveForSend(m.bodyInput.Value()))
+
+		case m.activePanel == responsePanel && key.Matches(msg, keys.AddBookmark):
+			if m.response.StatusCode > 0 || m.response.Error != nil {
+				return m.openPrompt(promptBookmarkNote, "Bookmark note: ", "")
+			}
+			return m, nil
+
+		case m.activePanel == responsePanel && key.Matches(msg, keys.DelBookmark):
+			m.bookmarks = removeBookmark(m.bookmarks, m.responseView.YOffset)
+			m.refreshResponseView()
+			return m, nil
+
+		case m.activePanel == responsePanel && key.Matches(msg, keys.NextBookmark):
+			if b, ok := nextBookmark(m.bookmarks, m.responseView.YOffset); ok {
+				m.responseView.SetYOffset(b.Line)
+			}
+			return m, nil
+
+		case m.activePanel == responsePanel && key.Matches(msg, keys.PrevBookmark):
+			if b, ok := prevBookmark(m.bookmarks, m.responseView.YOffset); ok {
+				m.responseView.SetYOffset(b.Line)
+			}
+			return m, nil
+
+		case m.activePanel == responsePanel && key.Matches(msg, keys.Search):
+			if m.response.StatusCode > 0 || m.response.Error != nil {
+				return m.openPrompt(promptSearch, "/", m.searchQuery)
+			}
+			return m, nil
+
+		case m.activePanel == responsePanel && len(m.searchMatches) > 0 && key.Matches(msg, keys.NextMatch):
+			m.jumpToMatch(m.searchIndex + 1)
+			return m, nil
+
+		case m.activePanel == responsePanel && len(m.searchMatches) > 0 && key.Matches(msg, keys.PrevMatch):
+			m.jumpToMatch(m.searchIndex - 1)
+			return m, nil
+
+		case m.activePanel == responsePanel && key.Matches(msg, keys.Extract):
+			if m.response.Body != "" {
+				return m.openPrompt(promptExtract, "Extract (e.g. data.items.0.id): ", m.extractPath)
+			}
+			return m, nil
+
+		case m.activePanel == responsePanel && key.Matches(msg, keys.ShowBookmarks):
+			m.showBookmarks = !m.showBookmarks
+			return m, nil
+
+		case m.activePanel == responsePanel && key.Matches(msg, keys.ShowPaths):
+			lines, err := flattenJSON([]byte(m.response.Body))
+			if err != nil {
+				m.requestError = err
+				return m, nil
+			}
+			items := make([]list.Item, len(lines))
+			for i, line := range lines {
+				items[i] = newPathItem(line)
+			}
+			m.pathList.ResetFilter()
+			cmd := m.pathList.SetItems(items)
+			m.pathList.Select(0)
+			m.showPaths = true
+			return m, cmd
+
+		case m.activePanel == responsePanel && key.Matches(msg, keys.ToggleMeta):
+			m.showMetadata = !m.showMetadata
+			m.refreshResponseView()
+			return m, nil
+
+		case m.activePanel == responsePanel && key.Matches(msg, keys.RawView):
+			m.showRaw = !m.showRaw
+			m.refreshResponseView()
+			if m.showRaw {
+				m.statusMessage = "Raw view (u for formatted)"
+			} else {
+				m.statusMessage = "Formatted view"
+			}
+			return m, nil
+
+		case m.activePanel == responsePanel && key.Matches(msg, keys.FoldHeaders):
+			m.foldHeaders = !m.foldHeaders
+			m.refreshResponseView()
+			if m.foldHeaders {
+				m.statusMessage = "Headers folded (f to unfold)"
+			} else {
+				m.statusMessage = "Headers unfolded"
+			}
+			return m, nil
+
+		case m.activePanel == responsePanel && key.Matches(msg, keys.DiffResponse):
+			if m.showDiff {
+				m.showDiff = false
+				m.refreshResponseView()
+				m.statusMessage = "Diff hidden"
+				return m, nil
+			}
+			if reason := diffable(m.prevResponse, m.response); reason != "" {
+				m.statusMessage = reason
+				return m, nil
+			}
+			m.showDiff = true
+			m.refreshResponseView()
+			m.responseView.GotoTop()
+			m.statusMessage = "Showing changes since the previous response"
+			return m, nil
+
+		case m.activePanel == responsePanel && key.Matches(msg, keys.CopyTimings):
+			if m.response.Timings.Total == 0 {
+				m.statusMessage = "No timing breakdown for this response"
+				return m, nil
+			}
+			m.copyToClipboard("Timing breakdown", formatTimings(m.response.Timings))
+			return m, nil
+
+		case m.activePanel == responsePanel && key.Matches(msg, keys.ExportTimings):
+			if m.response.Timings.Total == 0 {
+				m.statusMessage = "No timing breakdown for this response"
+				return m, nil
+			}
+			data, err := timingsJSON(m.response.Timings)
+			if err != nil {
+				m.requestError = err
+				return m, nil
+			}
+			m.copyToClipboard("Timing JSON", data)
+			return m, nil
+
+		case m.activePanel == responsePanel && key.Matches(msg, keys.CopySummary):
+			if m.response.StatusCode == 0 && m.response.Error == nil {
+				return m, nil
+			}
+			fields := defaultCopySummaryFields
+			if m.configManager != nil && len(m.configManager.Config.CopySummaryFields) > 0 {
+				fields = m.configManager.Config.CopySummaryFields
+			}
+			m.copyToClipboard("Summary", statusSummary(m.response, fields))
+			return m, nil
+
+		case m.activePanel == responsePanel && key.Matches(msg, keys.SaveResponse):
+			if m.response.StatusCode == 0 {
+				m.statusMessage = "No response to save"
+				return m, nil
+			}
+			path, err := saveResponseBody(m.configManager.downloadDir(), m.response.Headers.Get("Content-Type"), m.response.Body, time.Now())
+			if err != nil {
+				m.requestError = fmt.Errorf("saving response: %w", err)
+				return m, nil
+			}
+			m.statusMessage = fmt.Sprintf("Saved response body (%s) to %s", formatBytes(int64(len(m.response.Body))), path)
+			return m, nil
+
+		case m.activePanel == responsePanel && key.Matches(msg, keys.CopyCurl):
+			if m.response.Sent.URL == "" {
+				m.statusMessage = "No sent request for this response"
+				return m, nil
+			}
+			m.copyToClipboard("curl command", buildCurl(m.response.Sent))
+			return m, nil
+
+		case m.activePanel == responsePanel && key.Matches(msg, keys.CopyBody):
+			if m.response.StatusCode == 0 || m.response.Body == "" {
+				m.statusMessage = "No response body to copy"
+				return m, nil
+			}
+			m.copyToClipboard("Body", m.response.Body)
+			return m, nil
+
+		case m.activePanel == responsePanel && key.Matches(msg, keys.CopyHeader):
+			if len(m.response.Headers) == 0 {
+				m.statusMessage = "No response headers to copy"
+				return m, nil
+			}
+			return m.openPrompt(promptCopyHeader, "Copy header: ", "")
+
+		case m.activePanel == responsePanel && key.Matches(msg, keys.EditLastSent):
+			if m.lastSent.URL == "" {
+				m.statusMessage = "No request sent yet"
+				return m, nil
+			}
+			m.loadRequest(m.lastSent)
+			m.autoFocused = false
+			m.activePanel = urlPanel
+			m.statusMessage = "Loaded the last sent request"
+			return m.updateFocus()
+
+		case m.activePanel == responsePanel && key.Matches(msg, keys.DecodeJWT):
+			m.jwts = findJWTs(parseHeaders(m.headersInput.Value()), m.response.Headers, m.response.Body)
+			if len(m.jwts) == 0 {
+				m.statusMessage = "No JWTs found in the request headers or response"
+				return m, nil
+			}
+			m.jwtCursor = 0
+			m.showJWTs = true
+			return m, nil
+		}
+
+	case autosaveMsg:
+		if m.configManager != nil && m.confirmKind != confirmRestoreRecovery {
+			state := m.recoveryState()
+			if !state.sameContent(m.lastAutosave) {
+				if state.empty() {
+					_ = m.configManager.clearRecovery()
+				} else {
+					state.SavedAt = time.Now()
+					_ = m.configManager.saveRecovery(state)
+				}
+				m.lastAutosave = state
+			}
+		}
+		return m, autosaveTick()
+
+	case dashboardTickMsg:
+		if !m.showDashboard || msg.generation != m.dashboard.generation || m.dashboard.paused || m.dashboard.running {
+			return m, nil
+		}
+		m.dashboard.running = true
+		return m, m.checkDashboard()
+
+	case dashboardResultMsg:
+		if msg.generation != m.dashboard.generation {
+			return m, nil
+		}
+		m.dashboard.running = false
+		now := time.Now()
+		for i, res := range msg.results {
+			if i < len(m.dashboard.entries) {
+				m.dashboard.entries[i].Last = res
+				m.dashboard.entries[i].Checked = now
+			}
+		}
+		if !m.showDashboard || m.dashboard.paused {
+			return m, nil
+		}
+		return m, dashboardTick(m.dashboard.generation, m.dashboard.interval)
+
+	case wsConnectedMsg, wsFrameMsg, wsClosedMsg:
+		return m.updateWebSocket(msg)
+
+	case sseStartMsg:
+		m.stream = msg.stream
+		m.showResponse(msg.stream.head)
+		m.statusMessage = "Streaming events, esc to stop"
+		return m, waitForEvent(msg.stream)
+
+	case sseEventMsg:
+		if msg.stream == m.stream {
+			follow := m.responseView.AtBottom()
+			msg.stream.shown.add(msg.event)
+			m.response.Events = msg.stream.shown.events
+			m.refreshResponseView()
+			if follow {
+				m.responseView.GotoBottom()
+			}
+		}
+		return m, waitForEvent(msg.stream)
+
+	case repeatResultMsg:
+		m.loading = false
+		m.repeating = false
+		if m.cancelRequest != nil {
+			m.cancelRequest()
+			m.cancelRequest = nil
+		}
+		m.showResponse(msg.results[len(msg.results)-1])
+		m.repeatReport = formatRepeatStats(&m.styles, msg)
+		m.refreshResponseView()
+		m.statusMessage = fmt.Sprintf("Repeated %d requests", msg.count)
+		return m, nil
+
+	case list.FilterMatchesMsg:
+		if m.showPalette {
+			m.paletteList, cmd = m.paletteList.Update(msg)
+			return m, cmd
+		}
+
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		m.updatePanelSizes()
+
+	case Response:
+		m.loading = false
+		if msg.Error != nil {
+			m.requestError = msg.Error
+		}
+		if m.cancelRequest != nil {
+			m.cancelRequest() // Releases the context
+			m.cancelRequest = nil
+		}
+	
+		m.bodyInput.SetValue(m.lastBody)
+		m.timeline = appendTimeline(m.timeline, timelineEntry{At: time.Now(), Request: msg.Request, Response: msg})
+		m.recordLatency(msg)
+		if m.response.StatusCode > 0 && m.response.Error == nil {
+			m.prevResponse = m.response
+		}
+		m.showResponse(msg)
+		if m.stream != nil {
+			m.stream = nil
+			m.statusMessage = "Stream " + msg.StreamEnd
+			m.responseView.GotoBottom()
+		}
+		if msg.StatusCode > 0 {
+			m.lastJSON = parseResponseJSON(msg.Body)
+		}
+		if m.configManager.recordHAR() {
+			m.harRecords = append(m.harRecords, harRecord{Started: time.Now().Add(-msg.ResponseTime), Response: msg})
+		}
+		if m.handlerAttempts > 0 {
+			m.statusMessage = fmt.Sprintf("Final response after %d status handler attempt(s)", m.handlerAttempts)
+		}
+		if refs := msg.Sent.OSEnvRefs; len(refs) > 0 {
+			m.statusMessage = "From OS environment: " + strings.Join(refs, ", ")
+		}
+		if refs := msg.Sent.UnresolvedRefs; len(refs) > 0 {
+			m.statusMessage = "Sent unresolved: " + strings.Join(refs, ", ")
+		}
+		if cmd, handled := m.handleStatus(msg); handled {
+			return m, cmd
+		}
+		m.offerSave(msg)
+
+		if errors.Is(msg.Error, errRequestCancelled) {
+			return m, nil
+		}
+		if m.configManager != nil && m.configManager.Config.AutoFocusResponse && m.activePanel != responsePanel {
+			m.returnPanel = m.activePanel
+			m.autoFocused = true
+			m.activePanel = responsePanel
+			return m.updateFocus()
+		}
+		return m, nil
+	}
+
+	switch m.activePanel {
+	case urlPanel:
+		m.urlInput, cmd = m.urlInput.Update(msg)
+		cmds = append(cmds, cmd)
+
+	case methodPanel:
+		m.methodList, cmd = m.methodList.Update(msg)
+		cmds = append(cmds, cmd)
+
+	case queryPanel:
+		m.queryInput, cmd = m.queryInput.Update(msg)
+		cmds = append(cmds, cmd)
+
+	case headersPanel:
+		m.headersInput, cmd = m.headersInput.Update(msg)
+		cmds = append(cmds, cmd)
+
+	case bodyPanel:
+		m.bodyInput, cmd = m.bodyInput.Update(msg)
+		m.lastBody = m.bodyInput.Value() // Update lastBody when body input changes
+		cmds = append(cmds, cmd)
+
+	case variablesPanel:
+		m.variablesInput, cmd = m.variablesInput.Update(msg)
+		cmds = append(cmds, cmd)
+
+	case responsePanel:
+		// Typing after an automatic focus change goes back to the panel
+		// that was being edited
+		if keyMsg, ok := msg.(tea.KeyMsg); ok && m.autoFocused && isEditKey(keyMsg, m.responseView.KeyMap) {
+			m.autoFocused = false
+			m.activePanel = m.returnPanel
+			focused, focusCmd := m.updateFocus()
+			next, cmd := focused.(Model).Update(msg)
+			return next, tea.Batch(focusCmd, cmd)
+		}
+		m.responseView, cmd = m.responseView.Update(msg)
+		cmds = append(cmds, cmd)
+	}
+
+	if m.loading {
+		var spinnerCmd tea.Cmd
+		m.spinner, spinnerCmd = m.spinner.Update(msg)
+		cmds = append(cmds, spinnerCmd)
+	}
+
+	return m, tea.Batch(cmds...)
+}
+
+// isEditKey reports whether msg types into an input rather than scrolling
+// the response viewport.
+func isEditKey(msg tea.KeyMsg, scroll viewport.KeyMap) bool {
+	switch msg.Type {
+	case tea.KeyRunes, tea.KeyBackspace, tea.KeyDelete:
+	default:
+		return false
+	}
+	if msg.Alt {
+		return false
+	}
+	return !key.Matches(msg, scroll.PageDown, scroll.PageUp, scroll.HalfPageUp, scroll.HalfPageDown,
+		scroll.Down, scroll.Up, scroll.Left, scroll.Right)
+}
+
+func (m Model) updateFocus() (tea.Model, tea.Cmd) {
+	var cmds []tea.Cmd
+
+	m.urlInput.Blur()
+	m.queryInput.Blur()
+	m.headersInput.Blur()
+	m.bodyInput.Blur()
+	m.variablesInput.Blur()
+
+	switch m.activePanel {
+	case methodPanel:
+		return m, nil
+
+	case urlPanel:
+		m.urlInput.Focus()
+		cmds = append(cmds, textinput.Blink)
+
+	case queryPanel:
+		cmds = append(cmds, m.queryInput.Focus(), textarea.Blink)
+
+	case headersPanel:
+		cmds = append(cmds, m.headersInput.Focus(), textarea.Blink)
+
+	case bodyPanel:
+		cmds = append(cmds, m.bodyInput.Focus(), textarea.Blink)
+
+	case variablesPanel:
+		cmds = append(cmds, m.variablesInput.Focus(), textarea.Blink)
+	}
+
+	if len(cmds) > 0 {
+		return m, tea.Batch(cmds...)
+	}
+	return m, nil
+}
+
+// trySend sends the current request. Path variables in the URL are
+// prompted for first, one at a time.
+func (m Model) trySend() (tea.Model, tea.Cmd) {
+	if m.loading {
+		m.statusMessage = "Request in progress, wait for it to finish"
+		return m, nil
+	}
+	if names := pathParams(m.urlInput.Value()); len(names) > 0 {
+		m.pendingParams = names
+		return m.promptNextPathParam()
+	}
+	return m.confirmAndSend()
+}
+
+// promptNextPathParam asks for the first pending path variable, prefilled
+// with its previous value.
+func (m Model) promptNextPathParam() (tea.Model, tea.Cmd) {
+	name := m.pendingParams[0]
+	return m.openPrompt(promptPathParam, "Value for :"+name+": ", m.pathParams[name])
+}
+
+// confirmAndSend sends the editor request, asking first when its JSON body
+// doesn't parse or the active environment is protected.
+func (m Model) confirmAndSend() (tea.Model, tea.Cmd) {
+	reqItem := m.currentRequest()
+	reqItem.Body = m.lastBody
+	if err := m.lintBody(reqItem); err != nil {
+		m.confirmKind = confirmInvalidJSON
+		m.confirmText = "Body isn't valid JSON: " + err.Error() + ". Send anyway?"
+		return m, nil
+	}
+	return m.confirmEnvAndSend()
+}
+
+// confirmEnvAndSend sends the editor request, asking first when the active
+// environment is protected.
+func (m Model) confirmEnvAndSend() (tea.Model, tea.Cmd) {
+	if m.configManager != nil {
+		env := m.configManager.getCurrentEnvironment()
+		req := m.currentRequest()
+		switch {
+		case m.configManager.isProtectedEnv(env.Name):
+			m.confirmKind = confirmProtectedSend
+			m.confirmText = fmt.Sprintf("Environment %q is protected. Send %s to %s?",
+				env.Name, req.Method, m.effectiveURL(req))
+			return m, nil
+		case m.configManager.confirmDestructive(req.Method):
+			m.confirmKind = confirmProtectedSend
+			m.confirmText = fmt.Sprintf("Send %s to %s", req.Method, m.effectiveURL(req))
+			if env.Name != "" {
+				m.confirmText += fmt.Sprintf(" in environment %q", env.Name)
+			}
+			m.confirmText += "?"
+			return m, nil
+		}
+	}
+	return m.startSend()
+}
+
+func (m Model) startSend() (tea.Model, tea.Cmd) {
+	reqItem := m.currentRequest()
+	reqItem.Body = m.lastBody
+	if isWebSocketURL(m.effectiveURL(reqItem)) {
+		if m.ws != nil {
+			m.statusMessage = "WebSocket already open, press esc to close it first"
+			return m, nil
+		}
+		m.loading = true
+		m.requestError = nil
+		return m, m.connectWebSocket(m.newRequestContext(), reqItem)
+	}
+	if reqItem.Method == "GET" && reqItem.Body != "" && m.configManager.allowGetBody(reqItem.AllowGetBody) {
+		m.statusMessage = "Warning: sending a body with GET is non-standard and may be ignored or rejected"
+	}
+	return m.dispatch(reqItem)
+}
+
+// dispatch starts sending reqItem unless a request is already in flight,
+// in which case the send is dropped so loading always tracks exactly one
+// request.
+func (m Model) dispatch(reqItem RequestItem) (tea.Model, tea.Cmd) {
+	if m.loading {
+		m.statusMessage = "Request in progress, wait for it to finish"
+		return m, nil
+	}
+	m.loading = true
+	m.lastSent = reqItem
+	m.handlerAttempts = 0
+	return m, m.sendRequest(m.newRequestContext(), reqItem)
+}
+
+// sendsBody reports whether a request with the given method carries a
+// body. GET only does when allowGetBody is set; HEAD never does.
+func sendsBody(method string, allowGetBody bool) bool {
+	switch method {
+	case "HEAD":
+		return false
+	case "GET":
+		return allowGetBody
+	}
+	return true
+}
+
+// updateConfirm handles a pending yes/no confirmation. Only y confirms;
+// any other key cancels.
+func (m Model) updateConfirm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	kind := m.confirmKind
+	m.confirmKind = confirmNone
+	m.confirmText = ""
+
+	confirmed := msg.String() == "y" || msg.String() == "Y"
+
+	switch kind {
+	case confirmProtectedSend:
+		if confirmed {
+			return m.startSend()
+		}
+
+	case confirmDeleteEnv:
+		env := m.selectedEnv()
+		if !confirmed {
+			m.statusMessage = "Cancelled"
+			return m, nil
+		}
+		if err := m.configManager.DeleteEnvironment(env); err != nil {
+			m.requestError = err
+			return m, nil
+		}
+		m.envVarFocus = false
+		m.refreshEnvs("")
+		m.statusMessage = fmt.Sprintf("Deleted environment %q", env)
+		if current := m.configManager.currentEnvName(); current != "" {
+			m.statusMessage += ", " + current + " is active"
+		}
+		return m, nil
+
+	case confirmDeleteEnvVar:
+		env, name := m.selectedEnv(), m.envVarEdit
+		m.envVarEdit = ""
+		if !confirmed {
+			m.statusMessage = "Cancelled"
+			return m, nil
+		}
+		if err := m.configManager.DeleteEnvVar(env, name); err != nil {
+			m.requestError = err
+			return m, nil
+		}
+		m.refreshEnvs(env)
+		m.statusMessage = fmt.Sprintf("Deleted %s from %q", name, env)
+		return m, nil
+
+	case confirmInvalidJSON:
+		if confirmed {
+			return m.confirmEnvAndSend()
+		}
+		m.statusMessage = "Cancelled, fix the body and send again"
+		if err := lintJSON(m.lastBody); err != nil {
+			return m.showLintError(err)
+		}
+		return m, nil
+
+	case confirmSaveSuccess:
+		if confirmed {
+			return m.promptSaveCollection()
+		}
+		m.pendingSave = RequestItem{}
+		return m, nil
+
+	case confirmDeleteCollection:
+		name := m.collectionEdit
+		m.collectionEdit = ""
+		if !confirmed {
+			m.statusMessage = "Cancelled"
+			return m, nil
+		}
+		if err := m.configManager.deleteCollection(name); err != nil {
+			m.requestError = err
+			return m, nil
+		}
+		m.statusMessage = fmt.Sprintf("Deleted collection %q", name)
+		return m, m.refreshCollections()
+
+	case confirmRestoreRecovery:
+		if confirmed {
+			m.restoreRecovery(m.recovery)
+			m.statusMessage = "Restored unsaved request"
+		} else if m.configManager != nil {
+			_ = m.configManager.clearRecovery()
+			m.statusMessage = "Discarded unsaved request"
+		}
+		m.recovery = RecoveryState{}
+		return m, nil
+	}
+
+	if !confirmed {
+		m.statusMessage = "Cancelled"
+	}
+	return m, nil
+}
+
+// toggleRecording starts recording keys into a macro, or stops and asks
+// for a name to save it under.
+func (m Model) toggleRecording() (tea.Model, tea.Cmd) {
+	if !m.recording {
+		m.recording = true
+		m.macroSteps = nil
+		m.statusMessage = "Recording macro (f2 to stop)"
+		return m, nil
+	}
+
+	m.recording = false
+	if len(m.macroSteps) == 0 {
+		m.statusMessage = "Nothing recorded"
+		return m, nil
+	}
+	return m.openPrompt(promptMacroName, "Save macro as: ", "")
+}
+
+// offerSave asks whether to save the request behind a successful response
+// when PromptSaveOnSuccess is on and no collection has it yet. It never
+// interrupts another prompt or confirmation.
+func (m *Model) offerSave(resp Response) {
+	if m.configManager == nil || !m.configManager.Config.PromptSaveOnSuccess {
+		return
+	}
+	if resp.Error != nil || resp.StatusCode < 200 || resp.StatusCode >= 300 || resp.Request.URL == "" {
+		return
+	}
+	if m.confirmKind != confirmNone || m.promptKind != promptNone || m.configManager.isSaved(resp.Request) {
+		return
+	}
+
+	m.pendingSave = resp.Request
+	m.confirmKind = confirmSaveSuccess
+	m.confirmText = "Request succeeded. Save it to a collection?"
+}
+
+// promptSaveCollection asks which collection to save pendingSave to,
+// suggesting the existing ones. A new name creates that collection.
+func (m Model) promptSaveCollection() (tea.Model, tea.Cmd) {
+	collection := m.saveCollection
+	if collection == "" {
+		collection = "Default"
+	}
+	return m.openPrompt(promptSaveCollection, "Save to collection (tab completes): ", collection)
+}
+
+// quit exits cleanly, keeping the editor as a draft and removing the
+// crash-recovery snapshot.
+func (m Model) quit() (tea.Model, tea.Cmd) {
+	m.saveDraft()
+	if m.ws != nil {
+		m.ws.close()
+	}
+	if len(m.harRecords) > 0 {
+		if _, err := writeHAR(m.configManager.downloadDir(), m.harRecords); err != nil {
+			m.quitErr = fmt.Errorf("failed to save HAR file: %w", err)
+		}
+	}
+	if m.configManager != nil {
+		m.configManager.flushHistory()
+		_ = m.configManager.clearRecovery()
+		_ = m.configManager.saveLayout(m.layoutState())
+	}
+	return m, tea.Quit
+}
+
+// saveDraft writes the editor to draft.json so it's back on next start.
+func (m Model) saveDraft() {
+	if m.configManager == nil {
+		return
+	}
+	if state := m.recoveryState(); !state.empty() {
+		state.SavedAt = time.Now()
+		_ = m.configManager.saveDraft(state)
+	}
+}
+
+// recoveryState snapshots the editor for crash recovery.
+func (m Model) recoveryState() RecoveryState {
+	return RecoveryState{
+		URL:     m.urlInput.Value(),
+		Method:  m.currentRequest().Method,
+		Headers: m.headersInput.Value(),
+		Body:    m.bodyInput.Value(),
+		Query:   m.queryInput.Value(),
+	}
+}
+
+func (m *Model) restoreRecovery(state RecoveryState) {
+	m.loadRequest(RequestItem{URL: state.URL, Method: state.Method, Body: state.Body})
+	m.headersInput.SetValue(state.Headers)
+	m.queryInput.SetValue(state.Query)
+}
+
+// openPrompt shows the single-line prompt above the help bar, prefilled
+// with value. The submitted value is handled by submitPrompt according to
+// kind.
+func (m Model) openPrompt(kind int, label, value string) (tea.Model, tea.Cmd) {
+	m.promptKind = kind
+	m.prompt.Prompt = label
+	m.prompt.SetValue(value)
+	m.prompt.CursorEnd()
+	m.prompt.Focus()
+	if kind == promptAuthPassword {
+		m.prompt.EchoMode = textinput.EchoPassword
+	}
+	if m.configManager != nil {
+		switch kind {
+		case promptSaveCollection:
+			m.prompt.ShowSuggestions = true
+			m.prompt.SetSuggestions(m.configManager.GetAvailableCollectionNames())
+		case promptPlayMacro:
+			m.prompt.ShowSuggestions = true
+			m.prompt.SetSuggestions(m.configManager.macroNames())
+		case promptCopyHeader:
+			names := make([]string, 0, len(m.response.Headers))
+			for name := range m.response.Headers {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			m.prompt.ShowSuggestions = true
+			m.prompt.SetSuggestions(names)
+		}
+	}
+	return m, textinput.Blink
+}
+
+func (m *Model) closePrompt() {
+	m.promptKind = promptNone
+	m.prompt.ShowSuggestions = false
+	m.prompt.SetSuggestions(nil)
+	m.prompt.EchoMode = textinput.EchoNormal
+	m.prompt.Blur()
+	m.prompt.SetValue("")
+}
+
+func (m Model) updatePrompt(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, keys.Cancel):
+		m.closePrompt()
+		return m, nil
+
+	case key.Matches(msg, keys.Enter):
+		kind, value := m.promptKind, m.prompt.Value()
+		m.closePrompt()
+		return m.submitPrompt(kind, value)
+	}
+
+	var cmd tea.Cmd
+	m.prompt, cmd = m.prompt.Update(msg)
+	return m, cmd
+}
+
+func (m Model) submitPrompt(kind int, value string) (tea.Model, tea.Cmd) {
+	switch kind {
+	case promptBookmarkNote:
+		m.bookmarks = addBookmark(m.bookmarks, m.responseView.YOffset, strings.TrimSpace(value))
+		m.refreshResponseView()
+
+	case promptSearch:
+		m.setSearch(value)
+
+	case promptExtract:
+		path := strings.TrimSpace(value)
+		m.extractPath = path
+		extracted, err := extractJSONPath([]byte(m.response.Body), path)
+		if err != nil {
+			m.requestError = err
+			return m, nil
+		}
+		m.requestError = nil
+		if err := clipboard.WriteAll(extracted); err != nil {
+			m.statusMessage = path + " = " + extracted
+		} else {
+			m.statusMessage = path + " = " + extracted + " (copied to clipboard)"
+		}
+
+	case promptCopyHeader:
+		name := http.CanonicalHeaderKey(strings.TrimSpace(value))
+		values := m.response.Headers.Values(name)
+		if len(values) == 0 {
+			m.statusMessage = "No " + name + " header in the response"
+			return m, nil
+		}
+		m.copyToClipboard("Header "+name, strings.Join(values, ", "))
+
+	case promptReplayOverride:
+		reqItem, err := applyOverride(m.replayItem, value)
+		if err != nil {
+			m.requestError = err
+			return m, nil
+		}
+		return m.dispatch(reqItem)
+
+	case promptDashboardCollection:
+		return m.openDashboard(strings.TrimSpace(value))
+
+	case promptRepeatCount:
+		count, err := parseRepeatCount(value)
+		if err != nil {
+			m.requestError = err
+			return m, nil
+		}
+		m.repeatCount = count
+		return m.openPrompt(promptRepeatConcurrency, "Concurrency: ", strconv.Itoa(m.configManager.maxConcurrency()))
+
+	case promptRepeatConcurrency:
+		return m.startRepeat(value)
+
+	case promptNewCollection:
+		name := strings.TrimSpace(value)
+		if err := m.configManager.createCollection(name); err != nil {
+			m.requestError = err
+			return m, nil
+		}
+		m.statusMessage = fmt.Sprintf("Created collection %q", name)
+		cmd := m.refreshCollections()
+		for i, item := range m.collectionList.Items() {
+			if c, ok := item.(collectionItem); ok && c.Name == name {
+				m.collectionList.Select(i)
+			}
+		}
+		return m, cmd
+
+	case promptRenameCollection:
+		oldName, newName := m.collectionEdit, strings.TrimSpace(value)
+		m.collectionEdit = ""
+		if err := m.configManager.renameCollection(oldName, newName); err != nil {
+			m.requestError = err
+			return m, nil
+		}
+		if m.dashboard.collection == oldName {
+			m.dashboard.collection = newName
+		}
+		m.statusMessage = fmt.Sprintf("Renamed collection %q to %q", oldName, newName)
+		return m, m.refreshCollections()
+
+	case promptNewEnv:
+		name := strings.TrimSpace(value)
+		if err := m.configManager.CreateEnvironment(name); err != nil {
+			m.requestError = err
+			return m, nil
+		}
+		m.refreshEnvs(name)
+		m.statusMessage = fmt.Sprintf("Created environment %q (enter to activate)", name)
+
+	case promptSetEnvVar:
+		env, oldName := m.selectedEnv(), m.envVarEdit
+		m.envVarEdit = ""
+		name, val, ok := strings.Cut(value, "=")
+		if !ok {
+			m.requestError = fmt.Errorf("enter the variable as NAME=value")
+			return m, nil
+		}
+		name = strings.TrimSpace(name)
+		if err := m.configManager.SetEnvVar(env, name, val); err != nil {
+			m.requestError = err
+			return m, nil
+		}
+		if oldName != "" && oldName != name {
+			if err := m.configManager.DeleteEnvVar(env, oldName); err != nil {
+				m.requestError = err
+				return m, nil
+			}
+		}
+		m.refreshEnvs(env)
+		for i, n := range m.selectedEnvVars() {
+			if n == name {
+				m.envVarCursor = i
+			}
+		}
+		m.statusMessage = fmt.Sprintf("Set %s in %q", name, env)
+
+	case promptSchemaPath:
+		path := strings.TrimSpace(value)
+		if path == "" {
+			m.schemaPath = ""
+			m.statusMessage = "Responses won't be validated (save the request to keep this)"
+			return m, nil
+		}
+		if _, err := compileSchema(path); err != nil {
+			m.requestError = err
+			return m, nil
+		}
+		m.schemaPath = path
+		m.statusMessage = "Responses will be validated against " + path + " (save the request to keep this)"
+
+	case promptPathParam:
+		if len(m.pendingParams) == 0 {
+			return m, nil
+		}
+		params := make(map[string]string, len(m.pathParams)+1)
+		for k, v := range m.pathParams {
+			params[k] = v
+		}
+		params[m.pendingParams[0]] = value
+		m.pathParams = params
+		m.pendingParams = m.pendingParams[1:]
+		if len(m.pendingParams) > 0 {
+			return m.promptNextPathParam()
+		}
+		return m.confirmAndSend()
+
+	case promptMacroName:
+		name := strings.TrimSpace(value)
+		steps := m.macroSteps
+		m.macroSteps = nil
+		if name == "" || m.configManager == nil {
+			m.statusMessage = "Macro discarded"
+			return m, nil
+		}
+		if err := m.configManager.saveMacro(name, steps); err != nil {
+			m.requestError = err
+			return m, nil
+		}
+		m.statusMessage = fmt.Sprintf("Saved macro %q (%d steps)", name, len(steps))
+
+	case promptAuthToken, promptAuthUsername, promptAuthPassword:
+		return m.submitAuth(kind, value)
+
+	case promptPlayMacro:
+		name := strings.TrimSpace(value)
+		macros, err := m.configManager.loadMacros()
+		if err != nil {
+			m.requestError = err
+			return m, nil
+		}
+		steps, ok := macros[name]
+		if !ok {
+			m.requestError = fmt.Errorf("macro %q not found", name)
+			return m, nil
+		}
+		return m, replayMacro(macroKeyMsgs(steps, m.configManager.replaceEnvVars))
+
+	case promptSaveCollection:
+		collection := strings.TrimSpace(value)
+		if collection == "" || m.configManager == nil {
+			m.pendingSave = RequestItem{}
+			return m, nil
+		}
+		m.saveCollection = collection
+		return m.openPrompt(promptSaveName, "Request name: ", m.pendingSave.Name)
+
+	case promptSaveName:
+		reqItem := m.pendingSave
+		m.pendingSave = RequestItem{}
+		if name := strings.TrimSpace(value); name != "" {
+			reqItem.Name = name
+		}
+		reqItem.ID = fmt.Sprintf("%d", time.Now().UnixNano())
+		if err := m.configManager.addToCollection(m.saveCollection, reqItem); err != nil {
+			m.requestError = err
+			return m, nil
+		}
+		_ = m.configManager.clearDraft()
+		m.statusMessage = fmt.Sprintf("Saved %q to %s", reqItem.Name, m.saveCollection)
+
+	case promptImportShare:
+		reqItem, err := decodeShareLink(value)
+		if err != nil {
+			m.requestError = err
+			return m, nil
+		}
+		m.loadRequest(reqItem)
+		m.statusMessage = "Imported " + reqItem.Method + " " + reqItem.URL
+	}
+	return m, nil
+}
+
+func (m Model) updatePaths(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.pathList.FilterState() != list.Filtering {
+		switch {
+		case key.Matches(msg, keys.Cancel) && m.pathList.FilterState() == list.Unfiltered:
+			m.showPaths = false
+			return m, nil
+
+		case key.Matches(msg, keys.Enter):
+			if it, ok := m.pathList.SelectedItem().(pathItem); ok {
+				m.copyToClipboard("Path "+it.path, it.path)
+			}
+			return m, nil
+		}
+	}
+
+	var cmd tea.Cmd
+	m.pathList, cmd = m.pathList.Update(msg)
+	return m, cmd
+}
+
+func (m Model) updateConns(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, keys.Quit):
+		return m.quit()
+
+	case key.Matches(msg, keys.Cancel), key.Matches(msg, keys.Connections):
+		m.showConns = false
+
+	case key.Matches(msg, keys.CloseIdle):
+		m.configManager.closeIdleConnections()
+		m.statusMessage = "Closed idle connections"
+	}
+	return m, nil
+}
+
+// refreshSaved reloads the saved requests browser, filtered to the current
+// environment unless savedShowAll is set.
+func (m *Model) refreshSaved() tea.Cmd {
+	env := m.configManager.getCurrentEnvironment().Name
+	saved := m.configManager.savedRequests(env, m.savedShowAll)
+
+	scope := "all environments"
+	if !m.savedShowAll {
+		scope = "environment: " + env
+		if env == "" {
+			scope = "no environment"
+		}
+	}
+	m.savedList.Title = fmt.Sprintf("Saved Requests (%s • a: toggle • enter: load • esc: close)", scope)
+
+	items := make([]list.Item, len(saved))
+	for i, s := range saved {
+		items[i] = s
+	}
+	return m.savedList.SetItems(items)
+}
+
+func (m Model) updateSaved(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.savedList.FilterState() != list.Filtering {
+		switch {
+		case key.Matches(msg, keys.Cancel) && m.savedList.FilterState() == list.Unfiltered:
+			m.showSaved = false
+			return m, nil
+
+		case key.Matches(msg, keys.ShowAll):
+			m.savedShowAll = !m.savedShowAll
+			return m, m.refreshSaved()
+
+		case key.Matches(msg, keys.Enter):
+			if s, ok := m.savedList.SelectedItem().(savedRequest); ok {
+				m.showSaved = false
+				m.loadRequest(s.Request)
+				m.statusMessage = "Loaded " + s.Title() + " from " + s.Collection
+			}
+			return m, nil
+		}
+	}
+
+	var cmd tea.Cmd
+	m.savedList, cmd = m.savedList.Update(msg)
+	return m, cmd
+}
+
+func (m Model) updateTimeline(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.timelineList.FilterState() != list.Filtering {
+		switch {
+		case key.Matches(msg, keys.Cancel) && m.timelineList.FilterState() == list.Unfiltered:
+			m.showTimeline = false
+			return m, nil
+
+		case key.Matches(msg, keys.Enter):
+			if e, ok := m.timelineList.SelectedItem().(timelineEntry); ok {
+				m.showTimeline = false
+				m.showResponse(e.Response)
+				m.statusMessage = "Restored response from " + e.At.Format("15:04:05")
+			}
+			return m, nil
+		}
+	}
+
+	var cmd tea.Cmd
+	m.timelineList, cmd = m.timelineList.Update(msg)
+	return m, cmd
+}
+
+func (m Model) updateJWTs(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, keys.Quit):
+		return m.quit()
+
+	case key.Matches(msg, keys.Cancel), key.Matches(msg, keys.DecodeJWT):
+		m.showJWTs = false
+
+	case key.Matches(msg, keys.Up):
+		if m.jwtCursor > 0 {
+			m.jwtCursor--
+		}
+
+	case key.Matches(msg, keys.Down):
+		if m.jwtCursor < len(m.jwts)-1 {
+			m.jwtCursor++
+		}
+	}
+	return m, nil
+}
+
+// copyToClipboard copies text and reports the outcome in the status line.
+// When no clipboard is available, as over SSH, short single-line text is
+// shown instead and anything longer is written to a temp file.
+func (m *Model) copyToClipboard(label, text string) {
+	if err := clipboard.WriteAll(text); err == nil {
+		m.statusMessage = label + " copied to clipboard"
+		return
+	}
+	lowered := strings.ToLower(label[:1]) + label[1:]
+	if len(text) <= 120 && !strings.Contains(text, "\n") {
+		m.statusMessage = "Clipboard unavailable, " + lowered + ": " + text
+		return
+	}
+	f, err := os.CreateTemp("", "api-client-*.txt")
+	if err != nil {
+		m.requestError = fmt.Errorf("clipboard unavailable and writing a temp file failed: %w", err)
+		return
+	}
+	defer f.Close()
+	if _, err := f.WriteString(text); err != nil {
+		m.requestError = fmt.Errorf("clipboard unavailable and writing %s failed: %w", f.Name(), err)
+		return
+	}
+	m.statusMessage = "Clipboard unavailable, " + lowered + " written to " + f.Name()
+}
+
+// historyItems returns the history entries shown in the history panel.
+func (m Model) historyItems() []RequestItem {
+	if m.configManager == nil {
+		return nil
+	}
+	items := m.configManager.historySnapshot()
+	if limit := m.configManager.historyDisplayLimit(); len(items) > limit {
+		items = items[:limit]
+	}
+	return items
+}
+
+// updateHistory handles keys while the history panel is open. / searches
+// method, URL and name as you type. Entries can be loaded into the editor,
+// or replayed as-is or with a single header or body override without
+// touching the editor.
+func (m Model) updateHistory(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.historyList.FilterState() != list.Filtering {
+		selected, ok := m.historyList.SelectedItem().(historyEntry)
+
+		switch {
+		case key.Matches(msg, keys.Cancel) && m.historyList.FilterState() == list.Unfiltered,
+			key.Matches(msg, keys.ToggleHistory):
+			m.showHistory = false
+			return m, nil
+
+		case key.Matches(msg, keys.Enter), key.Matches(msg, keys.LoadAndSend):
+			if !ok {
+				return m, nil
+			}
+			m.showHistory = false
+			m.loadRequest(selected.RequestItem)
+			if m.configManager != nil {
+				_ = m.configManager.addToHistory(selected.RequestItem) // Moves it to the front
+			}
+			m.statusMessage = "Loaded " + selected.Title()
+			if key.Matches(msg, keys.LoadAndSend) {
+				return m.trySend()
+			}
+			return m, nil
+
+		case key.Matches(msg, keys.Replay):
+			if ok {
+				m.showHistory = false
+				return m.dispatch(selected.RequestItem)
+			}
+			return m, nil
+
+		case key.Matches(msg, keys.ReplayEdit):
+			if ok && !m.loading {
+				m.replayItem = selected.RequestItem
+				m.showHistory = false
+				return m.openPrompt(promptReplayOverride, "Override (Header: value or body=...): ", "")
+			}
+			return m, nil
+		}
+	}
+
+	var cmd tea.Cmd
+	m.historyList, cmd = m.historyList.Update(msg)
+	return m, cmd
+}
+
+// openDashboard starts health-checking every request in the named
+// collection. The first check runs immediately.
+func (m Model) openDashboard(name string) (tea.Model, tea.Cmd) {
+	collection, ok := m.configManager.Collections[name]
+	if !ok {
+		m.requestError = fmt.Errorf("collection %q not found", name)
+		return m, nil
+	}
+
+	interval := defaultDashboardInterval
+	if m.configManager.Config.DashboardInterval > 0 {
+		interval = m.configManager.Config.DashboardInterval
+	}
+
+	entries := make([]dashboardEntry, len(collection.Requests))
+	for i, req := range collection.Requests {
+		entries[i] = dashboardEntry{Request: req}
+	}
+
+	m.dashboard = dashboardState{
+		collection: name,
+		entries:    entries,
+		running:    true,
+		generation: m.dashboard.generation + 1,
+		interval:   time.Duration(interval) * time.Second,
+	}
+	m.showDashboard = true
+	m.showHistory = false
+	m.showEnvs = false
+	return m, m.checkDashboard()
+}
+
+func (m Model) updateDashboard(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, keys.Quit):
+		return m.quit()
+
+	case key.Matches(msg, keys.Cancel), key.Matches(msg, keys.Dashboard):
+		m.showDashboard = false
+		m.dashboard.generation++ // Stop polling
+
+	case key.Matches(msg, keys.Up):
+		if m.dashboard.cursor > 0 {
+			m.dashboard.cursor--
+		}
+
+	case key.Matches(msg, keys.Down):
+		if m.dashboard.cursor < len(m.dashboard.entries)-1 {
+			m.dashboard.cursor++
+		}
+
+	case key.Matches(msg, keys.Pause):
+		m.dashboard.paused = !m.dashboard.paused
+		if !m.dashboard.paused && !m.dashboard.running {
+			m.dashboard.running = true
+			return m, m.checkDashboard()
+		}
+
+	case key.Matches(msg, keys.Replay):
+		if !m.dashboard.running {
+			m.dashboard.running = true
+			return m, m.checkDashboard()
+		}
+
+	case key.Matches(msg, keys.Enter):
+		if m.dashboard.cursor < len(m.dashboard.entries) {
+			entry := m.dashboard.entries[m.dashboard.cursor]
+			if entry.Checked.IsZero() {
+				return m, nil
+			}
+			// Polling stops while the dashboard is hidden; ctrl+b
+			// reopens it
+			m.showDashboard = false
+			m.dashboard.generation++
+			m.showResponse(entry.Last)
+			m.activePanel = responsePanel
+			return m.updateFocus()
+		}
+	}
+	return m, nil
+}
+
+// currentRequest builds a RequestItem from the editor fields.
+func (m Model) currentRequest() RequestItem {
+	method := httpMethods[0] // Default to GET
+	if selected, ok := m.methodList.SelectedItem().(item); ok {
+		method = selected.title
+	}
+
+	return RequestItem{
+		Name:          fmt.Sprintf("%s %s", method, m.urlInput.Value()),
+		URL:           m.urlInput.Value(),
+		Method:        method,
+		Headers:       parseHeaders(m.headersInput.Value()),
+		Body:          m.bodyInput.Value(),
+		Timeout:       m.requestTimeout,
+		TrailingSlash: m.trailingSlash,
+		Summary:       m.summaryTemplate,
+		SchemaPath:    m.schemaPath,
+		AllowGetBody:  m.allowGetBody,
+		Tags:          m.tags,
+		PathParams:    m.pathParams,
+		Auth:          m.auth,
+		Query:         parseQueryParams(m.queryInput.Value()),
+		GraphQL:       m.graphQL,
+		Variables:     m.graphQLVariables(),
+	}
+}
+
+// shareLink encodes the editor request as a share link, with the current
+// environment's values replaced by placeholders when redact_shared_secrets
+// is set.
+func (m Model) shareLink() (string, error) {
+	reqItem := m.currentRequest()
+	if m.configManager != nil && m.configManager.Config.RedactSharedSecrets {
+		reqItem = redactSecrets(reqItem, m.configManager.getCurrentEnvironment().Variables)
+	}
+	return encodeShareLink(reqItem)
+}
+
+// graphQLVariables is the variables editor's content when in GraphQL mode.
+func (m Model) graphQLVariables() string {
+	if !m.graphQL {
+		return ""
+	}
+	return m.variablesInput.Value()
+}
+
+// selectMethod selects method in the method list, GET when it's empty. A
+// method that isn't listed, such as PROPFIND, is added to this model's
+// list so custom methods survive saving and loading.
+func selectMethod(m *Model, method string) {
+	method = strings.ToUpper(strings.TrimSpace(method))
+	if method == "" {
+		method = httpMethods[0]
+	}
+	items := m.methodList.Items()
+	for i, listed := range items {
+		if listed.(item).title == method {
+			m.methodList.Select(i)
+			return
+		}
+	}
+	m.methodList.InsertItem(len(items), item{title: method})
+	m.methodList.Select(len(items))
+}
+
+// loadRequest fills the editor fields from a saved or imported request.
+func (m *Model) loadRequest(req RequestItem) {
+	m.urlInput.SetValue(req.URL)
+	selectMethod(m, req.Method)
+
+	headerKeys := make([]string, 0, len(req.Headers))
+	for k := range req.Headers {
+		headerKeys = append(headerKeys, k)
+	}
+	sort.Strings(headerKeys)
+	headerLines := make([]string, 0, len(headerKeys))
+	for _, k := range headerKeys {
+		headerLines = append(headerLines, k+": "+req.Headers[k])
+	}
+	m.headersInput.SetValue(strings.Join(headerLines, "\n"))
+
+	m.bodyInput.SetValue(req.Body)
+	m.lastBody = req.Body
+	m.requestTimeout = req.Timeout
+	m.trailingSlash = req.TrailingSlash
+	m.summaryTemplate = req.Summary
+	m.schemaPath = req.SchemaPath
+	m.allowGetBody = req.AllowGetBody
+	m.tags = req.Tags
+	m.pathParams = req.PathParams
+	m.auth = req.Auth
+	m.queryInput.SetValue(formatQueryParams(req.Query))
+	m.graphQL = req.GraphQL
+	m.variablesInput.SetValue(req.Variables)
+	m.updatePanelSizes()
+}
+
+// importCurl fills the editor from a curl command line.
+func (m *Model) importCurl(cmd string) {
+	reqItem, err := parseCurl(cmd)
+	if err != nil {
+		m.requestError = err
+		return
+	}
+	m.loadRequest(reqItem)
+	m.statusMessage = "Imported curl command: " + reqItem.Method + " " + reqItem.URL
+}
+
+// effectiveURL is the URL that will actually be sent for reqItem, after
+// variable substitution, trailing-slash handling and the query panel's
+// parameters. Path variables that have no value yet are left as :name.
+func (m Model) effectiveURL(reqItem RequestItem) string {
+	return m.resolveURL(reqItem, m.resolveVars)
+}
+
+// resolveURL is effectiveURL with placeholders in the URL and query
+// substituted by resolve.
+func (m Model) resolveURL(reqItem RequestItem, resolve func(string) string) string {
+	url, _ := bindPathParams(reqItem.URL, reqItem.PathParams)
+	url = resolve(url)
+	if m.configManager == nil {
+		url = applyTrailingSlash(url, reqItem.TrailingSlash)
+	} else {
+		url = applyTrailingSlash(url, m.configManager.trailingSlashMode(reqItem.TrailingSlash))
+	}
+	return appendQueryParams(url, reqItem.Query, resolve)
+}
+
+// convertBody switches the body between a flat JSON object and a
+// form-encoded representation, updating Content-Type to match.
+func (m *Model) convertBody() {
+	body := strings.TrimSpace(m.bodyInput.Value())
+	if body == "" {
+		return
+	}
+
+	var converted, contentType string
+	var err error
+	if strings.HasPrefix(body, "{") {
+		converted, err = jsonToForm(body)
+		contentType = contentTypeForm
+	} else {
+		converted, err = formToJSON(body)
+		contentType = contentTypeJSON
+	}
+	if err != nil {
+		m.requestError = err
+		return
+	}
+
+	m.bodyInput.SetValue(converted)
+	m.lastBody = converted
+	m.headersInput.SetValue(setHeaderLine(m.headersInput.Value(), "Content-Type", contentType))
+	m.statusMessage = "Body converted to " + contentType
+}
+
+// refreshResponseView re-renders the response into the viewport, keeping
+// the current scroll position.
+func (m *Model) refreshResponseView() {
+	content := m.formatResponse()
+	if m.searchQuery != "" {
+		// Matches are found again since toggling metadata moves lines
+		m.searchMatches = findMatches(content, m.searchQuery)
+		if m.searchIndex >= len(m.searchMatches) {
+			m.searchIndex = 0
+		}
+		content = highlightMatches(&m.styles, content, m.searchMatches, m.searchIndex)
+	}
+	m.responseOffsets = lineOffsets(ansi.Strip(content))
+	m.responseView.SetContent(renderGutter(&m.styles, content, m.bookmarks))
+}
+
+// renderBody caches the response body as the response panel shows it:
+// cut to the display limit and syntax highlighted. A large body is
+// processed once per response rather than on every refresh.
+func (m *Model) renderBody() {
+	limit := m.configManager.displayLimit()
+	body, truncated := truncateBody(m.response.FormattedBody, limit)
+	if m.response.JSONFormatted && (m.configManager == nil || m.configManager.Config.SyntaxHighlighting) {
+		body = highlightJSON(&m.styles, body)
+	}
+	if truncated {
+		body += "\n\n" + m.styles.help.Render(fmt.Sprintf("Showing the first %s of %s; raise truncate_response to see more",
+			formatBytes(limit), formatBytes(int64(len(m.response.FormattedBody)))))
+	}
+	m.displayBody = body
+}
 
 func (m *Model) updatePanelSizes() {
 	headerHeight := 4
@@ -482,209 +2725,270 @@ func (m *Model) updatePanelSizes() {
 
 	m.urlInput.Width = m.width - methodWidth - 8
 
-	m.headersInput.Width = (m.width - 4) / 2
-	m.bodyInput.Width = (m.width - 4) / 2
+	// Query, headers and body share the row between the URL and the
+	// response and scroll once their content outgrows it
+	editorHeight := max(availableHeight/2-16, 3)
+	editorsWidth := m.width - 6
+	queryWidth := editorsWidth / 4
+	headersWidth := editorsWidth * 3 / 8
+	m.queryInput.SetWidth(queryWidth)
+	m.queryInput.SetHeight(editorHeight)
+	m.headersInput.SetWidth(headersWidth)
+	m.headersInput.SetHeight(editorHeight)
+	m.bodyInput.SetWidth(editorsWidth - queryWidth - headersWidth)
+	if m.graphQL {
+		// Query and variables split the body's share
+		bodyWidth := (editorsWidth - queryWidth - headersWidth) / 2
+		m.bodyInput.SetWidth(bodyWidth)
+		m.variablesInput.SetWidth(editorsWidth - queryWidth - headersWidth - bodyWidth)
+		m.variablesInput.SetHeight(editorHeight)
+	}
+	m.bodyInput.SetHeight(editorHeight)
 
 	m.responseView.Width = m.width - 4
 	m.responseView.Height = availableHeight / 2
+	if m.reviewMode {
+		// Request and response split the full height side by side
+		half := (m.width - 4) / 2
+		m.requestView.Width = half - 2
+		m.requestView.Height = availableHeight
+		m.responseView.Width = m.width - 4 - half - 2
+		m.responseView.Height = availableHeight
+	}
+
+	m.pathList.SetSize(m.width-6, max(availableHeight/2, 8))
+	m.timelineList.SetSize(m.width-6, max(availableHeight/2, 8))
+	m.savedList.SetSize(m.width-6, max(availableHeight/2, 8))
+	m.collectionList.SetSize(m.width-6, max(availableHeight/2, 8))
+	m.historyList.SetSize(m.width-6, max(availableHeight/2, 8))
+	m.paletteList.SetSize(m.width-6, max(availableHeight/2, 8))
 }
 
-func (m Model) sendRequest() tea.Cmd {
-	return func() tea.Msg {
-		// Don't modify model state here - it won't propagate
-		timeout := 5 * time.Second // Set to 5s for reliability
-		if m.configManager != nil && m.configManager.Config.Timeout > 0 {
-			timeout = time.Duration(m.configManager.Config.Timeout) * time.Second
-		}
+// newEditor creates a multi-line editor for the headers and body panels.
+// Line count is unlimited; the panel scrolls instead of growing.
+func newEditor(placeholder string) textarea.Model {
+	ta := textarea.New()
+	ta.Placeholder = placeholder
+	ta.ShowLineNumbers = false
+	ta.CharLimit = 0
+	ta.MaxHeight = 0
+	ta.SetWidth(50)
+	ta.SetHeight(3)
+	return ta
+}
 
-		ctx, cancel := context.WithTimeout(context.Background(), timeout)
-		defer cancel()
-		url := m.urlInput.Value()
-		if m.configManager != nil {
-			url = m.configManager.replaceEnvVars(url)
-		}
+// newPanelList creates an empty filterable list for the pop-up panels.
+// Quitting is left to the app so q can be typed into the filter.
+func newPanelList(title string) list.Model {
+	l := list.New(nil, list.NewDefaultDelegate(), 0, 0)
+	l.Title = title
+	l.SetShowHelp(false)
+	l.KeyMap.Quit.SetEnabled(false)
+	l.KeyMap.ForceQuit.SetEnabled(false)
+	return l
+}
 
-		method := httpMethods[0] // Default to GET
-		if i := m.methodList.Index(); i >= 0 && i < len(httpMethods) {
-			method = httpMethods[i]
-		}
+// stylePanelList colors a panel list's title with st.
+func stylePanelList(st *styles, l *list.Model) {
+	l.Styles.Title = l.Styles.Title.
+		Foreground(st.Contrast).
+		Background(st.Primary)
+}
 
-		var reqBody io.Reader
-		if method != "GET" && method != "HEAD" {
-			reqBody = strings.NewReader(m.lastBody)
-		}
+// styleMethodList colors the method list with st.
+func styleMethodList(st *styles, l *list.Model) {
+	delegate := list.NewDefaultDelegate()
+	delegate.ShowDescription = false
+	delegate.SetSpacing(1)
+	delegate.Styles.SelectedTitle = delegate.Styles.SelectedTitle.
+		Foreground(st.Primary).
+		Bold(true)
+	l.SetDelegate(delegate)
+	l.Styles.Title = l.Styles.Title.
+		Foreground(st.Primary).
+		Bold(true).
+		MarginLeft(1)
+	l.Styles.NoItems = l.Styles.NoItems.
+		Foreground(st.Accent)
+}
 
-		req, err := http.NewRequest(method, url, reqBody)
-		if err != nil {
-			return Response{Error: err}
-		}
+// showResponse puts r in the response panel, scrolled to the top with
+// bookmarks and search cleared.
+func (m *Model) showResponse(r Response) {
+	m.response = r
+	m.renderBody()
+	m.wsFrames = nil
+	m.showDiff = false
+	m.bookmarks = nil
+	m.showBookmarks = false
+	m.searchQuery = ""
+	m.searchMatches = nil
+	m.searchIndex = 0
+	m.repeatReport = ""
+	m.previewView = ""
+	m.refreshResponseView()
+	m.responseView.GotoTop()
+	if m.reviewMode {
+		m.requestView.SetContent(formatSentRequest(&m.styles, m.reviewRequest()))
+		m.requestView.GotoTop()
+	}
+}
 
-		headers := parseHeaders(m.headersInput.Value())
-		for k, v := range headers {
-			req.Header.Add(k, v)
-		}
-		
-		// Add default User-Agent if not set
-		if req.Header.Get("User-Agent") == "" {
-			req.Header.Set("User-Agent", "api-client-tui/1.0")
+// sendRequest returns a command that sends reqItem and delivers the
+// Response as a message. It only reads from reqItem, so it can send saved
+// requests without loading them into the editor.
+func (m Model) sendRequest(ctx context.Context, reqItem RequestItem) tea.Cmd {
+	return func() tea.Msg {
+		stream := newSSEStream(m.configManager.maxResponseBytes())
+		go func() {
+			resp := m.executeRequest(withSSEStream(ctx, stream), reqItem, true)
+			resp.Request = reqItem
+			stream.done <- resp
+		}()
+		select {
+		case <-stream.started:
+			return sseStartMsg{stream: stream}
+		case resp := <-stream.done:
+			return resp
 		}
+	}
+}
+
+// executeRequest resolves reqItem against the environment and config into
+// a RequestSpec, sends it with doRequest and blocks until the response has
+// been read and formatted, or ctx is cancelled. Batch runners call it
+// directly, passing saveHistory=false so polling doesn't flood the history.
+func (m Model) executeRequest(ctx context.Context, reqItem RequestItem, saveHistory bool) Response {
+	// Don't modify model state here - it won't propagate
+	spec, err := m.buildRequestSpec(reqItem)
+	if err != nil {
+		return Response{Error: err}
+	}
+	// doRequest enforces the timeout itself, so it can be lifted for
+	// event streams
+	client := &http.Client{
+		Transport: m.configManager.sharedTransport(),
+		Jar:       m.cookieJar(),
+	}
+
+	if m.configManager != nil {
+		m.configManager.conns.inFlight.Add(1)
+		defer m.configManager.conns.inFlight.Add(-1)
+	}
+	res := doRequest(ctx, client, spec)
 
-		// Use a simpler HTTP client configuration
-		client := &http.Client{
-			Timeout: timeout,
+	if res.Sent.URL != "" {
+		unresolvedParts := []string{spec.URL, spec.Body}
+		for _, values := range res.Sent.Headers {
+			unresolvedParts = append(unresolvedParts, values...)
 		}
+		res.Sent.UnresolvedRefs = unresolvedResponseRefs(unresolvedParts...)
+		res.Sent.OSEnvRefs = m.configManager.osEnvRefs(requestTemplateParts(reqItem)...)
+	}
+	if res.Error != nil {
+		return res
+	}
+	res.Insecure = res.TLS != nil && m.configManager.insecureSkipVerify()
+	res.Summary = renderSummary(m.configManager.summaryTemplate(reqItem.Summary), []byte(res.Body))
+	if reqItem.SchemaPath != "" && !res.Binary && len(res.Events) == 0 && spec.Method != http.MethodHead {
+		res.Schema = validateSchema(reqItem.SchemaPath, res.Body)
+	}
 
-		req = req.WithContext(ctx)
+	if saveHistory && m.configManager != nil && m.configManager.Config.SaveHistory {
+		m.configManager.queueHistory(RequestItem{
+			URL:       spec.URL,
+			Method:    spec.Method,
+			Headers:   reqItem.Headers,
+			Body:      reqItem.Body,
+			GraphQL:   reqItem.GraphQL,
+			Variables: reqItem.Variables,
+		})
+	}
+	return res
+}
 
-		resultChan := make(chan Response, 1)
-		startTime := time.Now()
+// buildRequestSpec resolves reqItem against the environment and config:
+// placeholders, including dynamic values, are substituted and the auth,
+// compression and User-Agent headers added. Sends and dry runs share it.
+func (m Model) buildRequestSpec(reqItem RequestItem) (RequestSpec, error) {
+	timeout := 5 * time.Second // Set to 5s for reliability
+	if m.configManager != nil {
+		if seconds := m.configManager.requestTimeout(reqItem.Timeout); seconds > 0 {
+			timeout = time.Duration(seconds) * time.Second
+		}
+	} else if reqItem.Timeout > 0 {
+		timeout = time.Duration(reqItem.Timeout) * time.Second
+	}
 
-		go func() {
-			resp, err := client.Do(req)
-			responseTime := time.Since(startTime)
+	if _, missing := bindPathParams(reqItem.URL, reqItem.PathParams); len(missing) > 0 {
+		return RequestSpec{}, missingPathParamsError(missing)
+	}
+	url := m.resolveURL(reqItem, m.resolveForSend)
 
-			if err != nil {
-				var errMsg string
-				switch {
-				case ctx.Err() == context.DeadlineExceeded:
-					errMsg = fmt.Sprintf("Request timed out after %v. The server took too long to respond.", timeout)
-				case strings.Contains(err.Error(), "no such host"):
-					errMsg = "Could not resolve host. Please check the URL and your internet connection."
-				case strings.Contains(err.Error(), "connection refused"):
-					errMsg = "Connection refused. The server is not accepting connections."
-				case strings.Contains(err.Error(), "certificate"):
-					errMsg = "SSL/TLS certificate error. The server's security certificate could not be verified."
-				case strings.Contains(err.Error(), "EOF"):
-					errMsg = "Connection closed unexpectedly. The server terminated the connection."
-				case strings.Contains(err.Error(), "i/o timeout"):
-					errMsg = "Connection timed out. The server is not responding."
-				case strings.Contains(err.Error(), "connection reset"):
-					errMsg = "Connection was reset. The server closed the connection abruptly."
-				default:
-					errMsg = "Request failed: " + err.Error()
-				}
-				resultChan <- Response{
-					Error:        errors.New(errMsg),
-					ResponseTime: responseTime,
-				}
-				return
-			}
-			defer resp.Body.Close()
-
-			contentLength := resp.ContentLength
-			if contentLength > 10*1024*1024 { // 10MB limit
-				resultChan <- Response{
-					StatusCode:     resp.StatusCode,
-					Status:         resp.Status,
-					Headers:        resp.Header,
-					Error:          fmt.Errorf("response too large (%.1f MB) - size limit is 10MB", float64(contentLength)/(1024*1024)),
-					ResponseTime:   responseTime,
-					ContentLength: contentLength,
-				}
-				return
-			} else if contentLength > 1*1024*1024 { // Show warning for responses over 1MB
-				fmt.Printf("Large response detected (%.1f MB). Reading...", float64(contentLength)/(1024*1024))
-			}
+	method := reqItem.Method
+	if method == "" {
+		method = httpMethods[0] // Default to GET
+	}
 
-			var bodyBuf bytes.Buffer
-			limitReader := io.LimitReader(resp.Body, 10*1024*1024)
-			_, err = io.Copy(&bodyBuf, limitReader)
-			if err != nil {
-				resultChan <- Response{
-					StatusCode:     resp.StatusCode,
-					Status:         resp.Status,
-					Headers:        resp.Header,
-					Error:          fmt.Errorf("failed to read response: %v", err),
-					ResponseTime:   responseTime,
-					ContentLength: contentLength,
-				}
-				return
-			}
-			respBody := bodyBuf.Bytes()
+	// Headers and body get the same substitution as the URL. History
+	// keeps the placeholders so secrets aren't written to disk.
+	body := m.resolveForSend(reqItem.Body)
+	sendHeaders := http.Header{}
+	for k, v := range reqItem.Headers {
+		sendHeaders.Add(k, m.resolveForSend(v))
+	}
 
-			contentType := resp.Header.Get("Content-Type")
-			encoding := "utf-8" // default
-			if idx := strings.LastIndex(contentType, "charset="); idx != -1 {
-				encoding = strings.TrimSpace(contentType[idx+8:])
-				if semicolon := strings.Index(encoding, ";"); semicolon != -1 {
-					encoding = encoding[:semicolon]
-				}
-			}
+	if reqItem.GraphQL {
+		wrapped, err := graphQLBody(body, m.resolveForSend(reqItem.Variables))
+		if err != nil {
+			return RequestSpec{}, err
+		}
+		method, body = "POST", wrapped
+		sendHeaders.Set("Content-Type", "application/json")
+	}
 
-			var decodedBody []byte
-			if encoding != "utf-8" && encoding != "UTF-8" {
-				if enc, err := htmlindex.Get(encoding); err == nil {
-					if decoded, _, err := transform.Bytes(enc.NewDecoder(), respBody); err == nil && utf8.Valid(decoded) {
-						decodedBody = decoded
-					}
-				}
-			}
+	if authHeader := reqItem.Auth.header(m.resolveForSend); authHeader != "" {
+		sendHeaders.Set("Authorization", authHeader)
+	}
 
-			if decodedBody == nil {
-				decodedBody = []byte(strings.Map(func(r rune) rune {
-					if r == utf8.RuneError {
-						return '�'
-					}
-					return r
-				}, string(respBody)))
-			}
-
-			formattedBody := string(decodedBody)
-			if len(decodedBody) > 100*1024 { // 100KB
-				formattedBody = fmt.Sprintf("Large response (%d KB) - showing first 1000 chars:\n%s", len(decodedBody)/1024, truncateString(string(decodedBody), 1000))
-			} else if m.configManager == nil || m.configManager.Config.AutoFormatJSON {
-				if strings.Contains(contentType, "application/json") {
-					var prettyJSON bytes.Buffer
-					if err := json.Indent(&prettyJSON, decodedBody, "", "  "); err != nil {
-						formattedBody = "Error formatting JSON: " + err.Error() + "\nRaw response:\n" + string(decodedBody)
-					} else {
-						formattedBody = prettyJSON.String()
-					}
-				} else if strings.Contains(contentType, "text/html") {
-					formattedBody = "HTML Response:\n" + truncateString(string(decodedBody), 1000)
-				}
-			}
+	if m.configManager != nil && m.configManager.Config.AdvertiseCompression && sendHeaders.Get("Accept-Encoding") == "" {
+		sendHeaders.Set("Accept-Encoding", acceptEncodingValue)
+	}
 
-			if m.configManager != nil && m.configManager.Config.SaveHistory {
-				go func() {
-					reqItem := RequestItem{
-						URL:     url,
-						Method:  method,
-						Headers: headers,
-						Body:    m.bodyInput.Value(),
-					}
-					_ = m.configManager.addToHistory(reqItem)
-				}()
-			}
-
-			response := Response{
-				StatusCode:    resp.StatusCode,
-				Status:        resp.Status,
-				Headers:       resp.Header,
-				Body:          string(respBody),
-				FormattedBody: formattedBody,
-				ResponseTime:  responseTime,
-				ContentLength: contentLength,
-			}
-			resultChan <- response
-		}()
+	// Add default User-Agent if not set
+	if sendHeaders.Get("User-Agent") == "" {
+		sendHeaders.Set("User-Agent", "api-client-tui/1.0")
+	}
 
-		select {
-		case res := <-resultChan:
-			return res
-		case <-time.After(timeout + 1*time.Second):
-			return Response{
-				Error:        fmt.Errorf("forced timeout: request took longer than %v", timeout),
-				ResponseTime: time.Since(startTime),
-			}
-		}
+	follow, maxRedirects := m.configManager.redirectPolicy()
+	spec := RequestSpec{
+		Method:          method,
+		URL:             url,
+		Headers:         sendHeaders,
+		Body:            body,
+		HasBody:         sendsBody(method, m.configManager.allowGetBody(reqItem.AllowGetBody)),
+		Timeout:         timeout,
+		FollowRedirects: follow,
+		MaxRedirects:    maxRedirects,
+		AutoFormat:      m.configManager == nil || m.configManager.Config.AutoFormatJSON,
+		MaxBytes:        m.configManager.maxResponseBytes(),
 	}
+	return spec, nil
 }
 
 func (m Model) formatResponse() string {
+	if m.wsFrames != nil {
+		return formatWebSocketLog(&m.styles, m.wsFrames, m.ws != nil)
+	}
+	if m.previewView != "" {
+		return m.previewView
+	}
+	if m.repeatReport != "" {
+		return m.repeatReport
+	}
 	if m.response.Error != nil {
 		var sb strings.Builder
-		sb.WriteString(errorStyle.Render("Error: " + m.response.Error.Error()))
+		sb.WriteString(m.styles.error.Render(errorText(m.response.Error)))
 		
 		if m.response.StatusCode > 0 {
 			sb.WriteString(fmt.Sprintf("\nStatus: %d - %s", m.response.StatusCode, http.StatusText(m.response.StatusCode)))
@@ -698,28 +3002,101 @@ func (m Model) formatResponse() string {
 		return sb.String()
 	}
 
+	if m.showRaw && m.response.StatusCode > 0 {
+		return formatRawExchange(&m.styles, m.response)
+	}
+
 	var sb strings.Builder
 
-	statusStyle := statusSuccessStyle
-	if m.response.StatusCode >= 400 {
-		statusStyle = statusErrorStyle
+	if m.response.Insecure {
+		sb.WriteString(m.styles.insecureBanner.Render("⚠ TLS certificate NOT verified (insecure_skip_verify is on)") + "\n")
+	}
+
+	statusLine := fmt.Sprintf("Status: %d %s", m.response.StatusCode, statusReason(m.response.StatusCode, m.response.Status))
+	if size := formatSize(m.response); size != "" {
+		statusLine += " (" + size + ")"
+	}
+	sb.WriteString(statusStyleFor(&m.styles, m.response.StatusCode).Render(statusLine) + "\n")
+	if m.response.Summary != "" {
+		sb.WriteString(m.styles.summary.Render("Summary: "+m.response.Summary) + "\n")
+	}
+	if m.showMetadata {
+		sb.WriteString(formatMetadata(m.response))
+	} else if phases := formatTimingsInline(m.response.Timings); phases != "" {
+		sb.WriteString(fmt.Sprintf("Time: %v (%s)\n", m.response.ResponseTime, phases))
+	} else {
+		sb.WriteString(fmt.Sprintf("Time: %v\n", m.response.ResponseTime))
+	}
+	sb.WriteString(m.formatLatencyTrend())
+	sb.WriteString("\n")
+
+	if m.response.Sent.Method == http.MethodOptions {
+		sb.WriteString(formatCORS(&m.styles, m.response.Headers))
+		sb.WriteString("\n")
+	}
+
+	if m.response.Schema != nil {
+		sb.WriteString(formatSchemaResult(&m.styles, m.response.Schema))
+		sb.WriteString("\n")
+	}
+
+	if len(m.response.Informational) > 0 && (m.configManager == nil || m.configManager.Config.ShowInformational) {
+		sb.WriteString("Informational:\n")
+		sb.WriteString(formatInformational(m.response.Informational))
+		sb.WriteString("\n")
 	}
 
-	statusLine := fmt.Sprintf("Status: %d - %s", m.response.StatusCode, m.response.Status)
-	if m.response.ContentLength > 0 {
-		statusLine += fmt.Sprintf(" (%.1f KB)", float64(m.response.ContentLength)/1024)
+	if len(m.response.Redirects) > 0 {
+		sb.WriteString("Redirects:\n")
+		sb.WriteString(formatRedirects(m.response.Redirects))
+		sb.WriteString("\n")
 	}
-	sb.WriteString(statusStyle.Render(statusLine + "\n"))
-	sb.WriteString(fmt.Sprintf("Time: %v\n\n", m.response.ResponseTime))
 
-	sb.WriteString("Headers:\n")
-	for k, v := range m.response.Headers {
-		sb.WriteString(fmt.Sprintf("%s: %s\n", k, strings.Join(v, ", ")))
+	if m.foldHeaders {
+		sb.WriteString(m.styles.help.Render(fmt.Sprintf("Headers: %d folded (f to show)", len(m.response.Headers))) + "\n")
+	} else {
+		sb.WriteString("Headers:\n")
+		sb.WriteString(formatHeaders(m.response.Headers))
 	}
 	sb.WriteString("\n")
 
+	if m.response.Request.GraphQL {
+		// GraphQL reports errors with a 200, so they're easy to miss
+		if errs := formatGraphQLErrors(&m.styles, m.response.Body); errs != "" {
+			sb.WriteString(errs + "\n")
+		}
+	}
+
+	if m.showDiff {
+		sb.WriteString(formatDiff(&m.styles, m.prevResponse, m.response))
+		return sb.String()
+	}
+	if m.response.Events != nil || m.stream != nil {
+		sb.WriteString(formatEvents(m.response.Events, m.response.StreamEnd))
+		return sb.String()
+	}
+	if m.response.Sent.Method == http.MethodHead {
+		sb.WriteString(m.styles.help.Render("No body (HEAD request)"))
+		return sb.String()
+	}
+	switch m.response.StatusCode {
+	case http.StatusNoContent:
+		sb.WriteString(m.styles.help.Render("No content (204)"))
+		return sb.String()
+	case http.StatusNotModified:
+		sb.WriteString(m.styles.help.Render("Not modified (304)"))
+		return sb.String()
+	}
+	if m.response.Body == "" {
+		sb.WriteString(m.styles.help.Render("No body"))
+		return sb.String()
+	}
+
 	sb.WriteString("Body:\n")
-	sb.WriteString(m.response.FormattedBody)
+	sb.WriteString(m.displayBody)
+	if m.showMetadata {
+		sb.WriteString("\n\n" + m.styles.help.Render(m.configManager.formatLimits()))
+	}
 
 	return sb.String()
 }
@@ -729,104 +3106,148 @@ func (m Model) View() string {
 		return "Initializing..."
 	}
 
-	header := headerStyle.Render("API Client TUI")
+	header := m.styles.header.Render("API Client TUI")
+	if m.configManager != nil {
+		// Protected environments, such as prod, stand out in red
+		if env := m.configManager.getCurrentEnvironment(); env.Name != "" {
+			style := m.styles.envBadge
+			if m.configManager.isProtectedEnv(env.Name) {
+				style = m.styles.protectedEnv
+			}
+			header += " " + style.Render("env: "+env.Name)
+		}
+	}
+	if m.recording {
+		header += " " + m.styles.error.Render("● REC")
+	}
+	if tabBar := m.renderTabBar(); tabBar != "" {
+		header += " " + tabBar
+	}
 
-	methodStyle := methodPanelStyle.Copy().
+	methodStyle := m.styles.methodPanel.Copy().
 		MarginRight(2).
-		BorderForeground(primaryColor)
+		BorderForeground(m.styles.Primary)
 	if m.activePanel == methodPanel {
-		methodStyle = methodStyle.BorderForeground(accentColor)
+		methodStyle = methodStyle.BorderForeground(m.styles.Accent)
 	}
 	methodView := methodStyle.Render(m.methodList.View())
 
-	urlStyle := blurredStyle
+	urlStyle := m.styles.blurred
 	if m.activePanel == urlPanel {
-		urlStyle = focusedStyle
+		urlStyle = m.styles.focused
+	}
+	urlContent := m.urlInput.View()
+	if m.urlInput.Value() != "" {
+		if effective := m.effectiveURL(m.currentRequest()); effective != m.urlInput.Value() {
+			urlContent += "\n" + m.styles.help.Render("→ "+effective)
+		}
 	}
-	urlView := urlStyle.Render(fmt.Sprintf("%s\n%s", "URL", m.urlInput.View()))
+	urlView := urlStyle.Render(fmt.Sprintf("%s\n%s", "URL", urlContent))
 
-	headersStyle := blurredStyle
+	headersStyle := m.styles.blurred
 	if m.activePanel == headersPanel {
-		headersStyle = focusedStyle
+		headersStyle = m.styles.focused
+	}
+	headersTitle := "Headers"
+	if label := m.auth.label(); label != "" {
+		headersTitle += " (Auth: " + label + ")"
 	}
-	headersView := headersStyle.Render(fmt.Sprintf("%s\n%s", "Headers", m.headersInput.View()))
+	headersView := headersStyle.Render(fmt.Sprintf("%s\n%s", headersTitle, m.headersInput.View()))
 
-	bodyStyle := blurredStyle
+	bodyStyle := m.styles.blurred
 	if m.activePanel == bodyPanel {
-		bodyStyle = focusedStyle
+		bodyStyle = m.styles.focused
 	}
-	bodyView := bodyStyle.Render(fmt.Sprintf("%s\n%s", "Body", m.bodyInput.View()))
+	bodyTitle := "Body"
+	if m.graphQL {
+		bodyTitle = "GraphQL Query"
+	}
+	bodyView := bodyStyle.Render(fmt.Sprintf("%s\n%s", bodyTitle, m.bodyInput.View()))
+	if m.graphQL {
+		variablesStyle := m.styles.blurred
+		if m.activePanel == variablesPanel {
+			variablesStyle = m.styles.focused
+		}
+		bodyView = lipgloss.JoinHorizontal(lipgloss.Top, bodyView,
+			variablesStyle.Render(fmt.Sprintf("%s\n%s", "Variables", m.variablesInput.View())))
+	}
+
+	queryStyle := m.styles.blurred
+	if m.activePanel == queryPanel {
+		queryStyle = m.styles.focused
+	}
+	queryView := queryStyle.Render(fmt.Sprintf("%s\n%s", "Query", m.queryInput.View()))
 
 	responseContent := "No response yet"
-	if m.loading {
-		responseContent = fmt.Sprintf("%s Sending request...", m.spinner.View())
-	} else if m.response.StatusCode > 0 || m.response.Error != nil {
+	if m.loading && m.stream == nil {
+		responseContent = fmt.Sprintf("%s Sending request... (esc to cancel)", m.spinner.View())
+		if m.repeating {
+			responseContent = fmt.Sprintf("%s Sending %d requests... (esc to cancel)", m.spinner.View(), m.repeatCount)
+		}
+	} else if m.response.StatusCode > 0 || m.response.Error != nil || m.wsFrames != nil {
 		responseContent = m.responseView.View()
 	}
-	responseStyle := blurredStyle
+	responseStyle := m.styles.blurred
 	if m.activePanel == responsePanel {
-		responseStyle = focusedStyle
+		responseStyle = m.styles.focused
+	}
+	responseTitle := "Response"
+	if position := m.scrollPosition(); position != "" && (!m.loading || m.stream != nil) {
+		responseTitle += " " + m.styles.help.Render(position)
 	}
-	responseView := responseStyle.Render(fmt.Sprintf("%s\n%s", "Response", responseContent))
+	responseView := responseStyle.Render(fmt.Sprintf("%s\n%s", responseTitle, responseContent))
 
 	topRow := lipgloss.JoinVertical(lipgloss.Left,
 		methodView,
 		urlView)
 
-	middleRow := lipgloss.JoinHorizontal(lipgloss.Top, headersView, bodyView)
+	middleRow := lipgloss.JoinHorizontal(lipgloss.Top, queryView, headersView, bodyView)
 
 	historyPanel := ""
 	if m.showHistory && m.configManager != nil {
 		historyContent := "No history items"
-		if len(m.configManager.History) > 0 {
-			var sb strings.Builder
-			sb.WriteString("Recent Requests:\n")
-			for i, item := range m.configManager.History {
-				if i >= 10 { // Show only 10 most recent items
-					break
-				}
-				sb.WriteString(fmt.Sprintf("%d. %s %s\n", i+1, item.Method, item.URL))
+		if len(m.historyList.Items()) > 0 {
+			historyContent = m.historyList.View()
+			if selected, ok := m.historyList.SelectedItem().(historyEntry); ok {
+				historyContent += "\n" + formatHistoryDetail(selected.RequestItem)
 			}
-			historyContent = sb.String()
 		}
 		historyPanel = lipgloss.NewStyle().
 			BorderStyle(lipgloss.RoundedBorder()).
-			BorderForeground(primaryColor).
+			BorderForeground(m.styles.Primary).
 			Width(m.width - 4).
 			Render(historyContent)
 	}
 
 	envsPanel := ""
-	if m.showEnvs && m.configManager != nil {
-		envsContent := "No environments configured"
-		if len(m.configManager.Environments) > 0 {
-			var sb strings.Builder
-			sb.WriteString("Environments:\n")
-
-			currentEnv := m.configManager.getCurrentEnvironment()
-			sb.WriteString(fmt.Sprintf("Current: %s\n\n", currentEnv.Name))
-
-			sb.WriteString("Variables:\n")
-			for k, v := range currentEnv.Variables {
-				sb.WriteString(fmt.Sprintf("%s: %s\n", k, v))
-			}
-			envsContent = sb.String()
-		}
+	if m.showEnvs {
 		envsPanel = lipgloss.NewStyle().
 			BorderStyle(lipgloss.RoundedBorder()).
-			BorderForeground(primaryColor).
+			BorderForeground(m.styles.Primary).
+			Width(m.width - 4).
+			Render(m.renderEnvs())
+	}
+
+	bookmarksPanel := ""
+	if m.showBookmarks {
+		bookmarksPanel = lipgloss.NewStyle().
+			BorderStyle(lipgloss.RoundedBorder()).
+			BorderForeground(m.styles.Primary).
 			Width(m.width - 4).
-			Render(envsContent)
+			Render(bookmarksReport(m.bookmarks, m.formatResponse()))
 	}
 
 	help := ""
 	if m.showHelp {
-		help = helpStyle.Render("\nTab: Next panel • Shift+Tab: Previous panel • Enter: Send request • Ctrl+h: History • Ctrl+e: Environments • Ctrl+s: Save • q: Quit • ?: Toggle help")
+		help = m.styles.help.Render("\n" + keys.helpLine())
 	} else {
-		help = helpStyle.Render("\nPress ? for help")
+		help = m.styles.help.Render("\nPress ? for help")
 	}
 
 	view := fmt.Sprintf("%s\n%s\n%s\n%s", header, topRow, middleRow, responseView)
+	if m.reviewMode {
+		view = fmt.Sprintf("%s\n%s", header, m.renderReview())
+	}
 
 	if m.showHistory {
 		view += "\n" + historyPanel
@@ -836,6 +3257,104 @@ func (m Model) View() string {
 		view += "\n" + envsPanel
 	}
 
+	if m.showAuth {
+		view += "\n" + lipgloss.NewStyle().
+			BorderStyle(lipgloss.RoundedBorder()).
+			BorderForeground(m.styles.Primary).
+			Width(m.width - 4).
+			Render(m.renderAuth())
+	}
+
+	if m.showBookmarks {
+		view += "\n" + bookmarksPanel
+	}
+
+	if m.showDashboard {
+		view += "\n" + lipgloss.NewStyle().
+			BorderStyle(lipgloss.RoundedBorder()).
+			BorderForeground(m.styles.Primary).
+			Width(m.width - 4).
+			Render(renderDashboard(&m.styles, m.dashboard, m.width-6))
+	}
+
+	if m.showJWTs {
+		view += "\n" + lipgloss.NewStyle().
+			BorderStyle(lipgloss.RoundedBorder()).
+			BorderForeground(m.styles.Primary).
+			Width(m.width - 4).
+			Render(renderJWTs(m.jwts, m.jwtCursor))
+	}
+
+	if m.showConns {
+		view += "\n" + lipgloss.NewStyle().
+			BorderStyle(lipgloss.RoundedBorder()).
+			BorderForeground(m.styles.Primary).
+			Width(m.width - 4).
+			Render(m.configManager.renderConnStats())
+	}
+
+	if m.showCookies {
+		view += "\n" + lipgloss.NewStyle().
+			BorderStyle(lipgloss.RoundedBorder()).
+			BorderForeground(m.styles.Primary).
+			Width(m.width - 4).
+			Render(m.renderCookies())
+	}
+
+	if m.showSaved {
+		view += "\n" + lipgloss.NewStyle().
+			BorderStyle(lipgloss.RoundedBorder()).
+			BorderForeground(m.styles.Primary).
+			Width(m.width - 4).
+			Render(m.savedList.View())
+	}
+
+	if m.showCollections {
+		view += "\n" + lipgloss.NewStyle().
+			BorderStyle(lipgloss.RoundedBorder()).
+			BorderForeground(m.styles.Primary).
+			Width(m.width - 4).
+			Render(m.collectionList.View())
+	}
+
+	if m.showTimeline {
+		view += "\n" + lipgloss.NewStyle().
+			BorderStyle(lipgloss.RoundedBorder()).
+			BorderForeground(m.styles.Primary).
+			Width(m.width - 4).
+			Render(m.timelineList.View())
+	}
+
+	if m.showPalette {
+		view += "\n" + lipgloss.NewStyle().
+			BorderStyle(lipgloss.RoundedBorder()).
+			BorderForeground(m.styles.Primary).
+			Width(m.width - 4).
+			Render(m.paletteList.View())
+	}
+
+	if m.showPaths {
+		view += "\n" + lipgloss.NewStyle().
+			BorderStyle(lipgloss.RoundedBorder()).
+			BorderForeground(m.styles.Primary).
+			Width(m.width - 4).
+			Render(m.pathList.View())
+	}
+
+	if m.promptKind != promptNone {
+		view += "\n" + m.styles.prompt.Render(m.prompt.View())
+	}
+
+	if m.confirmKind != confirmNone {
+		view += "\n" + m.styles.confirm.Render(m.confirmText+" [y/N]")
+	}
+
+	if m.requestError != nil {
+		view += "\n" + m.styles.error.Render(errorText(m.requestError))
+	} else if m.statusMessage != "" {
+		view += "\n" + m.styles.statusSuccess.Render(m.statusMessage)
+	}
+
 	view += help
 
 	return view
@@ -875,7 +3394,116 @@ func parseHeaders(input string) map[string]string {
 	return headers
 }
 
+// applyOverride returns a copy of req with one field replaced. The override
+// is either "body=<new body>" or a "Name: value" header line.
+func applyOverride(req RequestItem, override string) (RequestItem, error) {
+	override = strings.TrimSpace(override)
+	if override == "" {
+		return req, nil
+	}
+
+	if body, ok := strings.CutPrefix(override, "body="); ok {
+		req.Body = body
+		return req, nil
+	}
+
+	name, value, ok := strings.Cut(override, ":")
+	name = strings.TrimSpace(name)
+	if !ok || name == "" {
+		return req, fmt.Errorf("invalid override %q: use \"Header: value\" or \"body=...\"", override)
+	}
+
+	headers := make(map[string]string, len(req.Headers)+1)
+	for k, v := range req.Headers {
+		if !strings.EqualFold(k, name) {
+			headers[k] = v
+		}
+	}
+	headers[name] = strings.TrimSpace(value)
+	req.Headers = headers
+	return req, nil
+}
+
 func main() {
+	configDirFlag := flag.String("config-dir", "", "directory for config, history, collections and environments (overrides $"+configDirEnvVar+")")
+	exportBundle := flag.String("export-bundle", "", "write config, history, collections and environments to `file` and exit")
+	importBundle := flag.String("import-bundle", "", "load a bundle from `file` and exit")
+	mergeBundle := flag.Bool("merge", false, "with -import-bundle, merge into the existing data instead of replacing it")
+	importOpenAPI := flag.String("import-openapi", "", "add a request per operation in an OpenAPI or Swagger spec `file` to a collection and exit")
+	importPostman := flag.String("import-postman", "", "add the requests in a Postman v2.1 collection `file` to a collection and exit")
+	exportCollection := flag.String("export-collection", "", "write the collection called `name` to a file (see -out) and exit")
+	exportOut := flag.String("out", "", "with -export-collection, the `file` to write (default <name>.json)")
+	importCollection := flag.String("import-collection", "", "add a collection exported with -export-collection from `file` and exit")
+	flag.Parse()
+
+	configDir, err := resolveConfigDir(*configDirFlag)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if *exportBundle != "" || *importBundle != "" || *importOpenAPI != "" || *importPostman != "" ||
+		*exportCollection != "" || *importCollection != "" {
+		cm, err := NewConfigManager(configDir)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if *exportBundle != "" {
+			if err := cm.ExportBundle(*exportBundle); err != nil {
+				fmt.Fprintf(os.Stderr, "Error exporting bundle: %v\n", err)
+				os.Exit(1)
+			}
+			fmt.Println("Exported bundle to", *exportBundle)
+		}
+		if *importBundle != "" {
+			if err := cm.ImportBundle(*importBundle, *mergeBundle); err != nil {
+				fmt.Fprintf(os.Stderr, "Error importing bundle: %v\n", err)
+				os.Exit(1)
+			}
+			fmt.Println("Imported bundle from", *importBundle)
+		}
+		if *importOpenAPI != "" {
+			name, err := cm.ImportOpenAPI(*importOpenAPI)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error importing OpenAPI spec: %v\n", err)
+				os.Exit(1)
+			}
+			fmt.Printf("Imported %s into collection %q\n", *importOpenAPI, name)
+		}
+		if *importPostman != "" {
+			name, notes, err := cm.importPostman(*importPostman)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error importing Postman collection: %v\n", err)
+				os.Exit(1)
+			}
+			for _, note := range notes {
+				fmt.Fprintln(os.Stderr, "Note:", note)
+			}
+			fmt.Printf("Imported %s into collection %q\n", *importPostman, name)
+		}
+		if *exportCollection != "" {
+			out := *exportOut
+			if out == "" {
+				out = *exportCollection + ".json"
+			}
+			if err := cm.ExportCollection(*exportCollection, out); err != nil {
+				fmt.Fprintf(os.Stderr, "Error exporting collection: %v\n", err)
+				os.Exit(1)
+			}
+			fmt.Printf("Exported collection %q to %s\n", *exportCollection, out)
+		}
+		if *importCollection != "" {
+			name, err := cm.importCollection(*importCollection)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error importing collection: %v\n", err)
+				os.Exit(1)
+			}
+			fmt.Printf("Imported %s as collection %q\n", *importCollection, name)
+		}
+		return
+	}
+
 	stat, _ := os.Stdin.Stat()
 	if (stat.Mode() & os.ModeCharDevice) == 0 {
 		input, err := io.ReadAll(os.Stdin)
@@ -889,19 +3517,30 @@ func main() {
 			os.Exit(0)
 		}
 
-		model := initialModel()
-		model.bodyInput.SetValue(string(input))
-
-		p := tea.NewProgram(model, tea.WithAltScreen())
-		if _, err := p.Run(); err != nil {
-			fmt.Println("Error running program:", err)
-			os.Exit(1)
+		model := initialModel(configDir)
+		if looksLikeCurl(string(input)) {
+			model.importCurl(string(input))
+		} else {
+			model.bodyInput.SetValue(string(input))
 		}
+
+		runProgram(model)
 	} else {
-		p := tea.NewProgram(initialModel(), tea.WithAltScreen())
-		if _, err := p.Run(); err != nil {
-			fmt.Println("Error running program:", err)
-			os.Exit(1)
-		}
+		runProgram(initialModel(configDir))
+	}
+}
+
+// runProgram runs the TUI until it quits, then reports anything that went
+// wrong on the way out, which the alternate screen would have hidden.
+func runProgram(model Model) {
+	p := tea.NewProgram(model, tea.WithAltScreen())
+	final, err := p.Run()
+	if err != nil {
+		fmt.Println("Error running program:", err)
+		os.Exit(1)
+	}
+	if m, ok := final.(Model); ok && m.quitErr != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", m.quitErr)
+		os.Exit(1)
 	}
 }
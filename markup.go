@@ -0,0 +1,160 @@
+package main
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// isXMLContentType reports whether contentType is XML or an XML-based type
+// such as application/atom+xml.
+func isXMLContentType(contentType string) bool {
+	mediaType := strings.ToLower(strings.TrimSpace(strings.Split(contentType, ";")[0]))
+	return mediaType == "application/xml" || mediaType == "text/xml" || strings.HasSuffix(mediaType, "+xml")
+}
+
+// isYAMLContentType reports whether contentType is YAML under any of its
+// common names.
+func isYAMLContentType(contentType string) bool {
+	mediaType := strings.ToLower(strings.TrimSpace(strings.Split(contentType, ";")[0]))
+	switch mediaType {
+	case "application/yaml", "application/x-yaml", "text/yaml", "text/x-yaml":
+		return true
+	}
+	return strings.HasSuffix(mediaType, "+yaml")
+}
+
+// formatXML re-indents an XML document. Elements holding only text stay on
+// one line, and namespace prefixes are kept as written. A blank body is
+// returned as is.
+func formatXML(data []byte, indent string) (string, error) {
+	if len(bytes.TrimSpace(data)) == 0 {
+		return string(data), nil
+	}
+
+	// The first pass checks the document is well-formed, which RawToken
+	// doesn't do
+	check := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		if _, err := check.Token(); err == io.EOF {
+			break
+		} else if err != nil {
+			return "", err
+		}
+	}
+
+	var tokens []xml.Token
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		tok, err := dec.RawToken()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return "", err
+		}
+		if text, ok := tok.(xml.CharData); ok && len(bytes.TrimSpace(text)) == 0 {
+			continue
+		}
+		tokens = append(tokens, xml.CopyToken(tok))
+	}
+
+	var sb strings.Builder
+	depth := 0
+	line := func(s string) {
+		sb.WriteString(strings.Repeat(indent, depth) + s + "\n")
+	}
+	for i := 0; i < len(tokens); i++ {
+		switch t := tokens[i].(type) {
+		case xml.StartElement:
+			open := "<" + xmlName(t.Name) + xmlAttrs(t.Attr)
+			if i+1 < len(tokens) {
+				if _, ok := tokens[i+1].(xml.EndElement); ok {
+					line(open + "/>")
+					i++
+					continue
+				}
+			}
+			if i+2 < len(tokens) {
+				text, isText := tokens[i+1].(xml.CharData)
+				end, isEnd := tokens[i+2].(xml.EndElement)
+				if isText && isEnd {
+					line(open + ">" + xmlEscape(strings.TrimSpace(string(text))) + "</" + xmlName(end.Name) + ">")
+					i += 2
+					continue
+				}
+			}
+			line(open + ">")
+			depth++
+
+		case xml.EndElement:
+			depth--
+			line("</" + xmlName(t.Name) + ">")
+
+		case xml.CharData:
+			line(xmlEscape(strings.TrimSpace(string(t))))
+
+		case xml.Comment:
+			line("<!--" + string(t) + "-->")
+
+		case xml.ProcInst:
+			line("<?" + t.Target + " " + string(t.Inst) + "?>")
+
+		case xml.Directive:
+			line("<!" + string(t) + ">")
+		}
+	}
+	return strings.TrimSuffix(sb.String(), "\n"), nil
+}
+
+func xmlName(n xml.Name) string {
+	if n.Space != "" {
+		return n.Space + ":" + n.Local
+	}
+	return n.Local
+}
+
+func xmlAttrs(attrs []xml.Attr) string {
+	var sb strings.Builder
+	for _, a := range attrs {
+		sb.WriteString(" " + xmlName(a.Name) + `="` + xmlEscape(a.Value) + `"`)
+	}
+	return sb.String()
+}
+
+func xmlEscape(s string) string {
+	var buf bytes.Buffer
+	_ = xml.EscapeText(&buf, []byte(s))
+	return buf.String()
+}
+
+// formatYAML re-indents YAML, keeping key order and comments. Multiple
+// documents are kept apart with ---. A blank body is returned as is.
+func formatYAML(data []byte, indent int) (string, error) {
+	if len(bytes.TrimSpace(data)) == 0 {
+		return string(data), nil
+	}
+
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	var out bytes.Buffer
+	enc := yaml.NewEncoder(&out)
+	enc.SetIndent(indent)
+
+	for {
+		var node yaml.Node
+		if err := dec.Decode(&node); err == io.EOF {
+			break
+		} else if err != nil {
+			return "", err
+		}
+		if err := enc.Encode(&node); err != nil {
+			return "", err
+		}
+	}
+	if err := enc.Close(); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(out.String(), "\n"), nil
+}
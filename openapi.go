@@ -0,0 +1,422 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// openAPIMethods are the operations a path item can hold, in the order
+// they're imported.
+var openAPIMethods = []string{"get", "post", "put", "patch", "delete", "head", "options", "trace"}
+
+var openAPIPathParamPattern = regexp.MustCompile(`\{([^{}/]+)\}`)
+
+// openAPISpec is the subset of an OpenAPI 3 or Swagger 2 document needed
+// to build requests. YAML and JSON specs both decode into it.
+type openAPISpec struct {
+	Info struct {
+		Title string `yaml:"title"`
+	} `yaml:"info"`
+	Servers []struct {
+		URL       string `yaml:"url"`
+		Variables map[string]struct {
+			Default string `yaml:"default"`
+		} `yaml:"variables"`
+	} `yaml:"servers"`
+	Paths      map[string]map[string]yaml.Node `yaml:"paths"`
+	Components struct {
+		Schemas       map[string]*openAPISchema     `yaml:"schemas"`
+		Parameters    map[string]openAPIParameter   `yaml:"parameters"`
+		RequestBodies map[string]openAPIRequestBody `yaml:"requestBodies"`
+	} `yaml:"components"`
+
+	// Swagger 2
+	Host        string                      `yaml:"host"`
+	BasePath    string                      `yaml:"basePath"`
+	Schemes     []string                    `yaml:"schemes"`
+	Definitions map[string]*openAPISchema   `yaml:"definitions"`
+	Parameters  map[string]openAPIParameter `yaml:"parameters"`
+}
+
+type openAPIOperation struct {
+	OperationID string              `yaml:"operationId"`
+	Summary     string              `yaml:"summary"`
+	Tags        []string            `yaml:"tags"`
+	Parameters  []openAPIParameter  `yaml:"parameters"`
+	RequestBody *openAPIRequestBody `yaml:"requestBody"`
+	Consumes    []string            `yaml:"consumes"`
+}
+
+type openAPIParameter struct {
+	Ref    string         `yaml:"$ref"`
+	Name   string         `yaml:"name"`
+	In     string         `yaml:"in"`
+	Schema *openAPISchema `yaml:"schema"`
+}
+
+type openAPIRequestBody struct {
+	Ref     string                      `yaml:"$ref"`
+	Content map[string]openAPIMediaType `yaml:"content"`
+}
+
+type openAPIMediaType struct {
+	Schema   *openAPISchema `yaml:"schema"`
+	Example  interface{}    `yaml:"example"`
+	Examples map[string]struct {
+		Value interface{} `yaml:"value"`
+	} `yaml:"examples"`
+}
+
+type openAPISchema struct {
+	Ref        string                    `yaml:"$ref"`
+	Type       interface{}               `yaml:"type"` // A list of types in OpenAPI 3.1
+	Example    interface{}               `yaml:"example"`
+	Default    interface{}               `yaml:"default"`
+	Enum       []interface{}             `yaml:"enum"`
+	Properties map[string]*openAPISchema `yaml:"properties"`
+	Items      *openAPISchema            `yaml:"items"`
+	AllOf      []*openAPISchema          `yaml:"allOf"`
+	OneOf      []*openAPISchema          `yaml:"oneOf"`
+	AnyOf      []*openAPISchema          `yaml:"anyOf"`
+}
+
+// ImportOpenAPI reads an OpenAPI 3 (or Swagger 2) spec in JSON or YAML and
+// adds one request per operation to a collection named after the API
+// title, which is returned. Path, query and header parameters become
+// {{name}} placeholders, and request bodies are filled from the spec's
+// examples or, failing that, a skeleton built from the schema. Importing
+// the same spec again updates the requests in place.
+func (cm *ConfigManager) ImportOpenAPI(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	var spec openAPISpec
+	if err := yaml.Unmarshal(data, &spec); err != nil {
+		return "", fmt.Errorf("invalid OpenAPI spec: %w", err)
+	}
+	if len(spec.Paths) == 0 {
+		return "", fmt.Errorf("invalid OpenAPI spec: no paths")
+	}
+
+	name := strings.TrimSpace(spec.Info.Title)
+	if name == "" {
+		name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+
+	requests, err := spec.requests(time.Now())
+	if err != nil {
+		return "", err
+	}
+
+	cm.mu.Lock()
+	collection, ok := cm.Collections[name]
+	if !ok {
+		collection = Collection{Name: name, Requests: []RequestItem{}}
+	}
+	for _, req := range requests {
+		collection.Requests = mergeRequest(collection.Requests, req)
+	}
+	cm.Collections[name] = collection
+	cm.mu.Unlock()
+
+	return name, cm.saveCollections()
+}
+
+// baseURL is the first server's URL with its variables set to their
+// defaults, or {{BASE_URL}} when the spec names no absolute server.
+func (s *openAPISpec) baseURL() string {
+	if len(s.Servers) > 0 {
+		base := s.Servers[0].URL
+		for name, v := range s.Servers[0].Variables {
+			base = strings.ReplaceAll(base, "{"+name+"}", v.Default)
+		}
+		if strings.Contains(base, "://") {
+			return strings.TrimSuffix(base, "/")
+		}
+		return "{{BASE_URL}}" + strings.TrimSuffix(base, "/")
+	}
+	if s.Host != "" {
+		scheme := "https"
+		if len(s.Schemes) > 0 {
+			scheme = s.Schemes[0]
+		}
+		return scheme + "://" + s.Host + strings.TrimSuffix(s.BasePath, "/")
+	}
+	return "{{BASE_URL}}" + strings.TrimSuffix(s.BasePath, "/")
+}
+
+// requests builds a RequestItem per operation, ordered by path and then
+// method.
+func (s *openAPISpec) requests(now time.Time) ([]RequestItem, error) {
+	base := s.baseURL()
+
+	paths := make([]string, 0, len(s.Paths))
+	for p := range s.Paths {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	var requests []RequestItem
+	for _, p := range paths {
+		item := s.Paths[p]
+
+		var shared []openAPIParameter
+		if node, ok := item["parameters"]; ok {
+			if err := node.Decode(&shared); err != nil {
+				return nil, fmt.Errorf("invalid parameters for %s: %w", p, err)
+			}
+		}
+
+		for _, method := range openAPIMethods {
+			node, ok := item[method]
+			if !ok {
+				continue
+			}
+			var op openAPIOperation
+			if err := node.Decode(&op); err != nil {
+				return nil, fmt.Errorf("invalid operation %s %s: %w", strings.ToUpper(method), p, err)
+			}
+
+			req := s.request(base, p, strings.ToUpper(method), shared, op)
+			req.ID = fmt.Sprintf("%d", now.UnixNano()+int64(len(requests)))
+			req.CreatedAt = now
+			requests = append(requests, req)
+		}
+	}
+	return requests, nil
+}
+
+func (s *openAPISpec) request(base, path, method string, shared []openAPIParameter, op openAPIOperation) RequestItem {
+	req := RequestItem{
+		Name:    op.Summary,
+		URL:     base + openAPIPathParamPattern.ReplaceAllString(path, "{{$1}}"),
+		Method:  method,
+		Headers: map[string]string{},
+		Tags:    op.Tags,
+	}
+	if req.Name == "" {
+		req.Name = op.OperationID
+	}
+	if req.Name == "" {
+		req.Name = method + " " + path
+	}
+
+	// Operation parameters override path-level ones with the same name
+	// and location
+	params := make(map[string]openAPIParameter)
+	var order []string
+	for _, p := range append(append([]openAPIParameter{}, shared...), op.Parameters...) {
+		p = s.resolveParameter(p)
+		k := p.In + "\x00" + p.Name
+		if _, ok := params[k]; !ok {
+			order = append(order, k)
+		}
+		params[k] = p
+	}
+
+	for _, k := range order {
+		p := params[k]
+		switch p.In {
+		case "query":
+			if req.Query == nil {
+				req.Query = url.Values{}
+			}
+			req.Query.Set(p.Name, "{{"+p.Name+"}}")
+		case "header":
+			req.Headers[p.Name] = "{{" + p.Name + "}}"
+		case "body":
+			req.Body = s.exampleJSON(p.Schema)
+			req.Headers["Content-Type"] = "application/json"
+			if len(op.Consumes) > 0 {
+				req.Headers["Content-Type"] = op.Consumes[0]
+			}
+		}
+	}
+
+	if op.RequestBody != nil {
+		body := s.resolveRequestBody(*op.RequestBody)
+		if mediaType, media, ok := pickMediaType(body.Content); ok {
+			req.Headers["Content-Type"] = mediaType
+			req.Body = s.mediaExample(media)
+		}
+	}
+	return req
+}
+
+// pickMediaType prefers JSON and otherwise takes the first media type by
+// name.
+func pickMediaType(content map[string]openAPIMediaType) (string, openAPIMediaType, bool) {
+	types := make([]string, 0, len(content))
+	for t := range content {
+		if isJSONContentType(t) {
+			return t, content[t], true
+		}
+		types = append(types, t)
+	}
+	if len(types) == 0 {
+		return "", openAPIMediaType{}, false
+	}
+	sort.Strings(types)
+	return types[0], content[types[0]], true
+}
+
+func (s *openAPISpec) mediaExample(media openAPIMediaType) string {
+	if media.Example != nil {
+		return encodeExample(media.Example)
+	}
+	names := make([]string, 0, len(media.Examples))
+	for n := range media.Examples {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	for _, n := range names {
+		if v := media.Examples[n].Value; v != nil {
+			return encodeExample(v)
+		}
+	}
+	return s.exampleJSON(media.Schema)
+}
+
+func (s *openAPISpec) exampleJSON(schema *openAPISchema) string {
+	if schema == nil {
+		return ""
+	}
+	return encodeExample(s.example(schema, map[string]bool{}))
+}
+
+// encodeExample renders an example as indented JSON, or as is when it's
+// already a string such as an XML sample.
+func encodeExample(v interface{}) string {
+	if str, ok := v.(string); ok {
+		return str
+	}
+	encoded, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(encoded)
+}
+
+// example builds a sample value for schema from its example, default or
+// first enum value, falling back to a placeholder for its type. A schema
+// that refers back to itself is cut off with null; inRefs holds the $refs
+// being expanded.
+func (s *openAPISpec) example(schema *openAPISchema, inRefs map[string]bool) interface{} {
+	if schema != nil && schema.Ref != "" {
+		if inRefs[schema.Ref] {
+			return nil
+		}
+		inRefs[schema.Ref] = true
+		defer delete(inRefs, schema.Ref)
+	}
+	schema = s.resolveSchema(schema)
+	if schema == nil {
+		return nil
+	}
+	switch {
+	case schema.Example != nil:
+		return schema.Example
+	case schema.Default != nil:
+		return schema.Default
+	case len(schema.Enum) > 0:
+		return schema.Enum[0]
+	case len(schema.AllOf) > 0:
+		merged := map[string]interface{}{}
+		for _, part := range schema.AllOf {
+			if obj, ok := s.example(part, inRefs).(map[string]interface{}); ok {
+				for k, v := range obj {
+					merged[k] = v
+				}
+			}
+		}
+		return merged
+	case len(schema.OneOf) > 0:
+		return s.example(schema.OneOf[0], inRefs)
+	case len(schema.AnyOf) > 0:
+		return s.example(schema.AnyOf[0], inRefs)
+	}
+
+	switch schemaType(schema) {
+	case "object":
+		obj := map[string]interface{}{}
+		for name, prop := range schema.Properties {
+			obj[name] = s.example(prop, inRefs)
+		}
+		return obj
+	case "array":
+		return []interface{}{s.example(schema.Items, inRefs)}
+	case "string":
+		return "string"
+	case "integer", "number":
+		return 0
+	case "boolean":
+		return false
+	}
+	return nil
+}
+
+// schemaType returns the schema's type, taking the first non-null one
+// when several are listed. Schemas with properties but no type are
+// objects.
+func schemaType(schema *openAPISchema) string {
+	switch t := schema.Type.(type) {
+	case string:
+		return t
+	case []interface{}:
+		for _, v := range t {
+			if name, ok := v.(string); ok && name != "null" {
+				return name
+			}
+		}
+	}
+	if len(schema.Properties) > 0 {
+		return "object"
+	}
+	return ""
+}
+
+// refName returns the last segment of a local $ref such as
+// #/components/schemas/User.
+func refName(ref string) string {
+	return ref[strings.LastIndex(ref, "/")+1:]
+}
+
+func (s *openAPISpec) resolveSchema(schema *openAPISchema) *openAPISchema {
+	for i := 0; schema != nil && schema.Ref != "" && i < 16; i++ {
+		name := refName(schema.Ref)
+		if strings.HasPrefix(schema.Ref, "#/definitions/") {
+			schema = s.Definitions[name]
+		} else {
+			schema = s.Components.Schemas[name]
+		}
+	}
+	return schema
+}
+
+func (s *openAPISpec) resolveParameter(p openAPIParameter) openAPIParameter {
+	if p.Ref == "" {
+		return p
+	}
+	if strings.HasPrefix(p.Ref, "#/parameters/") {
+		return s.Parameters[refName(p.Ref)]
+	}
+	return s.Components.Parameters[refName(p.Ref)]
+}
+
+func (s *openAPISpec) resolveRequestBody(body openAPIRequestBody) openAPIRequestBody {
+	if body.Ref == "" {
+		return body
+	}
+	return s.Components.RequestBodies[refName(body.Ref)]
+}
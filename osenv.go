@@ -0,0 +1,67 @@
+package main
+
+import (
+	"os"
+	"regexp"
+)
+
+// osEnvRefPattern matches the {{NAME}} placeholders that can fall back to
+// an OS environment variable. {{$uuid}} and {{response.id}} never do.
+var osEnvRefPattern = regexp.MustCompile(`\{\{([A-Za-z_][A-Za-z0-9_]*)\}\}`)
+
+func (cm *ConfigManager) useOSEnv() bool {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+	return cm.Config.UseOSEnv
+}
+
+// replaceOSEnvVars substitutes placeholders with the OS environment
+// variables of the same name. Unset ones are left as they are.
+func replaceOSEnvVars(input string) string {
+	return osEnvRefPattern.ReplaceAllStringFunc(input, func(ref string) string {
+		if value, ok := os.LookupEnv(osEnvRefPattern.FindStringSubmatch(ref)[1]); ok {
+			return value
+		}
+		return ref
+	})
+}
+
+// requestTemplateParts lists the fields of req that may hold placeholders.
+func requestTemplateParts(req RequestItem) []string {
+	parts := []string{req.URL, req.Body, req.Variables}
+	for _, v := range req.Headers {
+		parts = append(parts, v)
+	}
+	for _, values := range req.Query {
+		parts = append(parts, values...)
+	}
+	if req.Auth != nil {
+		parts = append(parts, req.Auth.Token, req.Auth.Username, req.Auth.Password)
+	}
+	return parts
+}
+
+// osEnvRefs lists the placeholders in parts, each once, that will be
+// filled from the OS environment: use_os_env is on, the current
+// environment doesn't define them and the OS does.
+func (cm *ConfigManager) osEnvRefs(parts ...string) []string {
+	if cm == nil || !cm.useOSEnv() {
+		return nil
+	}
+	vars := cm.getCurrentEnvironment().Variables
+	var names []string
+	seen := make(map[string]bool)
+	for _, p := range parts {
+		for _, match := range osEnvRefPattern.FindAllStringSubmatch(p, -1) {
+			name := match[1]
+			if _, defined := vars[name]; defined || seen[name] {
+				continue
+			}
+			if _, ok := os.LookupEnv(name); ok {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
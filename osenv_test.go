@@ -0,0 +1,42 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestReplaceEnvVarsOSPrecedence(t *testing.T) {
+	t.Setenv("APITUI_FILE_AND_OS", "from-os")
+	t.Setenv("APITUI_OS_ONLY", "os-only")
+
+	tests := []struct {
+		name     string
+		useOSEnv bool
+		input    string
+		want     string
+		wantRefs []string
+	}{
+		{"environment file wins over the OS", true, "{{APITUI_FILE_AND_OS}}", "from-file", nil},
+		{"OS fills what the file lacks", true, "{{APITUI_OS_ONLY}}", "os-only", []string{"APITUI_OS_ONLY"}},
+		{"unset everywhere stays a placeholder", true, "{{APITUI_UNSET}}", "{{APITUI_UNSET}}", nil},
+		{"OS ignored when off", false, "{{APITUI_OS_ONLY}}", "{{APITUI_OS_ONLY}}", nil},
+		{"file still used when off", false, "{{APITUI_FILE_AND_OS}}", "from-file", nil},
+		{"OS reference listed once", true, "{{APITUI_OS_ONLY}}/{{APITUI_OS_ONLY}}", "os-only/os-only", []string{"APITUI_OS_ONLY"}},
+		{"dynamic values never come from the OS", true, "{{$uuid}}", "{{$uuid}}", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cm := newTestConfigManager(t)
+			cm.Environments = map[string]Environment{"dev": {Name: "dev", Variables: map[string]string{"APITUI_FILE_AND_OS": "from-file"}}}
+			cm.Config.CurrentEnv = "dev"
+			cm.Config.UseOSEnv = tt.useOSEnv
+
+			if got := cm.replaceEnvVars(tt.input); got != tt.want {
+				t.Errorf("replaceEnvVars(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+			if refs := cm.osEnvRefs(tt.input); !reflect.DeepEqual(refs, tt.wantRefs) {
+				t.Errorf("osEnvRefs(%q) = %v, want %v", tt.input, refs, tt.wantRefs)
+			}
+		})
+	}
+}
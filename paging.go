@@ -0,0 +1,32 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// lineOffsets returns the byte offset at which each line of s starts,
+// followed by len(s), so line i spans offsets[i] to offsets[i+1].
+func lineOffsets(s string) []int {
+	offsets := make([]int, 0, strings.Count(s, "\n")+2)
+	offsets = append(offsets, 0)
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\n' {
+			offsets = append(offsets, i+1)
+		}
+	}
+	return append(offsets, len(s))
+}
+
+// scrollPosition gives the byte offset of the response's top visible
+// line and the total, e.g. "1.2 MB / 4.0 MB (31%)". It's empty when the
+// whole response fits.
+func (m Model) scrollPosition() string {
+	lines := len(m.responseOffsets) - 1
+	if lines < 1 || (m.responseView.AtTop() && m.responseView.AtBottom()) {
+		return ""
+	}
+	offset := m.responseOffsets[min(m.responseView.YOffset, lines)]
+	total := m.responseOffsets[lines]
+	return fmt.Sprintf("%s / %s (%.0f%%)", formatBytes(int64(offset)), formatBytes(int64(total)), m.responseView.ScrollPercent()*100)
+}
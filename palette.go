@@ -0,0 +1,131 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// stayOnPanel marks palette actions that run in whichever panel is focused.
+const stayOnPanel = -1
+
+// paletteAction is an action as listed in the command palette. Running it
+// focuses panel, unless it's stayOnPanel, and sends the binding's first key,
+// so the action behaves exactly as if the key had been pressed.
+type paletteAction struct {
+	binding key.Binding
+	panel   int
+}
+
+func (a paletteAction) Title() string {
+	desc := a.binding.Help().Desc
+	if desc == "" {
+		return ""
+	}
+	return strings.ToUpper(desc[:1]) + desc[1:]
+}
+
+func (a paletteAction) Description() string { return a.binding.Help().Key }
+
+func (a paletteAction) FilterValue() string {
+	return a.binding.Help().Desc + " " + a.binding.Help().Key
+}
+
+// paletteActions lists the actions the palette offers, with the current
+// keys. Actions of the response panel focus it first, and sending focuses
+// the URL, where enter sends.
+func paletteActions() []list.Item {
+	global := []key.Binding{
+		keys.SaveRequest, keys.ToggleHistory, keys.ToggleEnvs, keys.Collections,
+		keys.SavedRequests, keys.Timeline, keys.Dashboard, keys.Auth,
+		keys.Cookies, keys.Connections, keys.GraphQL, keys.Repeat, keys.Review,
+		keys.RecordMacro, keys.PlayMacro, keys.ShareRequest, keys.ImportShare,
+		keys.NormalizeURL, keys.TrailingSlash, keys.ConvertBody, keys.LintBody,
+		keys.DryRun, keys.CopyAsFetch, keys.NewTab, keys.CloseTab, keys.NextTab,
+		keys.PrevTab, keys.ToggleTheme, keys.ToggleHelp, keys.Quit,
+	}
+	response := []key.Binding{
+		keys.CopyCurl, keys.CopyBody, keys.CopyHeader, keys.CopySummary,
+		keys.CopyTimings, keys.ExportTimings, keys.SaveResponse, keys.Search,
+		keys.ShowPaths, keys.Extract, keys.ToggleMeta, keys.RawView,
+		keys.FoldHeaders, keys.DiffResponse, keys.DecodeJWT, keys.EditLastSent,
+		keys.ShowBookmarks,
+	}
+
+	actions := []paletteAction{{binding: keys.Enter, panel: urlPanel}}
+	for _, b := range global {
+		actions = append(actions, paletteAction{binding: b, panel: stayOnPanel})
+	}
+	for _, b := range response {
+		actions = append(actions, paletteAction{binding: b, panel: responsePanel})
+	}
+
+	items := make([]list.Item, 0, len(actions))
+	for _, a := range actions {
+		if a.binding.Enabled() && len(a.binding.Keys()) > 0 {
+			items = append(items, a)
+		}
+	}
+	return items
+}
+
+// openPalette shows the command palette with its filter ready for typing.
+func (m Model) openPalette() (tea.Model, tea.Cmd) {
+	items := paletteActions()
+	m.paletteList.ResetFilter()
+	m.paletteList.SetFilterState(list.Filtering)
+	// Setting items while filtering runs the (empty) filter, which lists
+	// them all once its FilterMatchesMsg arrives
+	cmd := m.paletteList.SetItems(items)
+	m.paletteList.Title = fmt.Sprintf("Commands (%d) • type to filter • ↑/↓: select • enter: run • esc: close", len(items))
+	m.showPalette = true
+	return m, cmd
+}
+
+// updatePalette handles keys while the command palette is open. Typing
+// always goes to the filter, so only the arrow keys move the selection.
+func (m Model) updatePalette(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, keys.Cancel):
+		m.showPalette = false
+		return m, nil
+
+	case key.Matches(msg, keys.Enter):
+		a, ok := m.paletteList.SelectedItem().(paletteAction)
+		if !ok {
+			return m, nil
+		}
+		return m.runPaletteAction(a)
+
+	case msg.Type == tea.KeyUp:
+		m.paletteList.CursorUp()
+		return m, nil
+
+	case msg.Type == tea.KeyDown:
+		m.paletteList.CursorDown()
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.paletteList, cmd = m.paletteList.Update(msg)
+	return m, cmd
+}
+
+// runPaletteAction closes the palette and sends the action's key.
+func (m Model) runPaletteAction(a paletteAction) (tea.Model, tea.Cmd) {
+	m.showPalette = false
+	var cmds []tea.Cmd
+	if a.panel != stayOnPanel && a.panel != m.activePanel {
+		m.autoFocused = false
+		m.activePanel = a.panel
+		focused, cmd := m.updateFocus()
+		m = focused.(Model)
+		cmds = append(cmds, cmd)
+	}
+	msgs := macroKeyMsgs([]MacroStep{{Key: a.binding.Keys()[0]}}, func(s string) string { return s })
+	cmds = append(cmds, replayMacro(msgs))
+	return m, tea.Batch(cmds...)
+}
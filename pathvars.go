@@ -0,0 +1,75 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+var pathParamPattern = regexp.MustCompile(`^:([A-Za-z_][A-Za-z0-9_]*)$`)
+
+// splitPath separates raw into the scheme/host prefix, the path, and the
+// query/fragment suffix without parsing it, so templates that aren't valid
+// URLs yet still split cleanly.
+func splitPath(raw string) (prefix, path, suffix string) {
+	start := 0
+	if i := strings.Index(raw, "://"); i != -1 {
+		start = i + 3
+		if j := strings.Index(raw[start:], "/"); j != -1 {
+			start += j
+		} else {
+			start = len(raw)
+		}
+	}
+
+	end := len(raw)
+	if i := strings.IndexAny(raw[start:], "?#"); i != -1 {
+		end = start + i
+	}
+	return raw[:start], raw[start:end], raw[end:]
+}
+
+// pathParams returns the names of the :name path segments in raw, in order
+// and without duplicates, e.g. ["userId", "postId"] for
+// /users/:userId/posts/:postId. Colons in the host (ports) are ignored.
+func pathParams(raw string) []string {
+	_, path, _ := splitPath(raw)
+
+	var names []string
+	seen := make(map[string]bool)
+	for _, segment := range strings.Split(path, "/") {
+		if m := pathParamPattern.FindStringSubmatch(segment); m != nil && !seen[m[1]] {
+			seen[m[1]] = true
+			names = append(names, m[1])
+		}
+	}
+	return names
+}
+
+// bindPathParams replaces each :name path segment in raw with its
+// URL-encoded value. Segments without a value are left as-is and their
+// names are returned in missing.
+func bindPathParams(raw string, values map[string]string) (bound string, missing []string) {
+	prefix, path, suffix := splitPath(raw)
+
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		m := pathParamPattern.FindStringSubmatch(segment)
+		if m == nil {
+			continue
+		}
+		value, ok := values[m[1]]
+		if !ok || value == "" {
+			missing = append(missing, m[1])
+			continue
+		}
+		segments[i] = url.PathEscape(value)
+	}
+	return prefix + strings.Join(segments, "/") + suffix, missing
+}
+
+// missingPathParamsError describes unbound path variables.
+func missingPathParamsError(missing []string) error {
+	return fmt.Errorf("no value for path variable :%s", strings.Join(missing, ", :"))
+}
@@ -0,0 +1,288 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// postmanCollection is the part of a Postman v2.1 export that maps onto a
+// Collection. Folders are items with their own items.
+type postmanCollection struct {
+	Info struct {
+		Name   string `json:"name"`
+		Schema string `json:"schema"`
+	} `json:"info"`
+	Item     []postmanItem     `json:"item"`
+	Auth     *postmanAuth      `json:"auth"`
+	Variable []postmanKeyValue `json:"variable"`
+}
+
+type postmanItem struct {
+	Name    string          `json:"name"`
+	Item    []postmanItem   `json:"item"`
+	Request *postmanRequest `json:"request"`
+	Auth    *postmanAuth    `json:"auth"`
+}
+
+type postmanRequest struct {
+	Method string            `json:"method"`
+	Header []postmanKeyValue `json:"header"`
+	Body   *postmanBody      `json:"body"`
+	URL    json.RawMessage   `json:"url"` // A string or an object with raw
+	Auth   *postmanAuth      `json:"auth"`
+}
+
+type postmanKeyValue struct {
+	Key      string `json:"key"`
+	Value    string `json:"value"`
+	Type     string `json:"type"`
+	Disabled bool   `json:"disabled"`
+}
+
+type postmanBody struct {
+	Mode       string            `json:"mode"`
+	Raw        string            `json:"raw"`
+	URLEncoded []postmanKeyValue `json:"urlencoded"`
+	FormData   []postmanKeyValue `json:"formdata"`
+	GraphQL    *struct {
+		Query     string `json:"query"`
+		Variables string `json:"variables"`
+	} `json:"graphql"`
+	Options struct {
+		Raw struct {
+			Language string `json:"language"`
+		} `json:"raw"`
+	} `json:"options"`
+}
+
+type postmanAuth struct {
+	Type   string            `json:"type"`
+	Bearer []postmanKeyValue `json:"bearer"`
+	Basic  []postmanKeyValue `json:"basic"`
+}
+
+// ImportPostman reads a Postman v2.1 collection export and adds its
+// requests to a collection of the same name. Folders are flattened, with
+// the folder names prefixed to each request's name. Postman's {{var}}
+// placeholders are kept, so they resolve against the active environment.
+func (cm *ConfigManager) ImportPostman(path string) error {
+	_, _, err := cm.importPostman(path)
+	return err
+}
+
+// importPostman is ImportPostman, also returning the collection name and
+// notes about anything that couldn't be carried over.
+func (cm *ConfigManager) importPostman(path string) (string, []string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", nil, err
+	}
+
+	var pc postmanCollection
+	if err := json.Unmarshal(data, &pc); err != nil {
+		return "", nil, fmt.Errorf("invalid Postman collection: %w", err)
+	}
+	if pc.Info.Schema != "" && !strings.Contains(pc.Info.Schema, "v2.1") && !strings.Contains(pc.Info.Schema, "v2.0") {
+		return "", nil, fmt.Errorf("unsupported Postman collection schema %s (export as v2.1)", pc.Info.Schema)
+	}
+
+	name := strings.TrimSpace(pc.Info.Name)
+	if name == "" {
+		name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+
+	var notes []string
+	if len(pc.Variable) > 0 {
+		keys := make([]string, len(pc.Variable))
+		for i, v := range pc.Variable {
+			keys[i] = v.Key
+		}
+		notes = append(notes, "collection variables aren't imported, define them in an environment: "+strings.Join(keys, ", "))
+	}
+
+	now := time.Now()
+	var requests []RequestItem
+	var walk func(items []postmanItem, prefix string, auth *postmanAuth)
+	walk = func(items []postmanItem, prefix string, auth *postmanAuth) {
+		for _, it := range items {
+			itemAuth := auth
+			if it.Auth != nil && it.Auth.Type != "inherit" {
+				itemAuth = it.Auth
+			}
+			if it.Request == nil {
+				walk(it.Item, prefix+it.Name+" / ", itemAuth)
+				continue
+			}
+			if it.Request.Auth != nil && it.Request.Auth.Type != "inherit" {
+				itemAuth = it.Request.Auth
+			}
+
+			req, reqNotes := postmanRequestItem(*it.Request, itemAuth)
+			req.Name = prefix + it.Name
+			req.ID = fmt.Sprintf("%d", now.UnixNano()+int64(len(requests)))
+			req.CreatedAt = now
+			requests = append(requests, req)
+			for _, n := range reqNotes {
+				notes = append(notes, req.Name+": "+n)
+			}
+		}
+	}
+	walk(pc.Item, "", pc.Auth)
+	if len(requests) == 0 {
+		return "", notes, fmt.Errorf("no requests found in %s", path)
+	}
+
+	cm.mu.Lock()
+	collection, ok := cm.Collections[name]
+	if !ok {
+		collection = Collection{Name: name, Requests: []RequestItem{}}
+	}
+	for _, req := range requests {
+		collection.Requests = mergeRequest(collection.Requests, req)
+	}
+	cm.Collections[name] = collection
+	cm.mu.Unlock()
+
+	return name, notes, cm.saveCollections()
+}
+
+// postmanRequestItem converts one Postman request, noting any parts that
+// were dropped.
+func postmanRequestItem(pr postmanRequest, auth *postmanAuth) (RequestItem, []string) {
+	req := RequestItem{
+		Method:  strings.ToUpper(pr.Method),
+		URL:     postmanURL(pr.URL),
+		Headers: map[string]string{},
+	}
+	if req.Method == "" {
+		req.Method = "GET"
+	}
+	for _, h := range pr.Header {
+		if !h.Disabled && h.Key != "" {
+			req.Headers[h.Key] = h.Value
+		}
+	}
+
+	var notes []string
+	if b := pr.Body; b != nil {
+		switch b.Mode {
+		case "raw":
+			req.Body = b.Raw
+			if b.Options.Raw.Language == "json" && !hasHeader(req.Headers, "Content-Type") {
+				req.Headers["Content-Type"] = "application/json"
+			}
+		case "urlencoded":
+			form := url.Values{}
+			for _, kv := range b.URLEncoded {
+				if !kv.Disabled {
+					form.Add(kv.Key, kv.Value)
+				}
+			}
+			req.Body = form.Encode()
+			if !hasHeader(req.Headers, "Content-Type") {
+				req.Headers["Content-Type"] = "application/x-www-form-urlencoded"
+			}
+		case "graphql":
+			if b.GraphQL != nil {
+				payload := map[string]interface{}{"query": b.GraphQL.Query}
+				if vars := strings.TrimSpace(b.GraphQL.Variables); vars != "" {
+					payload["variables"] = json.RawMessage(vars)
+				}
+				if encoded, err := json.MarshalIndent(payload, "", "  "); err == nil {
+					req.Body = string(encoded)
+				} else {
+					notes = append(notes, "GraphQL variables aren't valid JSON, body skipped")
+				}
+				if !hasHeader(req.Headers, "Content-Type") {
+					req.Headers["Content-Type"] = "application/json"
+				}
+			}
+		case "", "none":
+		default:
+			notes = append(notes, b.Mode+" body isn't supported and was skipped")
+		}
+	}
+
+	if auth != nil {
+		switch auth.Type {
+		case "bearer":
+			req.Auth = &AuthConfig{Type: authBearer, Token: postmanValue(auth.Bearer, "token")}
+		case "basic":
+			req.Auth = &AuthConfig{
+				Type:     authBasic,
+				Username: postmanValue(auth.Basic, "username"),
+				Password: postmanValue(auth.Basic, "password"),
+			}
+		case "noauth", "":
+		default:
+			notes = append(notes, auth.Type+" auth isn't supported and was ignored")
+		}
+	}
+	return req, notes
+}
+
+// postmanURL returns a request URL given either as a string or as an
+// object, preferring the object's raw form.
+func postmanURL(raw json.RawMessage) string {
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return s
+	}
+
+	var u struct {
+		Raw      string            `json:"raw"`
+		Protocol string            `json:"protocol"`
+		Host     []string          `json:"host"`
+		Path     []string          `json:"path"`
+		Query    []postmanKeyValue `json:"query"`
+	}
+	if err := json.Unmarshal(raw, &u); err != nil {
+		return ""
+	}
+	if u.Raw != "" {
+		return u.Raw
+	}
+
+	built := strings.Join(u.Host, ".")
+	if u.Protocol != "" {
+		built = u.Protocol + "://" + built
+	}
+	if len(u.Path) > 0 {
+		built += "/" + strings.Join(u.Path, "/")
+	}
+	var query []string
+	for _, q := range u.Query {
+		if !q.Disabled {
+			query = append(query, q.Key+"="+q.Value)
+		}
+	}
+	if len(query) > 0 {
+		built += "?" + strings.Join(query, "&")
+	}
+	return built
+}
+
+// postmanValue finds key in the key/value list Postman uses for auth
+// settings.
+func postmanValue(kvs []postmanKeyValue, key string) string {
+	for _, kv := range kvs {
+		if kv.Key == key {
+			return kv.Value
+		}
+	}
+	return ""
+}
+
+func hasHeader(headers map[string]string, name string) bool {
+	for k := range headers {
+		if strings.EqualFold(k, name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// proxyFunc returns the transport's Proxy func. With Config.ProxyURL set
+// every request goes through it except hosts on the bypass list;
+// otherwise the standard HTTP_PROXY, HTTPS_PROXY and NO_PROXY variables
+// apply.
+func (cm *ConfigManager) proxyFunc() (func(*http.Request) (*url.URL, error), error) {
+	cm.mu.RLock()
+	raw, noProxy := strings.TrimSpace(cm.Config.ProxyURL), cm.Config.NoProxy
+	cm.mu.RUnlock()
+
+	if raw == "" {
+		return http.ProxyFromEnvironment, nil
+	}
+	proxy, err := parseProxyURL(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(noProxy) == 0 {
+		env := os.Getenv("NO_PROXY")
+		if env == "" {
+			env = os.Getenv("no_proxy")
+		}
+		noProxy = strings.Split(env, ",")
+	}
+	return func(req *http.Request) (*url.URL, error) {
+		if bypassProxy(req.URL, noProxy) {
+			return nil, nil
+		}
+		return proxy, nil
+	}, nil
+}
+
+// parseProxyURL checks a proxy_url value. A bare host:port is taken to be
+// an HTTP proxy.
+func parseProxyURL(raw string) (*url.URL, error) {
+	if !strings.Contains(raw, "://") {
+		raw = "http://" + raw
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy_url: %w", err)
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return nil, fmt.Errorf("invalid proxy_url %q: scheme must be http, https or socks5", u.Redacted())
+	}
+	if u.Hostname() == "" {
+		return nil, fmt.Errorf("invalid proxy_url %q: missing host", u.Redacted())
+	}
+	return u, nil
+}
+
+// bypassProxy reports whether u should be fetched directly. Loopback
+// addresses always are. Entries in noProxy follow the NO_PROXY
+// conventions: "*" matches everything, "example.com" and ".example.com"
+// match the domain and its subdomains, an IP or CIDR range matches
+// addresses, and a ":port" suffix restricts an entry to that port.
+func bypassProxy(u *url.URL, noProxy []string) bool {
+	host, port := u.Hostname(), u.Port()
+	if port == "" {
+		port = "80"
+		if u.Scheme == "https" {
+			port = "443"
+		}
+	}
+	ip := net.ParseIP(host)
+	if host == "localhost" || (ip != nil && ip.IsLoopback()) {
+		return true
+	}
+
+	for _, entry := range noProxy {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		if entry == "" {
+			continue
+		}
+		if entry == "*" {
+			return true
+		}
+		if _, cidr, err := net.ParseCIDR(entry); err == nil {
+			if ip != nil && cidr.Contains(ip) {
+				return true
+			}
+			continue
+		}
+
+		entryHost, entryPort := entry, ""
+		if h, p, err := net.SplitHostPort(entry); err == nil {
+			entryHost, entryPort = h, p
+		}
+		if entryPort != "" && entryPort != port {
+			continue
+		}
+		if entryIP := net.ParseIP(entryHost); entryIP != nil {
+			if ip != nil && entryIP.Equal(ip) {
+				return true
+			}
+			continue
+		}
+
+		domain := strings.TrimPrefix(entryHost, "*")
+		domain = strings.TrimPrefix(domain, ".")
+		h := strings.ToLower(host)
+		if h == domain || strings.HasSuffix(h, "."+domain) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// parseQueryParams reads the query panel: one key=value pair per line,
+// unencoded. A line without = is a key with an empty value.
+func parseQueryParams(input string) url.Values {
+	params := url.Values{}
+	for _, line := range strings.Split(input, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		key, value, _ := strings.Cut(line, "=")
+		if key = strings.TrimSpace(key); key != "" {
+			params.Add(key, strings.TrimSpace(value))
+		}
+	}
+	return params
+}
+
+// formatQueryParams renders params back into query panel lines, sorted by
+// key.
+func formatQueryParams(params url.Values) string {
+	keys := make([]string, 0, len(params))
+	for k := range params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var lines []string
+	for _, k := range keys {
+		for _, v := range params[k] {
+			lines = append(lines, k+"="+v)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// appendQueryParams adds params to raw as an encoded query string. The
+// URL's own query is kept exactly as written, so a key set in both places
+// is sent twice. resolve is applied to keys and values before encoding.
+func appendQueryParams(raw string, params url.Values, resolve func(string) string) string {
+	if len(params) == 0 {
+		return raw
+	}
+
+	resolved := url.Values{}
+	for k, vs := range params {
+		for _, v := range vs {
+			resolved.Add(resolve(k), resolve(v))
+		}
+	}
+
+	base, fragment, hasFragment := strings.Cut(raw, "#")
+	switch {
+	case !strings.Contains(base, "?"):
+		base += "?"
+	case !strings.HasSuffix(base, "?") && !strings.HasSuffix(base, "&"):
+		base += "&"
+	}
+	base += resolved.Encode()
+	if hasFragment {
+		base += "#" + fragment
+	}
+	return base
+}
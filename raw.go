@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// formatRawExchange shows the request as sent and the response as
+// received, without formatting or charset decoding. The request is
+// rebuilt from what was sent, so headers the transport adds on its own
+// (such as Accept-Encoding) aren't listed. The response body is shown
+// after decompression.
+func formatRawExchange(st *styles, r Response) string {
+	var sb strings.Builder
+	proto := harProto(r.Proto)
+
+	sent := r.Sent
+	if sent.URL != "" {
+		target, host := sent.URL, ""
+		if u, err := url.Parse(sent.URL); err == nil {
+			target, host = u.RequestURI(), u.Host
+		}
+		sb.WriteString(st.help.Render("> Request") + "\n")
+		sb.WriteString(fmt.Sprintf("%s %s %s\n", sent.Method, target, proto))
+		if host != "" && sent.Headers.Get("Host") == "" {
+			sb.WriteString("Host: " + host + "\n")
+		}
+		headers := sent.Headers.Clone()
+		if headers == nil {
+			headers = http.Header{}
+		}
+		if sent.HasBody && sent.BodyFile == "" && headers.Get("Content-Length") == "" {
+			headers.Set("Content-Length", fmt.Sprint(len(sent.Body)))
+		}
+		writeRawHeaders(&sb, headers)
+		sb.WriteString("\n")
+		if sent.BodyFile != "" {
+			sb.WriteString(st.help.Render("(contents of "+sent.BodyFile+")") + "\n")
+		} else if sent.HasBody {
+			sb.WriteString(sent.Body + "\n")
+		}
+		sb.WriteString("\n")
+	}
+
+	sb.WriteString(st.help.Render("< Response") + "\n")
+	status := r.Status
+	if status == "" {
+		status = fmt.Sprintf("%d %s", r.StatusCode, http.StatusText(r.StatusCode))
+	}
+	sb.WriteString(proto + " " + status + "\n")
+	writeRawHeaders(&sb, r.Headers)
+	sb.WriteString("\n")
+	if r.Binary {
+		sb.WriteString(st.help.Render(strings.SplitN(r.FormattedBody, "\n", 2)[0]))
+	} else {
+		sb.WriteString(sanitizeForTerminal(r.Body))
+	}
+	return sb.String()
+}
+
+// writeRawHeaders writes h one header line per value, sorted by name as
+// net/http sends them.
+func writeRawHeaders(sb *strings.Builder, h http.Header) {
+	for _, k := range sortedKeys(url.Values(h)) {
+		for _, v := range h[k] {
+			sb.WriteString(k + ": " + v + "\n")
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const (
+	recoveryFile     = "recovery.json"
+	draftFile        = "draft.json"
+	autosaveInterval = 5 * time.Second
+)
+
+// RecoveryState is a snapshot of the editor written periodically so an
+// in-progress request survives a crash or a closed terminal. Headers are
+// kept as the raw editor text rather than parsed.
+type RecoveryState struct {
+	URL     string    `json:"url"`
+	Method  string    `json:"method"`
+	Headers string    `json:"headers"`
+	Body    string    `json:"body"`
+	Query   string    `json:"query,omitempty"`
+	SavedAt time.Time `json:"saved_at"`
+}
+
+func (r RecoveryState) empty() bool {
+	return r.URL == "" && r.Headers == "" && r.Body == "" && r.Query == ""
+}
+
+// sameContent compares the editor fields, ignoring when they were saved.
+func (r RecoveryState) sameContent(o RecoveryState) bool {
+	return r.URL == o.URL && r.Method == o.Method && r.Headers == o.Headers && r.Body == o.Body && r.Query == o.Query
+}
+
+type autosaveMsg struct{}
+
+func autosaveTick() tea.Cmd {
+	return tea.Tick(autosaveInterval, func(time.Time) tea.Msg {
+		return autosaveMsg{}
+	})
+}
+
+func (cm *ConfigManager) saveRecovery(state RecoveryState) error {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+	return cm.saveJSONLocked(recoveryFile, state)
+}
+
+// loadRecovery returns the recovery snapshot left behind by a previous
+// session that didn't exit cleanly.
+func (cm *ConfigManager) loadRecovery() (RecoveryState, bool) {
+	return cm.loadSnapshot(recoveryFile)
+}
+
+func (cm *ConfigManager) clearRecovery() error {
+	return cm.removeSnapshot(recoveryFile)
+}
+
+// saveDraft stores the editor on send and on quit. Unlike the recovery
+// snapshot it survives a clean exit, and is only cleared once the request
+// is saved to a collection.
+func (cm *ConfigManager) saveDraft(state RecoveryState) error {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+	return cm.saveJSONLocked(draftFile, state)
+}
+
+func (cm *ConfigManager) loadDraft() (RecoveryState, bool) {
+	return cm.loadSnapshot(draftFile)
+}
+
+func (cm *ConfigManager) clearDraft() error {
+	return cm.removeSnapshot(draftFile)
+}
+
+func (cm *ConfigManager) loadSnapshot(name string) (RecoveryState, bool) {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+
+	data, err := os.ReadFile(filepath.Join(cm.configDir, name))
+	if err != nil {
+		return RecoveryState{}, false
+	}
+
+	var state RecoveryState
+	if err := json.Unmarshal(data, &state); err != nil || state.empty() {
+		return RecoveryState{}, false
+	}
+	return state, true
+}
+
+func (cm *ConfigManager) removeSnapshot(name string) error {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	err := os.Remove(filepath.Join(cm.configDir, name))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
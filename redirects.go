@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const defaultMaxRedirects = 10
+
+// RedirectHop is one redirect followed on the way to the final response.
+type RedirectHop struct {
+	StatusCode int
+	URL        string
+	Location   string
+}
+
+// redirectPolicy returns whether to follow redirects and how many at most.
+func (cm *ConfigManager) redirectPolicy() (follow bool, limit int) {
+	if cm == nil {
+		return true, defaultMaxRedirects
+	}
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+	limit = cm.Config.MaxRedirects
+	if limit <= 0 {
+		limit = defaultMaxRedirects
+	}
+	return cm.Config.FollowRedirects, limit
+}
+
+// checkRedirect builds a CheckRedirect func for http.Client. Followed hops
+// are appended to hops. With following disabled the 3xx itself is returned
+// as the response.
+func checkRedirect(follow bool, limit int, hops *[]RedirectHop) func(*http.Request, []*http.Request) error {
+	return func(req *http.Request, via []*http.Request) error {
+		if !follow {
+			return http.ErrUseLastResponse
+		}
+		if len(via) > limit {
+			return fmt.Errorf("stopped after %d redirects", limit)
+		}
+		hop := RedirectHop{URL: via[len(via)-1].URL.String(), Location: req.URL.String()}
+		if req.Response != nil {
+			hop.StatusCode = req.Response.StatusCode
+		}
+		*hops = append(*hops, hop)
+		return nil
+	}
+}
+
+// formatRedirects renders the chain of followed redirects, one hop per
+// line.
+func formatRedirects(hops []RedirectHop) string {
+	var sb strings.Builder
+	for _, h := range hops {
+		sb.WriteString(fmt.Sprintf("%d %s\n  %s → %s\n", h.StatusCode, http.StatusText(h.StatusCode), h.URL, h.Location))
+	}
+	return sb.String()
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestDoRequestRedirects(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/old", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/new", http.StatusFound)
+	})
+	mux.HandleFunc("/new", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("arrived"))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	tests := []struct {
+		name         string
+		follow       bool
+		wantStatus   int
+		wantHops     []RedirectHop
+		wantLocation string
+	}{
+		{
+			name:       "follow",
+			follow:     true,
+			wantStatus: http.StatusOK,
+			wantHops:   []RedirectHop{{StatusCode: http.StatusFound, URL: srv.URL + "/old", Location: srv.URL + "/new"}},
+		},
+		{
+			name:         "no follow",
+			follow:       false,
+			wantStatus:   http.StatusFound,
+			wantLocation: "/new",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := doRequest(context.Background(), srv.Client(), RequestSpec{
+				Method:          http.MethodGet,
+				URL:             srv.URL + "/old",
+				Timeout:         5 * time.Second,
+				FollowRedirects: tt.follow,
+				MaxRedirects:    defaultMaxRedirects,
+				MaxBytes:        defaultMaxResponseBytes,
+			})
+			if res.Error != nil {
+				t.Fatalf("doRequest: %v", res.Error)
+			}
+			if res.StatusCode != tt.wantStatus {
+				t.Errorf("status = %d, want %d", res.StatusCode, tt.wantStatus)
+			}
+			if len(res.Redirects) != len(tt.wantHops) {
+				t.Fatalf("redirects = %+v, want %+v", res.Redirects, tt.wantHops)
+			}
+			for i, hop := range tt.wantHops {
+				if res.Redirects[i] != hop {
+					t.Errorf("hop %d = %+v, want %+v", i, res.Redirects[i], hop)
+				}
+			}
+			if got := res.Headers.Get("Location"); got != tt.wantLocation {
+				t.Errorf("Location = %q, want %q", got, tt.wantLocation)
+			}
+		})
+	}
+}
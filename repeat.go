@@ -0,0 +1,161 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// maxRepeatCount keeps a mistyped count from turning a sanity check into
+// a load test.
+const maxRepeatCount = 1000
+
+// repeatResultMsg carries the responses of a repeat run, in send order.
+type repeatResultMsg struct {
+	request     RequestItem
+	count       int
+	concurrency int
+	elapsed     time.Duration
+	results     []Response
+}
+
+// repeatRequest returns a command that sends reqItem count times, at most
+// concurrency at once, through the batch runner. The requests aren't
+// added to the history.
+func (m Model) repeatRequest(ctx context.Context, reqItem RequestItem, count, concurrency int) tea.Cmd {
+	return func() tea.Msg {
+		start := time.Now()
+		results := runBatch(count, concurrency, func(int) Response {
+			if ctx.Err() != nil {
+				return Response{Error: errRequestCancelled}
+			}
+			return m.executeRequest(ctx, reqItem, false)
+		})
+		return repeatResultMsg{request: reqItem, count: count, concurrency: concurrency, elapsed: time.Since(start), results: results}
+	}
+}
+
+// startRepeat parses the count and concurrency prompts and starts the run.
+func (m Model) startRepeat(concurrencyValue string) (tea.Model, tea.Cmd) {
+	concurrency, err := strconv.Atoi(strings.TrimSpace(concurrencyValue))
+	if err != nil || concurrency < 1 {
+		m.requestError = fmt.Errorf("concurrency must be a positive number, got %q", concurrencyValue)
+		return m, nil
+	}
+	concurrency = min(concurrency, m.repeatCount)
+
+	if m.loading {
+		m.statusMessage = "Request in progress, wait for it to finish"
+		return m, nil
+	}
+	reqItem := m.currentRequest()
+	reqItem.Body = m.lastBody
+	m.loading = true
+	m.repeating = true
+	m.requestError = nil
+	m.statusMessage = ""
+	return m, m.repeatRequest(m.newRequestContext(), reqItem, m.repeatCount, concurrency)
+}
+
+// parseRepeatCount checks the repeat count prompt.
+func parseRepeatCount(value string) (int, error) {
+	count, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || count < 1 || count > maxRepeatCount {
+		return 0, fmt.Errorf("repeat count must be between 1 and %d, got %q", maxRepeatCount, value)
+	}
+	return count, nil
+}
+
+// formatRepeatStats summarises a repeat run: response time percentiles of
+// the requests that got a response, the status code distribution, and
+// failures grouped by error.
+func formatRepeatStats(st *styles, msg repeatResultMsg) string {
+	var times []time.Duration
+	statuses := map[int]int{}
+	failures := map[string]int{}
+	cancelled := 0
+	for _, r := range msg.results {
+		switch {
+		case errors.Is(r.Error, errRequestCancelled):
+			cancelled++
+		case r.Error != nil && r.StatusCode == 0:
+			failures[r.Error.Error()]++
+		default:
+			times = append(times, r.ResponseTime)
+			statuses[r.StatusCode]++
+		}
+	}
+
+	target := msg.request.Method + " " + msg.request.URL
+	for _, r := range msg.results {
+		if r.Sent.URL != "" {
+			target = r.Sent.Method + " " + r.Sent.URL
+			break
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Repeat: %d × %s\n", msg.count, target))
+	sb.WriteString(fmt.Sprintf("Concurrency: %d, took %v", msg.concurrency, msg.elapsed.Round(time.Millisecond)))
+	if secs := msg.elapsed.Seconds(); secs > 0 {
+		sb.WriteString(fmt.Sprintf(" (%.1f req/s)", float64(len(msg.results)-cancelled)/secs))
+	}
+	sb.WriteString("\n\n")
+
+	sb.WriteString(fmt.Sprintf("Responses: %d  Failed: %d", len(times), len(msg.results)-len(times)-cancelled))
+	if cancelled > 0 {
+		sb.WriteString(fmt.Sprintf("  Cancelled: %d", cancelled))
+	}
+	sb.WriteString("\n\n")
+
+	if len(times) > 0 {
+		sort.Slice(times, func(i, j int) bool { return times[i] < times[j] })
+		var total time.Duration
+		for _, t := range times {
+			total += t
+		}
+		sb.WriteString("Response time:\n")
+		sb.WriteString(fmt.Sprintf("  min  %v\n", times[0].Round(time.Microsecond)))
+		sb.WriteString(fmt.Sprintf("  avg  %v\n", (total / time.Duration(len(times))).Round(time.Microsecond)))
+		sb.WriteString(fmt.Sprintf("  p50  %v\n", percentile(times, 50).Round(time.Microsecond)))
+		sb.WriteString(fmt.Sprintf("  p95  %v\n", percentile(times, 95).Round(time.Microsecond)))
+		sb.WriteString(fmt.Sprintf("  max  %v\n\n", times[len(times)-1].Round(time.Microsecond)))
+
+		codes := make([]int, 0, len(statuses))
+		for code := range statuses {
+			codes = append(codes, code)
+		}
+		sort.Ints(codes)
+		sb.WriteString("Status codes:\n")
+		for _, code := range codes {
+			line := fmt.Sprintf("  %d %s  ×%d", code, statusReason(code, ""), statuses[code])
+			sb.WriteString(statusStyleFor(st, code).Render(line) + "\n")
+		}
+		sb.WriteString("\n")
+	}
+
+	if len(failures) > 0 {
+		errs := make([]string, 0, len(failures))
+		for e := range failures {
+			errs = append(errs, e)
+		}
+		sort.Strings(errs)
+		sb.WriteString("Failures:\n")
+		for _, e := range errs {
+			sb.WriteString(st.error.Render(fmt.Sprintf("  ×%d  %s", failures[e], e)) + "\n")
+		}
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+// percentile returns the nearest-rank p-th percentile of sorted.
+func percentile(sorted []time.Duration, p int) time.Duration {
+	rank := (p*len(sorted) + 99) / 100
+	return sorted[max(rank-1, 0)]
+}
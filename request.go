@@ -0,0 +1,379 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/htmlindex"
+	"golang.org/x/text/transform"
+)
+
+// RequestSpec is a request ready to send: variables are resolved, auth and
+// default headers are set, and settings have been read from the config.
+// doRequest needs nothing else, so it can be driven directly in tests.
+type RequestSpec struct {
+	Method  string
+	URL     string
+	Headers http.Header
+	// Body is sent when HasBody is set. A body of "@path" streams the file.
+	Body    string
+	HasBody bool
+	Timeout time.Duration
+	// FollowRedirects and MaxRedirects are the redirect policy, see
+	// checkRedirect.
+	FollowRedirects bool
+	MaxRedirects    int
+	// AutoFormat pretty-prints JSON, XML and YAML bodies.
+	AutoFormat bool
+	// MaxBytes is the largest body that's read; larger ones are rejected
+	// when the Content-Length says so and cut short otherwise.
+	MaxBytes int64
+}
+
+// doRequest sends spec with client and reads, decodes and formats the
+// response. Failures are reported in Response.Error, worded for the
+// status line. The request is abandoned when ctx is cancelled, which is
+// reported as errRequestCancelled. client's CheckRedirect is replaced so
+// the redirect chain can be recorded; the rest of it is used as is.
+//
+// When ctx carries an sseStream (see withSSEStream) and the response is an
+// event stream, the timeout stops once the headers arrive and the events
+// are passed to the stream as they come in.
+func doRequest(ctx context.Context, client *http.Client, spec RequestSpec) Response {
+	parent := ctx
+	ctx, cancel := context.WithCancel(parent)
+	defer cancel()
+	var timedOut atomic.Bool
+	deadline := time.AfterFunc(spec.Timeout, func() {
+		timedOut.Store(true)
+		cancel()
+	})
+	defer deadline.Stop()
+	stream := sseStreamFrom(parent)
+
+	var reqBody io.Reader
+	bodyFile, bodySize := "", int64(-1)
+	if spec.HasBody {
+		reqBody = strings.NewReader(spec.Body)
+		if path, ok := bodyFilePath(spec.Body); ok {
+			f, size, err := openBodyFile(path)
+			if err != nil {
+				return Response{Error: err}
+			}
+			reqBody, bodyFile, bodySize = f, path, size
+		}
+	}
+
+	req, err := http.NewRequest(spec.Method, spec.URL, reqBody)
+	if err != nil {
+		if closer, ok := reqBody.(io.Closer); ok {
+			closer.Close()
+		}
+		return Response{Error: err}
+	}
+	if bodyFile != "" {
+		req.ContentLength = bodySize
+	}
+	for k, values := range spec.Headers {
+		for _, v := range values {
+			req.Header.Add(k, v)
+		}
+	}
+
+	var redirects []RedirectHop
+	c := *client
+	c.CheckRedirect = checkRedirect(spec.FollowRedirects, spec.MaxRedirects, &redirects)
+
+	sent := SentRequest{
+		Method:   spec.Method,
+		URL:      req.URL.String(),
+		Headers:  req.Header.Clone(),
+		Body:     spec.Body,
+		HasBody:  reqBody != nil,
+		BodyFile: bodyFile,
+	}
+
+	ctx, tracer := withTracer(ctx)
+	req = req.WithContext(ctx)
+
+	resultChan := make(chan Response, 1)
+	startTime := time.Now()
+
+	go func() {
+		resp, err := c.Do(req)
+		responseTime := time.Since(startTime)
+
+		if err != nil {
+			if parent.Err() == context.Canceled {
+				resultChan <- Response{Error: errRequestCancelled, ResponseTime: responseTime}
+				return
+			}
+			resultChan <- Response{
+				Error:        classifyRequestError(err, timedOut.Load(), spec.Timeout),
+				ResponseTime: responseTime,
+			}
+			return
+		}
+		defer resp.Body.Close()
+
+		if stream != nil && isEventStream(resp.Header.Get("Content-Type")) && deadline.Stop() {
+			stream.head = Response{
+				StatusCode: resp.StatusCode,
+				Status:     resp.Status,
+				Headers:    resp.Header,
+				Proto:      resp.Proto,
+				TLS:        resp.TLS,
+				Sent:       sent,
+			}
+			close(stream.started)
+			raw, events, end := readEventStream(parent, resp.Body, stream, spec.MaxBytes)
+			res := stream.head
+			res.Body, res.FormattedBody = raw, raw
+			res.Events, res.StreamEnd = events, end
+			res.ResponseTime = time.Since(startTime)
+			res.DecodedSize = int64(len(raw))
+			res.Timings = tracer.timings(responseTime)
+			res.Redirects = redirects
+			resultChan <- res
+			return
+		}
+
+		// These never have a body, whatever Content-Length says: for HEAD
+		// and 304 it's the size of the resource, not of this response
+		noBody := spec.Method == http.MethodHead || resp.StatusCode == http.StatusNoContent || resp.StatusCode == http.StatusNotModified
+
+		contentLength := resp.ContentLength
+		if contentLength > spec.MaxBytes && !noBody {
+			resultChan <- Response{
+				StatusCode:    resp.StatusCode,
+				Status:        resp.Status,
+				Headers:       resp.Header,
+				Error:         fmt.Errorf("response too large (%s) - size limit is %s, see max_response_bytes", formatBytes(contentLength), formatBytes(spec.MaxBytes)),
+				ResponseTime:  responseTime,
+				ContentLength: contentLength,
+			}
+			return
+		}
+
+		var bodyBuf bytes.Buffer
+		// One byte over the limit tells a body that was cut short from one
+		// that's exactly the limit
+		var body io.Reader = resp.Body
+		if noBody {
+			body = http.NoBody
+		}
+		limitReader := io.LimitReader(body, spec.MaxBytes+1)
+		_, err = io.Copy(&bodyBuf, limitReader)
+		if err != nil && parent.Err() == context.Canceled {
+			resultChan <- Response{Error: errRequestCancelled, ResponseTime: time.Since(startTime)}
+			return
+		}
+		if err != nil {
+			resultChan <- Response{
+				StatusCode:    resp.StatusCode,
+				Status:        resp.Status,
+				Headers:       resp.Header,
+				Error:         fmt.Errorf("failed to read response: %v", err),
+				ResponseTime:  responseTime,
+				ContentLength: contentLength,
+			}
+			return
+		}
+		respBody := bodyBuf.Bytes()
+		cutShort := int64(len(respBody)) > spec.MaxBytes
+		if cutShort {
+			respBody = respBody[:spec.MaxBytes]
+		}
+
+		wireSize := int64(len(respBody))
+		contentEncoding := resp.Header.Get("Content-Encoding")
+		if resp.Uncompressed {
+			// Go's transport asked for gzip on our behalf and already
+			// stripped it, so the compressed size is unknown
+			wireSize = -1
+			contentEncoding = "gzip"
+		}
+
+		var decompressErr error
+		if decompressed, err := decompressBody(resp.Header.Get("Content-Encoding"), respBody, spec.MaxBytes); err != nil {
+			decompressErr = err // Fall back to the raw bytes
+		} else {
+			respBody = decompressed
+		}
+
+		contentType := resp.Header.Get("Content-Type")
+		// Binary bodies would flood the panel with control characters, so
+		// they're described instead
+		var formattedBody string
+		var jsonFormatted, binary bool
+		switch {
+		case isImageContentType(contentType):
+			formattedBody, binary = formatImage(contentType, respBody), true
+		case isBinaryBody(contentType, respBody):
+			formattedBody, binary = formatBinary(contentType, len(respBody)), true
+		default:
+			formattedBody, jsonFormatted = formatBody(contentType, decodeCharset(contentType, respBody), spec.AutoFormat)
+			formattedBody = sanitizeForTerminal(formattedBody)
+		}
+		if decompressErr != nil {
+			formattedBody = "Could not decompress response (" + decompressErr.Error() + "), showing raw bytes:\n" + formattedBody
+		}
+		if cutShort {
+			formattedBody = "Response cut short at " + formatBytes(spec.MaxBytes) + " (max_response_bytes):\n" + formattedBody
+		}
+
+		resultChan <- Response{
+			StatusCode:      resp.StatusCode,
+			Status:          resp.Status,
+			Headers:         resp.Header,
+			Body:            string(respBody),
+			FormattedBody:   formattedBody,
+			JSONFormatted:   jsonFormatted && decompressErr == nil,
+			Binary:          binary,
+			ResponseTime:    responseTime,
+			ContentLength:   contentLength,
+			WireSize:        wireSize,
+			DecodedSize:     int64(len(respBody)),
+			Truncated:       cutShort,
+			ContentEncoding: contentEncoding,
+			Proto:           resp.Proto,
+			TLS:             resp.TLS,
+			Timings:         tracer.timings(responseTime),
+			Informational:   tracer.informational(),
+			Redirects:       redirects,
+		}
+	}()
+
+	var started chan struct{}
+	if stream != nil {
+		started = stream.started
+	}
+	forced := time.NewTimer(spec.Timeout + 1*time.Second)
+	defer forced.Stop()
+	for {
+		select {
+		case res := <-resultChan:
+			res.Sent = sent
+			return res
+		case <-started:
+			// Streams run until they end or are stopped
+			forced.Stop()
+			started = nil
+		case <-forced.C:
+			return Response{
+				Error:        fmt.Errorf("forced timeout: request took longer than %v", spec.Timeout),
+				ResponseTime: time.Since(startTime),
+				Sent:         sent,
+			}
+		}
+	}
+}
+
+// classifyRequestError turns a transport error into a message for the
+// status line. timedOut is set when the request's deadline passed.
+func classifyRequestError(err error, timedOut bool, timeout time.Duration) error {
+	var errMsg string
+	switch {
+	case timedOut:
+		errMsg = fmt.Sprintf("Request timed out after %v. The server took too long to respond.", timeout)
+	case strings.Contains(err.Error(), "no such host"):
+		errMsg = "Could not resolve host. Please check the URL and your internet connection."
+	case strings.Contains(err.Error(), "connection refused"):
+		errMsg = "Connection refused. The server is not accepting connections."
+	case strings.Contains(err.Error(), "certificate"):
+		errMsg = "SSL/TLS certificate error. The server's security certificate could not be verified. For self-signed certificates in local development, set insecure_skip_verify in config.json."
+	case strings.Contains(err.Error(), "EOF"):
+		errMsg = "Connection closed unexpectedly. The server terminated the connection."
+	case strings.Contains(err.Error(), "i/o timeout"):
+		errMsg = "Connection timed out. The server is not responding."
+	case strings.Contains(err.Error(), "connection reset"):
+		errMsg = "Connection was reset. The server closed the connection abruptly."
+	default:
+		errMsg = "Request failed: " + err.Error()
+	}
+	return errors.New(errMsg)
+}
+
+// decodeCharset converts body to UTF-8 using the charset parameter of
+// contentType. Unknown charsets and bytes that don't decode are shown as
+// replacement characters.
+func decodeCharset(contentType string, body []byte) []byte {
+	encoding := "utf-8" // default
+	if idx := strings.LastIndex(contentType, "charset="); idx != -1 {
+		encoding = strings.TrimSpace(contentType[idx+8:])
+		if semicolon := strings.Index(encoding, ";"); semicolon != -1 {
+			encoding = encoding[:semicolon]
+		}
+	}
+
+	if encoding != "utf-8" && encoding != "UTF-8" {
+		if enc, err := htmlindex.Get(encoding); err == nil {
+			if decoded, _, err := transform.Bytes(enc.NewDecoder(), body); err == nil && utf8.Valid(decoded) {
+				return decoded
+			}
+		}
+	}
+
+	return []byte(strings.Map(func(r rune) rune {
+		if r == utf8.RuneError {
+			return '�'
+		}
+		return r
+	}, string(body)))
+}
+
+// formatBody renders a decoded body for the response panel according to
+// its content type, reporting whether the result is pretty-printed JSON.
+// The whole body is formatted, however large; the response panel only
+// renders the lines in view.
+func formatBody(contentType string, decodedBody []byte, format bool) (string, bool) {
+	if !format {
+		return string(decodedBody), false
+	}
+
+	switch {
+	case isNDJSONContentType(contentType):
+		pretty, err := formatNDJSON(decodedBody)
+		if err != nil {
+			return "Error formatting NDJSON: " + err.Error() + "\nRaw response:\n" + string(decodedBody), false
+		}
+		return pretty, true
+	case isJSONContentType(contentType):
+		var prettyJSON bytes.Buffer
+		if err := json.Indent(&prettyJSON, decodedBody, "", "  "); err != nil {
+			if looksLikeNDJSON(decodedBody) {
+				pretty, _ := formatNDJSON(decodedBody)
+				return pretty, true
+			}
+			return "Error formatting JSON: " + err.Error() + "\nRaw response:\n" + string(decodedBody), false
+		}
+		return prettyJSON.String(), true
+	case isXMLContentType(contentType):
+		pretty, err := formatXML(decodedBody, "  ")
+		if err != nil {
+			return "Error formatting XML: " + err.Error() + "\nRaw response:\n" + string(decodedBody), false
+		}
+		return pretty, false
+	case isYAMLContentType(contentType):
+		pretty, err := formatYAML(decodedBody, 2)
+		if err != nil {
+			return "Error formatting YAML: " + err.Error() + "\nRaw response:\n" + string(decodedBody), false
+		}
+		return pretty, false
+	case strings.Contains(contentType, "text/html"):
+		return "HTML Response:\n" + truncateString(string(decodedBody), 1000), false
+	case looksLikeNDJSON(decodedBody):
+		pretty, _ := formatNDJSON(decodedBody)
+		return pretty, true
+	}
+	return string(decodedBody), false
+}
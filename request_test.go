@@ -0,0 +1,139 @@
+package main
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDoRequest(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ok", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		w.Header().Set("X-Echo", r.Header.Get("X-Test"))
+		w.Write([]byte("hello"))
+	})
+	mux.HandleFunc("/missing", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "not here", http.StatusNotFound)
+	})
+	mux.HandleFunc("/slow", func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(2 * time.Second):
+		}
+	})
+	mux.HandleFunc("/latin1", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=iso-8859-1")
+		w.Write([]byte("caf\xe9"))
+	})
+	mux.HandleFunc("/large", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		// Flushing first sends the body chunked, without a Content-Length
+		w.(http.Flusher).Flush()
+		w.Write([]byte(strings.Repeat("x", 100)))
+	})
+	mux.HandleFunc("/declared-large", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(strings.Repeat("x", 100)))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	// A port nothing listens on
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	refusedURL := "http://" + ln.Addr().String() + "/"
+	ln.Close()
+
+	noDNS := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			return nil, &net.DNSError{Err: "no such host", Name: "api.example.invalid", IsNotFound: true}
+		},
+	}}
+
+	tests := []struct {
+		name          string
+		url           string
+		client        *http.Client
+		timeout       time.Duration
+		maxBytes      int64
+		wantStatus    int
+		wantHeader    string
+		wantBody      string
+		wantFormatted string
+		wantTruncated bool
+		wantErr       string
+	}{
+		{name: "ok", url: srv.URL + "/ok", wantStatus: http.StatusOK, wantHeader: "yes", wantBody: "hello"},
+		{name: "not found", url: srv.URL + "/missing", wantStatus: http.StatusNotFound, wantBody: "not here\n"},
+		{name: "timeout", url: srv.URL + "/slow", timeout: 50 * time.Millisecond, wantErr: "timed out after 50ms"},
+		{name: "charset", url: srv.URL + "/latin1", wantStatus: http.StatusOK, wantBody: "caf\xe9", wantFormatted: "café"},
+		{
+			name:          "cut short",
+			url:           srv.URL + "/large",
+			maxBytes:      16,
+			wantStatus:    http.StatusOK,
+			wantBody:      strings.Repeat("x", 16),
+			wantFormatted: "Response cut short at 16 B (max_response_bytes)",
+			wantTruncated: true,
+		},
+		{name: "declared too large", url: srv.URL + "/declared-large", maxBytes: 16, wantErr: "response too large"},
+		{name: "connection refused", url: refusedURL, wantErr: "Connection refused"},
+		{name: "dns failure", url: "http://api.example.invalid/", client: noDNS, wantErr: "Could not resolve host"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := tt.client
+			if client == nil {
+				client = srv.Client()
+			}
+			timeout := tt.timeout
+			if timeout == 0 {
+				timeout = 5 * time.Second
+			}
+			maxBytes := tt.maxBytes
+			if maxBytes == 0 {
+				maxBytes = defaultMaxResponseBytes
+			}
+
+			res := doRequest(context.Background(), client, RequestSpec{
+				Method:     http.MethodGet,
+				URL:        tt.url,
+				Headers:    http.Header{"X-Test": {"yes"}},
+				Timeout:    timeout,
+				AutoFormat: true,
+				MaxBytes:   maxBytes,
+			})
+			if tt.wantErr != "" {
+				if res.Error == nil || !strings.Contains(res.Error.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want it to mention %q", res.Error, tt.wantErr)
+				}
+				return
+			}
+			if res.Error != nil {
+				t.Fatalf("doRequest: %v", res.Error)
+			}
+			if res.StatusCode != tt.wantStatus {
+				t.Errorf("status = %d, want %d", res.StatusCode, tt.wantStatus)
+			}
+			if got := res.Headers.Get("X-Echo"); got != tt.wantHeader {
+				t.Errorf("X-Echo = %q, want %q", got, tt.wantHeader)
+			}
+			if res.Body != tt.wantBody {
+				t.Errorf("body = %q, want %q", res.Body, tt.wantBody)
+			}
+			if !strings.Contains(res.FormattedBody, tt.wantFormatted) {
+				t.Errorf("formatted body = %q, want it to contain %q", res.FormattedBody, tt.wantFormatted)
+			}
+			if res.Truncated != tt.wantTruncated {
+				t.Errorf("truncated = %v, want %v", res.Truncated, tt.wantTruncated)
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// formatSentRequest renders a request as it is sent: method and URL, then
+// headers and body.
+func formatSentRequest(st *styles, sent SentRequest) string {
+	var sb strings.Builder
+	sb.WriteString(st.statusSuccess.Render(sent.Method+" "+sent.URL) + "\n\n")
+
+	sb.WriteString("Headers:\n")
+	keys := make([]string, 0, len(sent.Headers))
+	for k := range sent.Headers {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		for _, v := range sent.Headers[k] {
+			sb.WriteString(fmt.Sprintf("%s: %s\n", k, v))
+		}
+	}
+
+	sb.WriteString("\nBody:\n")
+	if sent.BodyFile != "" {
+		sb.WriteString(st.help.Render("(contents of " + sent.BodyFile + ")"))
+	} else if sent.HasBody && sent.Body != "" {
+		sb.WriteString(sent.Body)
+	} else {
+		sb.WriteString(st.help.Render("(none)"))
+	}
+	return sb.String()
+}
+
+// reviewRequest is the request shown on the left in review mode: what was
+// actually sent for the current response, or the editor's effective
+// request before anything has been sent.
+func (m Model) reviewRequest() SentRequest {
+	if m.response.Sent.URL != "" {
+		return m.response.Sent
+	}
+
+	req := m.currentRequest()
+	sent := SentRequest{
+		Method:  req.Method,
+		URL:     m.effectiveURL(req),
+		Body:    m.lastBody,
+		HasBody: sendsBody(req.Method, m.configManager.allowGetBody(req.AllowGetBody)),
+	}
+	sent.Headers = make(map[string][]string, len(req.Headers))
+	for k, v := range req.Headers {
+		sent.Headers[k] = []string{v}
+	}
+	return sent
+}
+
+// toggleReview switches between the editor layout and the side-by-side
+// request/response review layout.
+func (m Model) toggleReview() (tea.Model, tea.Cmd) {
+	m.reviewMode = !m.reviewMode
+	m.reviewLeft = false
+	m.updatePanelSizes()
+	if m.reviewMode {
+		m.requestView.SetContent(formatSentRequest(&m.styles, m.reviewRequest()))
+		m.requestView.GotoTop()
+	}
+	return m, nil
+}
+
+// updateReview handles keys in review mode: tab switches the scrolled
+// pane, everything else scrolls it.
+func (m Model) updateReview(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, keys.Quit):
+		return m.quit()
+
+	case key.Matches(msg, keys.Cancel), key.Matches(msg, keys.Review):
+		return m.toggleReview()
+
+	case key.Matches(msg, keys.Tab), key.Matches(msg, keys.ShiftTab):
+		m.reviewLeft = !m.reviewLeft
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	if m.reviewLeft {
+		m.requestView, cmd = m.requestView.Update(msg)
+	} else {
+		m.responseView, cmd = m.responseView.Update(msg)
+	}
+	return m, cmd
+}
+
+// renderReview lays out the request and response side by side.
+func (m Model) renderReview() string {
+	leftStyle, rightStyle := m.styles.blurred, m.styles.focused
+	if m.reviewLeft {
+		leftStyle, rightStyle = m.styles.focused, m.styles.blurred
+	}
+
+	responseContent := "No response yet"
+	if m.loading {
+		responseContent = fmt.Sprintf("%s Sending request...", m.spinner.View())
+	} else if m.response.StatusCode > 0 || m.response.Error != nil {
+		responseContent = m.responseView.View()
+	}
+
+	left := leftStyle.Width(m.requestView.Width).Render("Request\n" + m.requestView.View())
+	right := rightStyle.Width(m.responseView.Width).Render("Response\n" + responseContent)
+	return lipgloss.JoinHorizontal(lipgloss.Top, left, right)
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// savedRequest is a request from a collection as listed in the saved
+// requests browser.
+type savedRequest struct {
+	Collection string
+	Request    RequestItem
+	Tags       []string
+}
+
+func (s savedRequest) Title() string {
+	if s.Request.Name != "" {
+		return s.Request.Name
+	}
+	return s.Request.Method + " " + s.Request.URL
+}
+
+func (s savedRequest) Description() string {
+	desc := fmt.Sprintf("%s %s • %s", s.Request.Method, s.Request.URL, s.Collection)
+	if len(s.Tags) > 0 {
+		desc += " [" + strings.Join(s.Tags, ", ") + "]"
+	}
+	return desc
+}
+
+func (s savedRequest) FilterValue() string {
+	return s.Request.Name + " " + s.Request.Method + " " + s.Request.URL + " " + strings.Join(s.Tags, " ")
+}
+
+// matchesEnvironment reports whether something tagged with tags is relevant
+// to the named environment. Untagged items are relevant everywhere.
+func matchesEnvironment(tags []string, env string) bool {
+	if len(tags) == 0 {
+		return true
+	}
+	for _, tag := range tags {
+		if strings.EqualFold(tag, env) {
+			return true
+		}
+	}
+	return false
+}
+
+// savedRequests lists every request in every collection, ordered by
+// collection name. A request's tags include its collection's tags. Unless
+// all is set, only requests relevant to env are returned.
+func (cm *ConfigManager) savedRequests(env string, all bool) []savedRequest {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+
+	var result []savedRequest
+	for _, name := range cm.collectionNamesLocked() {
+		collection := cm.Collections[name]
+		for _, req := range collection.Requests {
+			tags := append(append([]string{}, collection.Tags...), req.Tags...)
+			if !all && !matchesEnvironment(tags, env) {
+				continue
+			}
+			result = append(result, savedRequest{Collection: name, Request: req, Tags: tags})
+		}
+	}
+	return result
+}
+
+// isSaved reports whether a request with the same method, URL and body is
+// already in some collection.
+func (cm *ConfigManager) isSaved(req RequestItem) bool {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+
+	for _, collection := range cm.Collections {
+		for _, item := range collection.Requests {
+			if strings.EqualFold(item.Method, req.Method) && item.URL == req.URL && item.Body == req.Body {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// GetAvailableCollectionNames returns the names of all collections, sorted.
+func (cm *ConfigManager) GetAvailableCollectionNames() []string {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+	return cm.collectionNamesLocked()
+}
+
+func (cm *ConfigManager) collectionNamesLocked() []string {
+	names := make([]string, 0, len(cm.Collections))
+	for name := range cm.Collections {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+)
+
+// maxSchemaViolations caps the violations listed in the response panel.
+const maxSchemaViolations = 20
+
+// SchemaViolation is one place where a response breaks its schema.
+type SchemaViolation struct {
+	// Location is a JSON pointer into the response body, "" for the root.
+	Location string
+	Message  string
+}
+
+// SchemaResult is the outcome of checking a response body against the
+// request's JSON Schema. Err is set when the check couldn't run at all:
+// the schema didn't load or the body isn't JSON.
+type SchemaResult struct {
+	Path       string
+	Err        error
+	Violations []SchemaViolation
+}
+
+func (r SchemaResult) passed() bool { return r.Err == nil && len(r.Violations) == 0 }
+
+// compileSchema loads and compiles the JSON Schema at path. A leading ~ is
+// the home directory; $refs to other files resolve relative to it.
+func compileSchema(path string) (*jsonschema.Schema, error) {
+	schema, err := jsonschema.NewCompiler().Compile(expandHome(path))
+	if err != nil {
+		return nil, fmt.Errorf("can't load schema %s: %w", path, err)
+	}
+	return schema, nil
+}
+
+// validateSchema checks body against the JSON Schema at path. The schema
+// is read again for every response, so edits to it apply straight away.
+func validateSchema(path, body string) *SchemaResult {
+	result := &SchemaResult{Path: path}
+	schema, err := compileSchema(path)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	doc, err := jsonschema.UnmarshalJSON(strings.NewReader(body))
+	if err != nil {
+		result.Err = fmt.Errorf("response body isn't JSON: %w", err)
+		return result
+	}
+
+	err = schema.Validate(doc)
+	if err == nil {
+		return result
+	}
+	validationErr, ok := err.(*jsonschema.ValidationError)
+	if !ok {
+		result.Err = err
+		return result
+	}
+	result.Violations = schemaViolations(validationErr.DetailedOutput())
+	return result
+}
+
+// schemaViolations flattens the validator's output to the errors that say
+// what's wrong, skipping the ones that only group others, such as "allOf
+// failed".
+func schemaViolations(unit *jsonschema.OutputUnit) []SchemaViolation {
+	var violations []SchemaViolation
+	var walk func(u jsonschema.OutputUnit)
+	walk = func(u jsonschema.OutputUnit) {
+		if u.Error != nil && len(u.Errors) == 0 {
+			violations = append(violations, SchemaViolation{Location: u.InstanceLocation, Message: u.Error.String()})
+		}
+		for _, child := range u.Errors {
+			walk(child)
+		}
+	}
+	walk(*unit)
+	return violations
+}
+
+// formatSchemaResult renders the schema check as a block of its own: a
+// pass or fail line naming the schema, then each violation with where in
+// the body it is.
+func formatSchemaResult(st *styles, r *SchemaResult) string {
+	if r == nil {
+		return ""
+	}
+	var sb strings.Builder
+	switch {
+	case r.Err != nil:
+		sb.WriteString(st.error.Render("Schema: ✗ "+r.Err.Error()) + "\n")
+	case r.passed():
+		sb.WriteString(st.status2xx.Render("Schema: ✓ passed ("+r.Path+")") + "\n")
+	default:
+		sb.WriteString(st.error.Bold(true).Render(fmt.Sprintf("Schema: ✗ %d violation(s) (%s)", len(r.Violations), r.Path)) + "\n")
+		for i, v := range r.Violations {
+			if i == maxSchemaViolations {
+				sb.WriteString(st.help.Render(fmt.Sprintf("  … %d more", len(r.Violations)-i)) + "\n")
+				break
+			}
+			location := v.Location
+			if location == "" {
+				location = "(root)"
+			}
+			sb.WriteString(st.error.Render("  • "+location+": "+v.Message) + "\n")
+		}
+	}
+	return sb.String()
+}
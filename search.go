@@ -0,0 +1,116 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+// searchMatch is one occurrence of the search query in the response view,
+// as a line number and a byte range within that line.
+type searchMatch struct {
+	Line  int
+	Start int
+	End   int
+}
+
+// findMatches returns every occurrence of query in content, ignoring
+// case and any styling. Matches don't overlap.
+func findMatches(content, query string) []searchMatch {
+	if query == "" {
+		return nil
+	}
+	query = strings.ToLower(query)
+
+	var matches []searchMatch
+	for i, line := range strings.Split(ansi.Strip(content), "\n") {
+		lower := strings.ToLower(line)
+		if len(lower) != len(line) {
+			// Lowercasing changed the byte length, so offsets wouldn't line
+			// up; fall back to a case-sensitive search on this line
+			lower = line
+		}
+		for from := 0; from < len(lower); {
+			idx := strings.Index(lower[from:], query)
+			if idx < 0 {
+				break
+			}
+			start := from + idx
+			matches = append(matches, searchMatch{Line: i, Start: start, End: start + len(query)})
+			from = start + len(query)
+		}
+	}
+	return matches
+}
+
+// highlightMatches strips styling from content and paints each match with
+// a background, the one at current more strongly than the rest.
+func highlightMatches(st *styles, content string, matches []searchMatch, current int) string {
+	lines := strings.Split(ansi.Strip(content), "\n")
+	byLine := make(map[int][]int)
+	for i, mt := range matches {
+		byLine[mt.Line] = append(byLine[mt.Line], i)
+	}
+
+	for n, idxs := range byLine {
+		if n >= len(lines) {
+			continue
+		}
+		line := lines[n]
+		var sb strings.Builder
+		last := 0
+		for _, i := range idxs {
+			mt := matches[i]
+			style := st.searchMatch
+			if i == current {
+				style = st.searchCurrent
+			}
+			sb.WriteString(line[last:mt.Start])
+			sb.WriteString(style.Render(line[mt.Start:mt.End]))
+			last = mt.End
+		}
+		sb.WriteString(line[last:])
+		lines[n] = sb.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// setSearch runs query against the response and jumps to the first match.
+// An empty query clears the search.
+func (m *Model) setSearch(query string) {
+	m.searchQuery = query
+	m.searchMatches = nil
+	m.searchIndex = 0
+	m.refreshResponseView()
+	if query == "" {
+		return
+	}
+	if len(m.searchMatches) == 0 {
+		m.statusMessage = fmt.Sprintf("No matches for %q", query)
+		return
+	}
+	m.jumpToMatch(0)
+}
+
+// clearSearch drops the search and restores the normal rendering.
+func (m *Model) clearSearch() {
+	m.searchQuery = ""
+	m.searchMatches = nil
+	m.searchIndex = 0
+	m.refreshResponseView()
+}
+
+// jumpToMatch makes match i current, wrapping around at either end, and
+// scrolls the response view so it sits in the middle.
+func (m *Model) jumpToMatch(i int) {
+	n := len(m.searchMatches)
+	if n == 0 {
+		return
+	}
+	i = ((i % n) + n) % n
+	m.searchIndex = i
+	m.refreshResponseView()
+	m.responseView.SetYOffset(m.searchMatches[i].Line - m.responseView.Height/2)
+	m.statusMessage = fmt.Sprintf("Match %d of %d for %q", i+1, n, m.searchQuery)
+}
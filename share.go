@@ -0,0 +1,157 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// shareLinkPrefix marks a string as a request share link. Decoding also
+// accepts the bare base64 payload so links survive chat clients that strip
+// the prefix.
+const shareLinkPrefix = "api-client-tui://"
+
+// sharedRequest is the compact wire form of a RequestItem inside a share
+// link. Only the fields needed to rebuild the request are included, under
+// one-letter keys to keep links short.
+type sharedRequest struct {
+	Name       string            `json:"n,omitempty"`
+	URL        string            `json:"u"`
+	Method     string            `json:"m"`
+	Headers    map[string]string `json:"h,omitempty"`
+	Body       string            `json:"b,omitempty"`
+	Query      url.Values        `json:"q,omitempty"`
+	Auth       *AuthConfig       `json:"a,omitempty"`
+	GraphQL    bool              `json:"g,omitempty"`
+	Variables  string            `json:"v,omitempty"`
+	SchemaPath string            `json:"s,omitempty"`
+	Timeout    int               `json:"t,omitempty"`
+}
+
+// encodeShareLink serializes req into a single copy-pasteable string.
+func encodeShareLink(req RequestItem) (string, error) {
+	payload, err := json.Marshal(sharedRequest{
+		Name:       req.Name,
+		URL:        req.URL,
+		Method:     req.Method,
+		Headers:    req.Headers,
+		Body:       req.Body,
+		Query:      req.Query,
+		Auth:       req.Auth,
+		GraphQL:    req.GraphQL,
+		Variables:  req.Variables,
+		SchemaPath: req.SchemaPath,
+		Timeout:    req.Timeout,
+	})
+	if err != nil {
+		return "", err
+	}
+	return shareLinkPrefix + base64.RawURLEncoding.EncodeToString(payload), nil
+}
+
+// decodeShareLink parses a link produced by encodeShareLink.
+func decodeShareLink(link string) (RequestItem, error) {
+	encoded := strings.TrimPrefix(strings.TrimSpace(link), shareLinkPrefix)
+	if encoded == "" {
+		return RequestItem{}, errors.New("share link is empty")
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(encoded, "="))
+	if err != nil {
+		return RequestItem{}, fmt.Errorf("invalid share link: %w", err)
+	}
+
+	var shared sharedRequest
+	if err := json.Unmarshal(payload, &shared); err != nil {
+		return RequestItem{}, fmt.Errorf("invalid share link: %w", err)
+	}
+	if shared.URL == "" {
+		return RequestItem{}, errors.New("invalid share link: missing URL")
+	}
+	if shared.Method == "" {
+		shared.Method = httpMethods[0]
+	}
+
+	return RequestItem{
+		Name:       shared.Name,
+		URL:        shared.URL,
+		Method:     strings.ToUpper(shared.Method),
+		Headers:    shared.Headers,
+		Body:       shared.Body,
+		Query:      shared.Query,
+		Auth:       shared.Auth,
+		GraphQL:    shared.GraphQL,
+		Variables:  shared.Variables,
+		SchemaPath: shared.SchemaPath,
+		Timeout:    shared.Timeout,
+	}, nil
+}
+
+// redactSecrets replaces any resolved environment variable values found in
+// the request with their {{KEY}} placeholders, so a shared request doesn't
+// leak the sender's tokens.
+func redactSecrets(req RequestItem, vars map[string]string) RequestItem {
+	if len(vars) == 0 {
+		return req
+	}
+	redact := secretRedactor(vars)
+
+	headers := make(map[string]string, len(req.Headers))
+	for k, v := range req.Headers {
+		headers[k] = redact(v)
+	}
+
+	if req.Query != nil {
+		query := make(url.Values, len(req.Query))
+		for k, values := range req.Query {
+			for _, v := range values {
+				query.Add(k, redact(v))
+			}
+		}
+		req.Query = query
+	}
+	if req.Auth != nil {
+		auth := *req.Auth
+		auth.Token = redact(auth.Token)
+		auth.Username = redact(auth.Username)
+		auth.Password = redact(auth.Password)
+		req.Auth = &auth
+	}
+
+	req.Name = redact(req.Name)
+	req.URL = redact(req.URL)
+	req.Headers = headers
+	req.Body = redact(req.Body)
+	req.Variables = redact(req.Variables)
+	return req
+}
+
+// secretRedactor returns a function replacing the values of vars with their
+// {{NAME}} placeholders. Longer values are replaced first so a value that
+// contains another is redacted as a whole.
+func secretRedactor(vars map[string]string) func(string) string {
+	keys := make([]string, 0, len(vars))
+	for k, v := range vars {
+		// Very short values would match all over the place
+		if len(v) >= 4 {
+			keys = append(keys, k)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if len(vars[keys[i]]) != len(vars[keys[j]]) {
+			return len(vars[keys[i]]) > len(vars[keys[j]])
+		}
+		return keys[i] < keys[j]
+	})
+
+	return func(s string) string {
+		for _, k := range keys {
+			s = strings.ReplaceAll(s, vars[k], "{{"+k+"}}")
+		}
+		return s
+	}
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"encoding/base64"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestShareLinkRoundTrip(t *testing.T) {
+	req := RequestItem{
+		Name:       "Create user",
+		URL:        "https://api.example.com/users",
+		Method:     "POST",
+		Headers:    map[string]string{"Content-Type": "application/json"},
+		Body:       `{"name":"ada"}`,
+		Query:      url.Values{"page": {"2"}, "tag": {"a", "b"}},
+		Auth:       &AuthConfig{Type: authBearer, Token: "abc123"},
+		GraphQL:    true,
+		Variables:  `{"id":1}`,
+		SchemaPath: "schemas/user.json",
+		Timeout:    15,
+	}
+
+	link, err := encodeShareLink(req)
+	if err != nil {
+		t.Fatalf("encodeShareLink: %v", err)
+	}
+	got, err := decodeShareLink(link)
+	if err != nil {
+		t.Fatalf("decodeShareLink: %v", err)
+	}
+	if !reflect.DeepEqual(got, req) {
+		t.Errorf("round trip = %+v, want %+v", got, req)
+	}
+}
+
+func TestRedactSecrets(t *testing.T) {
+	vars := map[string]string{"TOKEN": "s3cret-token", "PASS": "hunter22"}
+	req := RequestItem{
+		URL:       "https://api.example.com/?key=s3cret-token",
+		Headers:   map[string]string{"X-Token": "s3cret-token"},
+		Body:      "s3cret-token",
+		Query:     url.Values{"key": {"s3cret-token"}},
+		Auth:      &AuthConfig{Type: authBasic, Username: "me", Password: "hunter22"},
+		Variables: `{"token":"s3cret-token"}`,
+	}
+
+	got := redactSecrets(req, vars)
+	want := RequestItem{
+		URL:       "https://api.example.com/?key={{TOKEN}}",
+		Headers:   map[string]string{"X-Token": "{{TOKEN}}"},
+		Body:      "{{TOKEN}}",
+		Query:     url.Values{"key": {"{{TOKEN}}"}},
+		Auth:      &AuthConfig{Type: authBasic, Username: "me", Password: "{{PASS}}"},
+		Variables: `{"token":"{{TOKEN}}"}`,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("redactSecrets = %+v, want %+v", got, want)
+	}
+	if req.Auth.Password != "hunter22" {
+		t.Errorf("redactSecrets modified the original auth: %+v", req.Auth)
+	}
+}
+
+func TestShareLinkNeverContainsToken(t *testing.T) {
+	const token = "dev-secret-123"
+	// Repeat so a current environment that depends on map order shows up
+	for i := 0; i < 50; i++ {
+		m := initialModel(t.TempDir())
+		m.configManager.Config.CurrentEnv = ""
+		m.configManager.Config.RedactSharedSecrets = true
+		m.configManager.Environments = map[string]Environment{
+			"development": {Name: "development", Variables: map[string]string{"TOKEN": token}},
+			"production":  {Name: "production", Variables: map[string]string{"TOKEN": "prod-secret-456"}},
+		}
+		m.urlInput.SetValue("https://api.example.com/items?key=" + token)
+		m.headersInput.SetValue("Authorization: Bearer " + token)
+		m.bodyInput.SetValue(`{"token":"` + token + `"}`)
+		m.auth = &AuthConfig{Type: authBearer, Token: token}
+
+		link, err := m.shareLink()
+		if err != nil {
+			t.Fatalf("shareLink: %v", err)
+		}
+		payload, err := base64.RawURLEncoding.DecodeString(strings.TrimPrefix(link, shareLinkPrefix))
+		if err != nil {
+			t.Fatalf("decoding link: %v", err)
+		}
+		if strings.Contains(string(payload), token) {
+			t.Fatalf("attempt %d: share link contains the token: %s", i, payload)
+		}
+		if !strings.Contains(string(payload), "{{TOKEN}}") {
+			t.Fatalf("attempt %d: share link has no placeholder: %s", i, payload)
+		}
+	}
+}
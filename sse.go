@@ -0,0 +1,179 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// sseEvent is one server-sent event.
+type sseEvent struct {
+	At    time.Time
+	Event string
+	ID    string
+	Data  string
+}
+
+// sseStream carries a text/event-stream response from doRequest to
+// Update while it's still arriving. head holds the status and headers and
+// is set before started is closed; events is closed when the stream ends,
+// after which the complete response arrives on done. shown is the log
+// Update keeps for the response panel, and is only touched by Update.
+type sseStream struct {
+	head    Response
+	started chan struct{}
+	events  chan sseEvent
+	done    chan Response
+	shown   eventLog
+}
+
+// eventLog holds the newest events of a stream whose data fits in limit
+// bytes, dropping the oldest, so a long-lived stream doesn't grow without
+// bound.
+type eventLog struct {
+	events []sseEvent
+	size   int64
+	limit  int64
+}
+
+func (l *eventLog) add(e sseEvent) {
+	l.events = append(l.events, e)
+	l.size += int64(len(e.Data))
+	for l.size > l.limit && len(l.events) > 1 {
+		l.size -= int64(len(l.events[0].Data))
+		l.events = l.events[1:]
+	}
+}
+
+type sseStartMsg struct {
+	stream *sseStream
+}
+
+type sseEventMsg struct {
+	stream *sseStream
+	event  sseEvent
+}
+
+func newSSEStream(limit int64) *sseStream {
+	return &sseStream{
+		started: make(chan struct{}),
+		events:  make(chan sseEvent, 64),
+		done:    make(chan Response, 1),
+		shown:   eventLog{limit: limit},
+	}
+}
+
+type sseStreamKey struct{}
+
+// withSSEStream asks doRequest to stream an event-stream response to s
+// rather than buffering it. Other responses are read as usual.
+func withSSEStream(ctx context.Context, s *sseStream) context.Context {
+	return context.WithValue(ctx, sseStreamKey{}, s)
+}
+
+func sseStreamFrom(ctx context.Context) *sseStream {
+	s, _ := ctx.Value(sseStreamKey{}).(*sseStream)
+	return s
+}
+
+// isEventStream reports whether contentType is text/event-stream.
+func isEventStream(contentType string) bool {
+	mediaType := strings.ToLower(strings.TrimSpace(strings.Split(contentType, ";")[0]))
+	return mediaType == "text/event-stream"
+}
+
+// readEventStream parses server-sent events from body, passing each to
+// stream.events as it completes, until the body ends or ctx is cancelled.
+// It returns the raw text read (up to limit bytes), the newest events
+// whose data fits in limit bytes, and how the stream ended for the
+// response panel.
+func readEventStream(ctx context.Context, body io.Reader, stream *sseStream, limit int64) (string, []sseEvent, string) {
+	defer close(stream.events)
+
+	var raw strings.Builder
+	events := eventLog{limit: limit}
+	var current sseEvent
+	var data []string
+	dispatch := func() {
+		if len(data) > 0 {
+			current.At = time.Now()
+			current.Data = strings.Join(data, "\n")
+			events.add(current)
+			stream.events <- current
+		}
+		current, data = sseEvent{ID: current.ID}, nil
+	}
+
+	reader := bufio.NewReader(body)
+	for {
+		line, err := reader.ReadString('\n')
+		if int64(raw.Len()) < limit {
+			raw.WriteString(line)
+		}
+		if line != "" {
+			line = strings.TrimRight(line, "\r\n")
+			switch field, value, _ := strings.Cut(line, ":"); {
+			case line == "":
+				dispatch()
+			case field == "":
+				// A comment, often sent as a keep-alive
+			case field == "data":
+				data = append(data, strings.TrimPrefix(value, " "))
+			case field == "event":
+				current.Event = strings.TrimPrefix(value, " ")
+			case field == "id":
+				current.ID = strings.TrimPrefix(value, " ")
+			}
+		}
+		if err != nil {
+			dispatch()
+			switch {
+			case err == io.EOF:
+				return raw.String(), events.events, "ended"
+			case ctx.Err() == context.Canceled:
+				return raw.String(), events.events, "stopped"
+			default:
+				return raw.String(), events.events, "interrupted: " + err.Error()
+			}
+		}
+	}
+}
+
+// waitForEvent returns a command that delivers the stream's next event,
+// or its complete response once it has ended.
+func waitForEvent(s *sseStream) tea.Cmd {
+	return func() tea.Msg {
+		if event, ok := <-s.events; ok {
+			return sseEventMsg{stream: s, event: event}
+		}
+		return <-s.done
+	}
+}
+
+// formatEvents lists server-sent events, one per line with the time it
+// arrived and its type when it isn't the default "message".
+func formatEvents(events []sseEvent, end string) string {
+	var sb strings.Builder
+	title := fmt.Sprintf("Events (%d", len(events))
+	if end == "" {
+		title += ", streaming… esc to stop"
+	} else {
+		title += ", " + end
+	}
+	sb.WriteString(title + "):\n")
+	for _, e := range events {
+		line := e.At.Format("15:04:05.000") + " "
+		if e.Event != "" && e.Event != "message" {
+			line += "[" + sanitizeForTerminal(e.Event) + "] "
+		}
+		// Multi-line data continues under the first line
+		indent := "\n" + strings.Repeat(" ", len(line))
+		sb.WriteString(line + strings.ReplaceAll(sanitizeForTerminal(e.Data), "\n", indent) + "\n")
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReadEventStreamKeepsNewestWithinLimit(t *testing.T) {
+	var body strings.Builder
+	for i := 0; i < 500; i++ {
+		fmt.Fprintf(&body, "data: event-%03d\n\n", i)
+	}
+	stream := newSSEStream(100)
+	go func() {
+		for range stream.events {
+		}
+	}()
+
+	_, events, end := readEventStream(context.Background(), strings.NewReader(body.String()), stream, 100)
+	if end != "ended" {
+		t.Errorf("end = %q, want ended", end)
+	}
+	// Each event's data is 9 bytes, so 11 fit in 100
+	if len(events) != 11 {
+		t.Fatalf("kept %d events, want 11", len(events))
+	}
+	if first, last := events[0].Data, events[len(events)-1].Data; first != "event-489" || last != "event-499" {
+		t.Errorf("kept %s..%s, want event-489..event-499", first, last)
+	}
+}
+
+func TestFormatEventsSanitizes(t *testing.T) {
+	events := []sseEvent{{At: time.Now(), Event: "tick\x1b[31m", Data: "a\x1b]0;pwned\x07b"}}
+	out := formatEvents(events, "ended")
+	if strings.ContainsAny(out, "\x1b\x07") {
+		t.Errorf("events contain control characters: %q", out)
+	}
+}
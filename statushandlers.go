@@ -0,0 +1,172 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Status handler actions.
+const (
+	statusActionRetry   = "retry"
+	statusActionRequest = "request"
+	statusActionMessage = "message"
+)
+
+// StatusAction is what to do when a response comes back with a given
+// status code, configured in Config.StatusHandlers. For example:
+//
+//	"401": {"action": "request", "request": "Auth/Refresh token", "retry": true}
+//	"429": {"action": "retry", "delay": 5, "max_attempts": 3}
+//	"503": {"action": "message", "message": "Service is down for maintenance"}
+type StatusAction struct {
+	Action string `json:"action"`
+	// Request names the saved request run by the "request" action, either
+	// "Collection/Name" or just the request name.
+	Request string `json:"request,omitempty"`
+	// Retry resends the original request after the "request" action, with
+	// {{response.*}} placeholders filled from the handler's response.
+	Retry bool `json:"retry,omitempty"`
+	// Delay is the wait in seconds before retrying. A Retry-After header
+	// on the response takes precedence.
+	Delay       int    `json:"delay,omitempty"`
+	MaxAttempts int    `json:"max_attempts,omitempty"`
+	Message     string `json:"message,omitempty"`
+}
+
+func (a StatusAction) maxAttempts() int {
+	if a.MaxAttempts > 0 {
+		return a.MaxAttempts
+	}
+	return 1
+}
+
+// retryAfter returns how long to wait before retrying resp: the
+// Retry-After header if present (seconds or an HTTP date), otherwise the
+// configured delay.
+func retryAfter(resp Response, fallback int) time.Duration {
+	if v := strings.TrimSpace(resp.Headers.Get("Retry-After")); v != "" {
+		if secs, err := strconv.Atoi(v); err == nil && secs >= 0 {
+			return time.Duration(secs) * time.Second
+		}
+		if t, err := http.ParseTime(v); err == nil {
+			if d := time.Until(t); d > 0 {
+				return d
+			}
+			return 0
+		}
+	}
+	return time.Duration(fallback) * time.Second
+}
+
+// findSavedRequest looks up a saved request by "Collection/Name" or, failing
+// that, by name alone across all collections.
+func (cm *ConfigManager) findSavedRequest(ref string) (RequestItem, bool) {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+
+	if collection, name, ok := strings.Cut(ref, "/"); ok {
+		for _, req := range cm.Collections[collection].Requests {
+			if req.Name == name {
+				return req, true
+			}
+		}
+	}
+	for _, collection := range cm.collectionNamesLocked() {
+		for _, req := range cm.Collections[collection].Requests {
+			if req.Name == ref {
+				return req, true
+			}
+		}
+	}
+	return RequestItem{}, false
+}
+
+// handleStatus runs the configured handler for resp's status code. It
+// returns a command when the handler sends another request. Handlers that
+// send are limited to MaxAttempts per user-initiated send so they can't
+// loop.
+func (m *Model) handleStatus(resp Response) (tea.Cmd, bool) {
+	if m.configManager == nil || resp.Error != nil || resp.Request.URL == "" {
+		return nil, false
+	}
+	action, ok := m.configManager.Config.StatusHandlers[resp.StatusCode]
+	if !ok {
+		return nil, false
+	}
+
+	switch action.Action {
+	case statusActionMessage:
+		m.statusMessage = action.Message
+		return nil, false
+
+	case statusActionRetry, statusActionRequest:
+		if m.handlerAttempts >= action.maxAttempts() {
+			m.statusMessage = fmt.Sprintf("Status handler for %d gave up after %d attempt(s)", resp.StatusCode, m.handlerAttempts)
+			return nil, false
+		}
+		m.handlerAttempts++
+	default:
+		m.requestError = fmt.Errorf("unknown status handler action %q for %d", action.Action, resp.StatusCode)
+		return nil, false
+	}
+
+	original := resp.Request
+	delay := retryAfter(resp, action.Delay)
+
+	if action.Action == statusActionRetry {
+		m.loading = true
+		m.statusMessage = fmt.Sprintf("Got %d, retrying in %v (attempt %d)", resp.StatusCode, delay, m.handlerAttempts)
+		return m.sendRequestAfter(m.newRequestContext(), original, delay), true
+	}
+
+	hook, found := m.configManager.findSavedRequest(action.Request)
+	if !found {
+		m.requestError = fmt.Errorf("status handler for %d: saved request %q not found", resp.StatusCode, action.Request)
+		return nil, false
+	}
+
+	m.loading = true
+	m.statusMessage = fmt.Sprintf("Got %d, running %q", resp.StatusCode, action.Request)
+	ctx := m.newRequestContext()
+	retryModel := *m
+	return func() tea.Msg {
+		hookResp := m.executeRequest(ctx, hook, false)
+		if !action.Retry {
+			hookResp.Request = hook
+			return hookResp
+		}
+		if hookResp.Error != nil || hookResp.StatusCode >= 400 {
+			hookResp.Request = hook
+			if hookResp.Error == nil {
+				hookResp.Error = fmt.Errorf("status handler request %q failed with %s", action.Request, hookResp.Status)
+			}
+			return hookResp
+		}
+		if err := sleepContext(ctx, delay); err != nil {
+			return Response{Error: errRequestCancelled, Request: original}
+		}
+		// The retry must see the refreshed credentials, not the error body
+		retryModel.lastJSON = parseResponseJSON(hookResp.Body)
+		retried := retryModel.executeRequest(ctx, original, true)
+		retried.Request = original
+		return retried
+	}, true
+}
+
+// sendRequestAfter sends reqItem once delay has passed.
+func (m Model) sendRequestAfter(ctx context.Context, reqItem RequestItem, delay time.Duration) tea.Cmd {
+	return func() tea.Msg {
+		if err := sleepContext(ctx, delay); err != nil {
+			return Response{Error: errRequestCancelled, Request: reqItem}
+		}
+		resp := m.executeRequest(ctx, reqItem, true)
+		resp.Request = reqItem
+		return resp
+	}
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestStatusHandlerRetryUsesRefreshedToken(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/refresh", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"access_token":"new-token"}`))
+	})
+	mux.HandleFunc("/me", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer new-token" {
+			http.Error(w, `{"error":"expired"}`, http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"ok":true}`))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	m := initialModel(t.TempDir())
+	m.configManager.Config.CurrentEnv = "development"
+	m.configManager.Config.StatusHandlers = map[int]StatusAction{
+		http.StatusUnauthorized: {Action: statusActionRequest, Request: "Auth/Refresh", Retry: true},
+	}
+	if err := m.configManager.addToCollection("Auth", RequestItem{Name: "Refresh", Method: http.MethodPost, URL: srv.URL + "/refresh"}); err != nil {
+		t.Fatal(err)
+	}
+	// The stale body of the 401 is what the placeholder would otherwise see
+	m.lastJSON = parseResponseJSON(`{"access_token":"old-token"}`)
+
+	original := RequestItem{
+		Method:  http.MethodGet,
+		URL:     srv.URL + "/me",
+		Headers: map[string]string{"Authorization": "Bearer {{response.access_token}}"},
+	}
+	cmd, handled := m.handleStatus(Response{StatusCode: http.StatusUnauthorized, Request: original})
+	if !handled || cmd == nil {
+		t.Fatal("401 handler didn't run")
+	}
+
+	res, ok := cmd().(Response)
+	if !ok {
+		t.Fatal("handler didn't return a response")
+	}
+	if res.Error != nil {
+		t.Fatalf("retry failed: %v", res.Error)
+	}
+	if res.StatusCode != http.StatusOK {
+		t.Errorf("retry status = %d, want %d; sent Authorization %q", res.StatusCode, http.StatusOK, res.Sent.Headers.Get("Authorization"))
+	}
+}
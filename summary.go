@@ -0,0 +1,52 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// renderSummary evaluates a text/template against the decoded JSON body,
+// e.g. "{{.id}}: {{.status}}". Missing fields render as "-" and template
+// or body problems are reported in the summary itself rather than failing
+// the response. An empty template or a non-JSON body yields no summary.
+func renderSummary(tmpl string, body []byte) string {
+	if strings.TrimSpace(tmpl) == "" {
+		return ""
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	var data interface{}
+	if err := dec.Decode(&data); err != nil {
+		return ""
+	}
+
+	t, err := template.New("summary").Option("missingkey=default").Parse(tmpl)
+	if err != nil {
+		return fmt.Sprintf("template error: %v", err)
+	}
+
+	var sb strings.Builder
+	if err := t.Execute(&sb, data); err != nil {
+		return fmt.Sprintf("template error: %v", err)
+	}
+	return strings.ReplaceAll(sb.String(), "<no value>", "-")
+}
+
+// summaryTemplate returns the template for a request: its own if set,
+// otherwise the global one from config.
+func (cm *ConfigManager) summaryTemplate(perRequest string) string {
+	if perRequest != "" {
+		return perRequest
+	}
+	if cm == nil {
+		return ""
+	}
+
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+	return cm.Config.SummaryTemplate
+}
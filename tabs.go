@@ -0,0 +1,148 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+)
+
+const tabLabelWidth = 24
+
+// requestTab is a tab's editor and response while another tab is active.
+// The active tab lives in the editor widgets themselves and its entry in
+// Model.tabs is only brought up to date when switching away.
+type requestTab struct {
+	Editor      RecoveryState
+	Request     RequestItem // Settings the editor doesn't show as text
+	LastBody    string
+	Response    Response
+	Bookmarks   []Bookmark
+	YOffset     int
+	ActivePanel int
+}
+
+// label names the tab in the tab bar after its method and URL.
+func (t requestTab) label() string {
+	if t.Editor.URL == "" {
+		return "New tab"
+	}
+	return ansi.Truncate(t.Editor.Method+" "+t.Editor.URL, tabLabelWidth, "…")
+}
+
+// snapshotTab captures the editor and response as a requestTab.
+func (m Model) snapshotTab() requestTab {
+	return requestTab{
+		Editor:      m.recoveryState(),
+		Request:     m.currentRequest(),
+		LastBody:    m.lastBody,
+		Response:    m.response,
+		Bookmarks:   m.bookmarks,
+		YOffset:     m.responseView.YOffset,
+		ActivePanel: m.activePanel,
+	}
+}
+
+// restoreTab loads t into the editor and response panel.
+func (m *Model) restoreTab(t requestTab) {
+	m.loadRequest(t.Request)
+	m.urlInput.SetValue(t.Editor.URL)
+	m.headersInput.SetValue(t.Editor.Headers)
+	m.queryInput.SetValue(t.Editor.Query)
+	m.bodyInput.SetValue(t.Editor.Body)
+	m.lastBody = t.LastBody
+	m.requestError = t.Response.Error
+
+	m.showResponse(t.Response)
+	m.bookmarks = t.Bookmarks
+	m.refreshResponseView()
+	m.responseView.SetYOffset(t.YOffset)
+	m.activePanel = t.ActivePanel
+}
+
+// switchTab makes tab i active, keeping the current tab's state. Tabs
+// can't change while a request is in flight, since its response belongs
+// to the tab that sent it.
+func (m Model) switchTab(i int) (tea.Model, tea.Cmd) {
+	if m.loading {
+		m.statusMessage = "Request in progress, wait for it to finish or press Esc to cancel"
+		return m, nil
+	}
+	if len(m.tabs) < 2 {
+		return m, nil
+	}
+	i = ((i % len(m.tabs)) + len(m.tabs)) % len(m.tabs)
+
+	tabs := append([]requestTab(nil), m.tabs...)
+	tabs[m.activeTab] = m.snapshotTab()
+	m.tabs = tabs
+	m.activeTab = i
+	m.restoreTab(tabs[i])
+	m.statusMessage = ""
+	return m.updateFocus()
+}
+
+// newTab opens an empty tab after the current one and switches to it.
+func (m Model) newTab() (tea.Model, tea.Cmd) {
+	if m.loading {
+		m.statusMessage = "Request in progress, wait for it to finish or press Esc to cancel"
+		return m, nil
+	}
+
+	tabs := make([]requestTab, 0, len(m.tabs)+1)
+	tabs = append(tabs, m.tabs[:m.activeTab+1]...)
+	tabs[m.activeTab] = m.snapshotTab()
+	tabs = append(tabs, requestTab{Request: RequestItem{Method: httpMethods[0]}, ActivePanel: urlPanel})
+	tabs = append(tabs, m.tabs[m.activeTab+1:]...)
+
+	m.tabs = tabs
+	m.activeTab++
+	m.restoreTab(tabs[m.activeTab])
+	m.statusMessage = ""
+	return m.updateFocus()
+}
+
+// closeTab discards the current tab and shows its neighbour. The last tab
+// can't be closed.
+func (m Model) closeTab() (tea.Model, tea.Cmd) {
+	if m.loading {
+		m.statusMessage = "Request in progress, wait for it to finish or press Esc to cancel"
+		return m, nil
+	}
+	if len(m.tabs) < 2 {
+		m.statusMessage = "Can't close the last tab"
+		return m, nil
+	}
+
+	tabs := make([]requestTab, 0, len(m.tabs)-1)
+	tabs = append(tabs, m.tabs[:m.activeTab]...)
+	tabs = append(tabs, m.tabs[m.activeTab+1:]...)
+	if m.activeTab >= len(tabs) {
+		m.activeTab = len(tabs) - 1
+	}
+
+	m.tabs = tabs
+	m.restoreTab(tabs[m.activeTab])
+	m.statusMessage = ""
+	return m.updateFocus()
+}
+
+// renderTabBar lists the open tabs, numbered, with the active one
+// highlighted. It's empty while there's only one tab.
+func (m Model) renderTabBar() string {
+	if len(m.tabs) < 2 {
+		return ""
+	}
+
+	labels := make([]string, len(m.tabs))
+	for i, t := range m.tabs {
+		if i == m.activeTab {
+			t = m.snapshotTab()
+			labels[i] = m.styles.activeTab.Render(fmt.Sprintf("%d %s", i+1, t.label()))
+			continue
+		}
+		labels[i] = m.styles.inactiveTab.Render(fmt.Sprintf("%d %s", i+1, t.label()))
+	}
+	return strings.Join(labels, "")
+}
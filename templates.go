@@ -0,0 +1,84 @@
+package main
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// templatePattern matches {{$name}} dynamic values, optionally followed by
+// space-separated arguments as in {{$randomInt 1 100}}. The $ keeps them
+// apart from environment variables.
+var templatePattern = regexp.MustCompile(`\{\{\s*\$(\w+)((?:\s+[^{}\s]+)*)\s*\}\}`)
+
+// templateFuncs are the dynamic values, after Postman's. A function
+// returns false when its arguments are invalid, leaving the placeholder
+// as is.
+var templateFuncs = map[string]func(args []string) (string, bool){
+	"uuid": func(args []string) (string, bool) {
+		return newUUID(), len(args) == 0
+	},
+	"timestamp": func(args []string) (string, bool) {
+		return strconv.FormatInt(time.Now().Unix(), 10), len(args) == 0
+	},
+	"isoDate": func(args []string) (string, bool) {
+		return time.Now().UTC().Format(time.RFC3339), len(args) == 0
+	},
+	"randomInt": func(args []string) (string, bool) {
+		lo, hi := int64(0), int64(1000)
+		if len(args) == 2 {
+			var err1, err2 error
+			lo, err1 = strconv.ParseInt(args[0], 10, 64)
+			hi, err2 = strconv.ParseInt(args[1], 10, 64)
+			if err1 != nil || err2 != nil {
+				return "", false
+			}
+		} else if len(args) != 0 {
+			return "", false
+		}
+		if hi < lo {
+			return "", false
+		}
+		n, err := rand.Int(rand.Reader, big.NewInt(hi-lo+1))
+		if err != nil {
+			return "", false
+		}
+		return strconv.FormatInt(lo+n.Int64(), 10), true
+	},
+}
+
+// resolveTemplates expands {{$name}} dynamic values in s, each occurrence
+// separately, so two {{$uuid}} get different UUIDs. Unknown names and
+// invalid arguments are left untouched.
+func resolveTemplates(s string) string {
+	if !strings.Contains(s, "{{") {
+		return s
+	}
+	return templatePattern.ReplaceAllStringFunc(s, func(placeholder string) string {
+		match := templatePattern.FindStringSubmatch(placeholder)
+		fn, ok := templateFuncs[match[1]]
+		if !ok {
+			return placeholder
+		}
+		value, ok := fn(strings.Fields(match[2]))
+		if !ok {
+			return placeholder
+		}
+		return value
+	})
+}
+
+// newUUID returns a random (version 4) UUID.
+func newUUID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return ""
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
@@ -0,0 +1,271 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// palette is the set of colors a theme is made of. Every style is derived
+// from it, see newStyles.
+type palette struct {
+	Primary  lipgloss.Color // borders, titles, JSON keys
+	Accent   lipgloss.Color // focus, errors, 5xx
+	Muted    lipgloss.Color // help text, blurred borders
+	Text     lipgloss.Color // emphasised text on the terminal background
+	Contrast lipgloss.Color // text on Primary and Accent backgrounds
+	Success  lipgloss.Color // 2xx, JSON strings
+	Warning  lipgloss.Color // 3xx, search matches
+	Caution  lipgloss.Color // 4xx, JSON numbers
+	Literal  lipgloss.Color // JSON booleans
+}
+
+// themes are the palettes Config.Theme can name.
+var themes = map[string]palette{
+	"dark": {
+		Primary:  "#4ECDC4", // Teal
+		Accent:   "#FF6B6B", // Red
+		Muted:    "#999999", // Gray
+		Text:     "#FFFFFF",
+		Contrast: "#FFFFFF",
+		Success:  "#98C379",
+		Warning:  "#E5C07B",
+		Caution:  "#D19A66",
+		Literal:  "#C678DD",
+	},
+	"light": {
+		Primary:  "#00796B",
+		Accent:   "#C62828",
+		Muted:    "#6E7781",
+		Text:     "#1F2328",
+		Contrast: "#FFFFFF",
+		Success:  "#2E7D32",
+		Warning:  "#9A6700",
+		Caution:  "#BC4C00",
+		Literal:  "#8250DF",
+	},
+}
+
+const defaultTheme = "dark"
+
+// styles are the lipgloss styles the UI renders with, built from a
+// palette by newStyles. Each Model holds its own, so switching the theme
+// of one doesn't restyle another.
+type styles struct {
+	palette
+
+	base           lipgloss.Style
+	focused        lipgloss.Style
+	blurred        lipgloss.Style
+	help           lipgloss.Style
+	methodPanel    lipgloss.Style
+	error          lipgloss.Style
+	statusSuccess  lipgloss.Style
+	statusError    lipgloss.Style
+	header         lipgloss.Style
+	prompt         lipgloss.Style
+	bookmarkMarker lipgloss.Style
+	confirm        lipgloss.Style
+	summary        lipgloss.Style
+	insecureBanner lipgloss.Style
+	activeEnv      lipgloss.Style
+	envBadge       lipgloss.Style
+	protectedEnv   lipgloss.Style
+
+	status2xx   lipgloss.Style
+	status3xx   lipgloss.Style
+	status4xx   lipgloss.Style
+	status5xx   lipgloss.Style
+	statusOther lipgloss.Style
+
+	jsonKey    lipgloss.Style
+	jsonString lipgloss.Style
+	jsonNumber lipgloss.Style
+	jsonBool   lipgloss.Style
+	jsonNull   lipgloss.Style
+
+	searchMatch   lipgloss.Style
+	searchCurrent lipgloss.Style
+
+	diffAdd    lipgloss.Style
+	diffRemove lipgloss.Style
+
+	activeTab   lipgloss.Style
+	inactiveTab lipgloss.Style
+}
+
+// newStyles builds every style from p. Widgets that copy a style when
+// they're created are restyled by Model.applyTheme.
+func newStyles(p palette) styles {
+	st := styles{palette: p}
+
+	st.base = lipgloss.NewStyle().
+		BorderStyle(lipgloss.RoundedBorder())
+	st.focused = st.base.
+		BorderForeground(p.Accent)
+	st.blurred = st.base.
+		BorderForeground(p.Muted)
+	st.help = lipgloss.NewStyle().
+		Foreground(p.Muted)
+	st.methodPanel = st.base.
+		BorderForeground(p.Primary).
+		Padding(1)
+	st.error = lipgloss.NewStyle().
+		Foreground(p.Accent)
+	st.statusSuccess = lipgloss.NewStyle().
+		Foreground(p.Primary)
+	st.statusError = lipgloss.NewStyle().
+		Foreground(p.Accent)
+	st.header = lipgloss.NewStyle().
+		Bold(true).
+		Foreground(p.Contrast).
+		Background(p.Primary).
+		Padding(0, 1)
+	st.prompt = lipgloss.NewStyle().
+		Foreground(p.Primary).
+		Bold(true)
+	st.bookmarkMarker = lipgloss.NewStyle().
+		Foreground(p.Accent)
+	st.confirm = lipgloss.NewStyle().
+		Foreground(p.Accent).
+		Bold(true)
+	st.summary = lipgloss.NewStyle().
+		Foreground(p.Text).
+		Bold(true)
+	st.insecureBanner = lipgloss.NewStyle().
+		Foreground(p.Contrast).
+		Background(p.Accent).
+		Bold(true).
+		Padding(0, 1)
+	st.activeEnv = lipgloss.NewStyle().Foreground(p.Primary).Bold(true)
+	st.envBadge = lipgloss.NewStyle().Foreground(p.Contrast).Background(p.Primary).Padding(0, 1)
+	st.protectedEnv = st.envBadge.Background(p.Accent).Bold(true)
+
+	st.status2xx = lipgloss.NewStyle().Foreground(p.Success).Bold(true)
+	st.status3xx = lipgloss.NewStyle().Foreground(p.Warning).Bold(true)
+	st.status4xx = lipgloss.NewStyle().Foreground(p.Caution).Bold(true)
+	st.status5xx = lipgloss.NewStyle().Foreground(p.Accent).Bold(true)
+	st.statusOther = lipgloss.NewStyle().Foreground(p.Muted).Bold(true)
+
+	st.jsonKey = lipgloss.NewStyle().Foreground(p.Primary)
+	st.jsonString = lipgloss.NewStyle().Foreground(p.Success)
+	st.jsonNumber = lipgloss.NewStyle().Foreground(p.Caution)
+	st.jsonBool = lipgloss.NewStyle().Foreground(p.Literal)
+	st.jsonNull = lipgloss.NewStyle().Foreground(p.Muted)
+
+	st.searchMatch = lipgloss.NewStyle().Foreground(lipgloss.Color("#000000")).Background(p.Warning)
+	st.searchCurrent = lipgloss.NewStyle().Foreground(lipgloss.Color("#000000")).Background(p.Accent).Bold(true)
+
+	st.diffAdd = lipgloss.NewStyle().Foreground(p.Success)
+	st.diffRemove = lipgloss.NewStyle().Foreground(p.Accent)
+
+	st.activeTab = lipgloss.NewStyle().Foreground(p.Contrast).Background(p.Accent).Padding(0, 1)
+	st.inactiveTab = lipgloss.NewStyle().Foreground(p.Muted).Padding(0, 1)
+	return st
+}
+
+// colorPattern matches the colors accepted in Config.Colors: hex as
+// #RGB or #RRGGBB, or an ANSI color number.
+var colorPattern = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+func validColor(c string) bool {
+	if n, err := strconv.Atoi(c); err == nil {
+		return n >= 0 && n <= 255
+	}
+	return colorPattern.MatchString(c)
+}
+
+// resolvePalette returns the named theme with overrides applied on top.
+// Override names are the palette fields, case-insensitive, with an
+// optional "Color" suffix, so "primary" and "primaryColor" both work. An
+// unknown theme falls back to dark; unknown names and invalid colors are
+// skipped. Each problem is reported as a warning.
+func resolvePalette(theme string, overrides map[string]string) (palette, []string) {
+	var warnings []string
+	p, ok := themes[strings.ToLower(theme)]
+	if !ok {
+		if theme != "" {
+			warnings = append(warnings, fmt.Sprintf("unknown theme %q, using %s", theme, defaultTheme))
+		}
+		p = themes[defaultTheme]
+	}
+
+	fields := map[string]*lipgloss.Color{
+		"primary":  &p.Primary,
+		"accent":   &p.Accent,
+		"muted":    &p.Muted,
+		"text":     &p.Text,
+		"contrast": &p.Contrast,
+		"success":  &p.Success,
+		"warning":  &p.Warning,
+		"caution":  &p.Caution,
+		"literal":  &p.Literal,
+	}
+	names := make([]string, 0, len(overrides))
+	for name := range overrides {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		field, ok := fields[strings.TrimSuffix(strings.ToLower(name), "color")]
+		if !ok {
+			warnings = append(warnings, fmt.Sprintf("unknown color %q", name))
+			continue
+		}
+		value := strings.TrimSpace(overrides[name])
+		if !validColor(value) {
+			warnings = append(warnings, fmt.Sprintf("invalid color %q for %q", value, name))
+			continue
+		}
+		*field = lipgloss.Color(value)
+	}
+	return p, warnings
+}
+
+// themeNames lists the themes in the order the theme key cycles through.
+func themeNames() []string {
+	names := make([]string, 0, len(themes))
+	for name := range themes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// nextTheme returns the theme after current in themeNames.
+func nextTheme(current string) string {
+	names := themeNames()
+	for i, name := range names {
+		if strings.EqualFold(name, current) {
+			return names[(i+1)%len(names)]
+		}
+	}
+	return names[0]
+}
+
+// applyTheme switches to the named theme with the configured color
+// overrides, restyles the widgets that copied the old colors and
+// re-renders the response. It returns the palette's warnings.
+func (m *Model) applyTheme(theme string) []string {
+	var overrides map[string]string
+	if m.configManager != nil {
+		overrides = m.configManager.Config.Colors
+	}
+	p, warnings := resolvePalette(theme, overrides)
+	m.styles = newStyles(p)
+
+	styleMethodList(&m.styles, &m.methodList)
+	for _, l := range []*list.Model{&m.pathList, &m.timelineList, &m.savedList, &m.collectionList, &m.historyList, &m.paletteList} {
+		stylePanelList(&m.styles, l)
+	}
+	m.responseView.Style = m.styles.blurred
+	m.spinner.Style = lipgloss.NewStyle().Foreground(p.Accent)
+	m.renderBody()
+	m.refreshResponseView()
+	return warnings
+}
@@ -0,0 +1,34 @@
+package main
+
+import "testing"
+
+func TestApplyThemeIsPerModel(t *testing.T) {
+	dark := initialModel(t.TempDir())
+	light := initialModel(t.TempDir())
+
+	dark.applyTheme("dark")
+	light.applyTheme("light")
+
+	if dark.styles.Primary != themes["dark"].Primary {
+		t.Errorf("dark model primary = %v after another model switched to light", dark.styles.Primary)
+	}
+	if light.styles.Primary != themes["light"].Primary {
+		t.Errorf("light model primary = %v, want %v", light.styles.Primary, themes["light"].Primary)
+	}
+	if got, want := light.styles.focused.GetBorderTopForeground(), themes["light"].Accent; got != want {
+		t.Errorf("light focused border = %v, want %v", got, want)
+	}
+}
+
+func TestResolvePaletteOverrides(t *testing.T) {
+	p, warnings := resolvePalette("light", map[string]string{"primaryColor": "#123456", "bogus": "#fff", "accent": "nope"})
+	if p.Primary != "#123456" {
+		t.Errorf("primary = %v, want the override", p.Primary)
+	}
+	if p.Accent != themes["light"].Accent {
+		t.Errorf("accent = %v, want the theme's after an invalid override", p.Accent)
+	}
+	if len(warnings) != 2 {
+		t.Errorf("warnings = %v, want one for the unknown name and one for the invalid color", warnings)
+	}
+}
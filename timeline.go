@@ -0,0 +1,41 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// maxTimelineEntries caps the in-memory session timeline. Older entries
+// are dropped; the persisted history is unaffected.
+const maxTimelineEntries = 50
+
+// timelineEntry is one request sent during this session together with the
+// response it produced, so the response can be restored later.
+type timelineEntry struct {
+	At       time.Time
+	Request  RequestItem
+	Response Response
+}
+
+func (e timelineEntry) Title() string {
+	return fmt.Sprintf("%s  %s %s", e.At.Format("15:04:05"), e.Request.Method, e.Request.URL)
+}
+
+func (e timelineEntry) Description() string {
+	if e.Response.Error != nil {
+		return "error: " + e.Response.Error.Error()
+	}
+	return fmt.Sprintf("%s • %v", e.Response.Status, e.Response.ResponseTime.Round(time.Millisecond))
+}
+
+func (e timelineEntry) FilterValue() string { return e.Request.Method + " " + e.Request.URL }
+
+// appendTimeline adds e to the timeline, dropping the oldest entries past
+// maxTimelineEntries.
+func appendTimeline(entries []timelineEntry, e timelineEntry) []timelineEntry {
+	entries = append(entries, e)
+	if len(entries) > maxTimelineEntries {
+		entries = entries[len(entries)-maxTimelineEntries:]
+	}
+	return entries
+}
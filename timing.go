@@ -0,0 +1,192 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptrace"
+	"net/textproto"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Timings is the per-phase breakdown of a request collected with
+// httptrace. A zero duration means the phase didn't happen, e.g. no DNS
+// lookup for an IP address or no TLS handshake on a reused connection.
+type Timings struct {
+	DNS        time.Duration `json:"dns,omitempty"`
+	Connect    time.Duration `json:"connect,omitempty"`
+	TLS        time.Duration `json:"tls,omitempty"`
+	TTFB       time.Duration `json:"ttfb,omitempty"`
+	Total      time.Duration `json:"total"`
+	ReusedConn bool          `json:"reused_conn,omitempty"`
+}
+
+// requestTracer records phase boundaries. Callbacks may fire from several
+// goroutines when the transport races dials, hence the mutex.
+type requestTracer struct {
+	mu           sync.Mutex
+	start        time.Time
+	dnsStart     time.Time
+	dnsDone      time.Time
+	connectStart time.Time
+	connectDone  time.Time
+	tlsStart     time.Time
+	tlsDone      time.Time
+	firstByte    time.Time
+	reused       bool
+	info         []InformationalResponse
+}
+
+// InformationalResponse is a 1xx response (100 Continue, 103 Early Hints,
+// ...) received before the final response.
+type InformationalResponse struct {
+	StatusCode int
+	Headers    http.Header
+}
+
+// withTracer attaches a tracer to ctx, starting the clock now.
+func withTracer(ctx context.Context) (context.Context, *requestTracer) {
+	t := &requestTracer{start: time.Now()}
+	stamp := func(dst *time.Time) {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		if dst.IsZero() {
+			*dst = time.Now()
+		}
+	}
+
+	trace := &httptrace.ClientTrace{
+		DNSStart:     func(httptrace.DNSStartInfo) { stamp(&t.dnsStart) },
+		DNSDone:      func(httptrace.DNSDoneInfo) { stamp(&t.dnsDone) },
+		ConnectStart: func(string, string) { stamp(&t.connectStart) },
+		ConnectDone: func(_, _ string, err error) {
+			if err == nil {
+				stamp(&t.connectDone)
+			}
+		},
+		TLSHandshakeStart: func() { stamp(&t.tlsStart) },
+		TLSHandshakeDone: func(_ tls.ConnectionState, err error) {
+			if err == nil {
+				stamp(&t.tlsDone)
+			}
+		},
+		GotConn: func(info httptrace.GotConnInfo) {
+			t.mu.Lock()
+			t.reused = info.Reused
+			t.mu.Unlock()
+		},
+		GotFirstResponseByte: func() { stamp(&t.firstByte) },
+		Got1xxResponse: func(code int, header textproto.MIMEHeader) error {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.info = append(t.info, InformationalResponse{
+				StatusCode: code,
+				Headers:    http.Header(header).Clone(),
+			})
+			return nil
+		},
+	}
+	return httptrace.WithClientTrace(ctx, trace), t
+}
+
+// timings converts the recorded timestamps into phase durations. total is
+// the overall response time measured by the caller.
+func (t *requestTracer) timings(total time.Duration) Timings {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	span := func(from, to time.Time) time.Duration {
+		if from.IsZero() || to.IsZero() || to.Before(from) {
+			return 0
+		}
+		return to.Sub(from)
+	}
+
+	return Timings{
+		DNS:        span(t.dnsStart, t.dnsDone),
+		Connect:    span(t.connectStart, t.connectDone),
+		TLS:        span(t.tlsStart, t.tlsDone),
+		TTFB:       span(t.start, t.firstByte),
+		Total:      total,
+		ReusedConn: t.reused,
+	}
+}
+
+// informational returns the 1xx responses seen before the final one.
+func (t *requestTracer) informational() []InformationalResponse {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.info
+}
+
+// formatTimings renders the breakdown as aligned text for reports. Phases
+// that didn't occur are left out.
+func formatTimings(t Timings) string {
+	var sb strings.Builder
+	row := func(label string, d time.Duration) {
+		if d > 0 {
+			sb.WriteString(fmt.Sprintf("%-10s%v\n", label+":", d.Round(time.Microsecond)))
+		}
+	}
+
+	row("DNS", t.DNS)
+	row("Connect", t.Connect)
+	row("TLS", t.TLS)
+	row("TTFB", t.TTFB)
+	row("Total", t.Total)
+	if t.ReusedConn {
+		sb.WriteString("(reused connection)\n")
+	}
+	return sb.String()
+}
+
+// formatTimingsInline renders the phases on one line for the response
+// panel, e.g. "DNS 3ms · Connect 12ms · TLS 25ms · TTFB 140ms". Phases
+// that didn't occur are left out, and a reused connection is noted.
+func formatTimingsInline(t Timings) string {
+	var parts []string
+	phase := func(label string, d time.Duration) {
+		if d > 0 {
+			parts = append(parts, label+" "+d.Round(100*time.Microsecond).String())
+		}
+	}
+
+	phase("DNS", t.DNS)
+	phase("Connect", t.Connect)
+	phase("TLS", t.TLS)
+	phase("TTFB", t.TTFB)
+	if t.ReusedConn {
+		parts = append(parts, "reused connection")
+	}
+	return strings.Join(parts, " · ")
+}
+
+// timingsJSON renders the breakdown as JSON with millisecond values.
+func timingsJSON(t Timings) (string, error) {
+	ms := func(d time.Duration) *float64 {
+		if d <= 0 {
+			return nil
+		}
+		v := float64(d.Microseconds()) / 1000
+		return &v
+	}
+
+	out := struct {
+		DNS        *float64 `json:"dns_ms,omitempty"`
+		Connect    *float64 `json:"connect_ms,omitempty"`
+		TLS        *float64 `json:"tls_ms,omitempty"`
+		TTFB       *float64 `json:"ttfb_ms,omitempty"`
+		Total      *float64 `json:"total_ms"`
+		ReusedConn bool     `json:"reused_connection"`
+	}{ms(t.DNS), ms(t.Connect), ms(t.TLS), ms(t.TTFB), ms(t.Total), t.ReusedConn}
+
+	bytes, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(bytes), nil
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestDoRequestRecordsEarlyHints(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Link", "</style.css>; rel=preload; as=style")
+		w.WriteHeader(http.StatusEarlyHints)
+		w.Header().Del("Link")
+		w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	res := doRequest(context.Background(), srv.Client(), RequestSpec{
+		Method:   http.MethodGet,
+		URL:      srv.URL,
+		Timeout:  5 * time.Second,
+		MaxBytes: defaultMaxResponseBytes,
+	})
+	if res.Error != nil {
+		t.Fatalf("doRequest: %v", res.Error)
+	}
+	if res.StatusCode != http.StatusOK {
+		t.Errorf("status = %d, want 200", res.StatusCode)
+	}
+	if len(res.Informational) != 1 {
+		t.Fatalf("informational = %+v, want one 103", res.Informational)
+	}
+	hint := res.Informational[0]
+	if hint.StatusCode != http.StatusEarlyHints {
+		t.Errorf("informational status = %d, want 103", hint.StatusCode)
+	}
+	if got := hint.Headers.Get("Link"); got != "</style.css>; rel=preload; as=style" {
+		t.Errorf("Link = %q", got)
+	}
+}
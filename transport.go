@@ -0,0 +1,137 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const defaultIdleConnTimeout = 90
+
+// connStats counts connections made through the shared transport. Open
+// minus in-flight requests approximates the idle pool; HTTP/2 multiplexing
+// makes it only an estimate.
+type connStats struct {
+	dialed   atomic.Int64
+	open     atomic.Int64
+	inFlight atomic.Int64
+}
+
+func (s *connStats) idle() int64 {
+	if idle := s.open.Load() - s.inFlight.Load(); idle > 0 {
+		return idle
+	}
+	return 0
+}
+
+// countedConn decrements the open count once when closed.
+type countedConn struct {
+	net.Conn
+	stats *connStats
+	once  sync.Once
+}
+
+func (c *countedConn) Close() error {
+	c.once.Do(func() { c.stats.open.Add(-1) })
+	return c.Conn.Close()
+}
+
+// sharedTransport returns the transport used for every request, creating it
+// from the config on first use so connections are reused across requests,
+// polling and batches.
+func (cm *ConfigManager) sharedTransport() http.RoundTripper {
+	if cm == nil {
+		return http.DefaultTransport
+	}
+
+	cm.transportOnce.Do(func() {
+		cm.mu.RLock()
+		idleTimeout := cm.Config.IdleConnTimeout
+		perHost := cm.Config.MaxIdleConnsPerHost
+		insecure := cm.Config.InsecureSkipVerify
+		cm.mu.RUnlock()
+
+		if idleTimeout <= 0 {
+			idleTimeout = defaultIdleConnTimeout
+		}
+		if perHost <= 0 {
+			perHost = defaultMaxConcurrency
+		}
+
+		dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+		t := http.DefaultTransport.(*http.Transport).Clone()
+		t.IdleConnTimeout = time.Duration(idleTimeout) * time.Second
+		t.MaxIdleConnsPerHost = perHost
+		if insecure {
+			t.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+		}
+		if proxy, err := cm.proxyFunc(); err != nil {
+			// Fail each request with the config error rather than
+			// silently connecting directly
+			t.Proxy = func(*http.Request) (*url.URL, error) { return nil, err }
+		} else {
+			t.Proxy = proxy
+		}
+		t.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+			conn, err := dialer.DialContext(ctx, network, addr)
+			if err != nil {
+				return nil, err
+			}
+			cm.conns.dialed.Add(1)
+			cm.conns.open.Add(1)
+			return &countedConn{Conn: conn, stats: &cm.conns}, nil
+		}
+		cm.transport = t
+	})
+	return cm.transport
+}
+
+// insecureSkipVerify reports whether TLS certificates go unverified. It's
+// read once with the transport, so a config change needs a restart.
+func (cm *ConfigManager) insecureSkipVerify() bool {
+	if cm == nil {
+		return false
+	}
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+	return cm.Config.InsecureSkipVerify
+}
+
+// closeIdleConnections drops every idle pooled connection.
+func (cm *ConfigManager) closeIdleConnections() {
+	if t, ok := cm.sharedTransport().(*http.Transport); ok {
+		t.CloseIdleConnections()
+	}
+}
+
+// renderConnStats describes the shared transport's connections.
+func (cm *ConfigManager) renderConnStats() string {
+	if cm == nil {
+		return "Connection stats unavailable"
+	}
+
+	cm.mu.RLock()
+	idleTimeout, perHost := cm.Config.IdleConnTimeout, cm.Config.MaxIdleConnsPerHost
+	cm.mu.RUnlock()
+	if idleTimeout <= 0 {
+		idleTimeout = defaultIdleConnTimeout
+	}
+	if perHost <= 0 {
+		perHost = defaultMaxConcurrency
+	}
+
+	return fmt.Sprintf("Connections (x: close idle • esc: close)\n\n"+
+		"Open:        %d\n"+
+		"In use:      %d (approx.)\n"+
+		"Idle:        %d (approx.)\n"+
+		"Dialed:      %d total this session\n\n"+
+		"Idle timeout: %ds • Max idle per host: %d",
+		cm.conns.open.Load(), cm.conns.open.Load()-cm.conns.idle(), cm.conns.idle(),
+		cm.conns.dialed.Load(), idleTimeout, perHost)
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+var placeholderPattern = regexp.MustCompile(`\{\{[^{}]*\}\}`)
+
+// normalizeURL percent-encodes the path and query of raw so it can be sent
+// as-is: spaces and reserved characters are escaped, while sequences that
+// are already valid escapes are left alone to avoid double-encoding.
+// {{VAR}} placeholders are preserved for environment substitution.
+func normalizeURL(raw string) (string, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return "", fmt.Errorf("URL is empty")
+	}
+
+	// Swap placeholders for inert tokens so their braces aren't escaped
+	var placeholders []string
+	protected := placeholderPattern.ReplaceAllStringFunc(raw, func(p string) string {
+		placeholders = append(placeholders, p)
+		return fmt.Sprintf("PLACEHOLDER%dX", len(placeholders)-1)
+	})
+
+	fragment := ""
+	if i := strings.Index(protected, "#"); i != -1 {
+		protected, fragment = protected[:i], protected[i+1:]
+	}
+	query := ""
+	hasQuery := false
+	if i := strings.Index(protected, "?"); i != -1 {
+		protected, query, hasQuery = protected[:i], protected[i+1:], true
+	}
+
+	u, err := url.Parse(strings.ReplaceAll(protected, " ", "%20"))
+	if err != nil {
+		return "", fmt.Errorf("invalid URL: %w", err)
+	}
+	if hasQuery {
+		u.RawQuery = normalizeQuery(query)
+	}
+	if fragment != "" {
+		u.Fragment = fragment
+	}
+
+	result := u.String()
+	for i, p := range placeholders {
+		result = strings.Replace(result, fmt.Sprintf("PLACEHOLDER%dX", i), p, 1)
+	}
+	return result, nil
+}
+
+// normalizeQuery re-encodes each key=value pair, keeping the original
+// order. Pairs that are already encoded decode and re-encode to the same
+// text.
+func normalizeQuery(query string) string {
+	if query == "" {
+		return ""
+	}
+
+	pairs := strings.Split(query, "&")
+	for i, pair := range pairs {
+		key, value, hasValue := strings.Cut(pair, "=")
+		pairs[i] = reencodeQueryPart(key)
+		if hasValue {
+			pairs[i] += "=" + reencodeQueryPart(value)
+		}
+	}
+	return strings.Join(pairs, "&")
+}
+
+func reencodeQueryPart(s string) string {
+	if decoded, err := url.QueryUnescape(s); err == nil {
+		s = decoded
+	}
+	return url.QueryEscape(s)
+}
+
+// Trailing-slash modes for Config.TrailingSlash and RequestItem.TrailingSlash.
+const (
+	trailingSlashLeave = "leave"
+	trailingSlashAdd   = "add"
+	trailingSlashStrip = "strip"
+)
+
+var trailingSlashModes = []string{trailingSlashLeave, trailingSlashAdd, trailingSlashStrip}
+
+// applyTrailingSlash adds or strips the trailing slash on the path of raw.
+// The root path is left alone, as are URLs that don't parse so the request
+// builder can report the error itself.
+func applyTrailingSlash(raw, mode string) string {
+	if mode != trailingSlashAdd && mode != trailingSlashStrip {
+		return raw
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil || u.Path == "/" || (u.Path == "" && mode == trailingSlashStrip) {
+		return raw
+	}
+
+	switch mode {
+	case trailingSlashAdd:
+		if !strings.HasSuffix(u.Path, "/") {
+			u.Path += "/"
+			if u.RawPath != "" {
+				u.RawPath += "/"
+			}
+		}
+	case trailingSlashStrip:
+		u.Path = strings.TrimRight(u.Path, "/")
+		u.RawPath = strings.TrimRight(u.RawPath, "/")
+		if u.Path == "" {
+			u.Path = "/"
+		}
+	}
+	return u.String()
+}
+
+// nextTrailingSlashMode cycles a per-request mode through "" (use the
+// config default), leave, add and strip.
+func nextTrailingSlashMode(mode string) string {
+	for i, m := range trailingSlashModes {
+		if m == mode {
+			if i == len(trailingSlashModes)-1 {
+				return ""
+			}
+			return trailingSlashModes[i+1]
+		}
+	}
+	return trailingSlashModes[0]
+}
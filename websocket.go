@@ -0,0 +1,229 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/gorilla/websocket"
+)
+
+// wsFrame is a message sent or received on the WebSocket connection, or a
+// note about the connection itself.
+type wsFrame struct {
+	At       time.Time
+	Outgoing bool
+	Binary   bool
+	Text     string
+	// Note is set instead of Text for connection events.
+	Note string
+}
+
+// wsSession is an open WebSocket connection. Sends run as commands, so
+// writes are serialised through mu; gorilla allows one writer at a time.
+type wsSession struct {
+	conn *websocket.Conn
+	url  string
+	mu   sync.Mutex
+}
+
+type wsConnectedMsg struct {
+	session *wsSession
+	err     error
+}
+
+type wsFrameMsg struct {
+	session *wsSession
+	frame   wsFrame
+}
+
+type wsClosedMsg struct {
+	session *wsSession
+	err     error
+}
+
+// wsHandshakeHeaders are set by the dialer itself; sending them from the
+// headers editor as well makes the handshake fail.
+var wsHandshakeHeaders = []string{"Upgrade", "Connection", "Sec-Websocket-Key", "Sec-Websocket-Version", "Sec-Websocket-Extensions"}
+
+// isWebSocketURL reports whether u uses the ws or wss scheme.
+func isWebSocketURL(u string) bool {
+	lower := strings.ToLower(strings.TrimSpace(u))
+	return strings.HasPrefix(lower, "ws://") || strings.HasPrefix(lower, "wss://")
+}
+
+// connectWebSocket returns a command that opens a WebSocket connection to
+// reqItem's URL with its headers and auth, resolved like an HTTP request.
+// The proxy and TLS settings of the shared transport apply.
+func (m Model) connectWebSocket(ctx context.Context, reqItem RequestItem) tea.Cmd {
+	url := m.resolveURL(reqItem, m.resolveForSend)
+	headers := http.Header{}
+	for k, v := range reqItem.Headers {
+		headers.Add(k, m.resolveForSend(v))
+	}
+	if authHeader := reqItem.Auth.header(m.resolveForSend); authHeader != "" {
+		headers.Set("Authorization", authHeader)
+	}
+	for _, h := range wsHandshakeHeaders {
+		headers.Del(h)
+	}
+
+	timeout := 30 * time.Second
+	if m.configManager != nil {
+		if seconds := m.configManager.requestTimeout(reqItem.Timeout); seconds > 0 {
+			timeout = time.Duration(seconds) * time.Second
+		}
+	}
+	dialer := &websocket.Dialer{HandshakeTimeout: timeout, Jar: m.cookieJar()}
+	if t, ok := m.configManager.sharedTransport().(*http.Transport); ok {
+		dialer.Proxy = t.Proxy
+		dialer.TLSClientConfig = t.TLSClientConfig
+		dialer.NetDialContext = t.DialContext
+	}
+
+	return func() tea.Msg {
+		conn, resp, err := dialer.DialContext(ctx, url, headers)
+		if err != nil {
+			if ctx.Err() == context.Canceled {
+				return wsConnectedMsg{err: errRequestCancelled}
+			}
+			if resp != nil {
+				err = fmt.Errorf("WebSocket handshake failed: %s", resp.Status)
+			}
+			return wsConnectedMsg{err: err}
+		}
+		return wsConnectedMsg{session: &wsSession{conn: conn, url: url}}
+	}
+}
+
+// readWebSocket returns a command that waits for the next message on s.
+// Update issues it again after each frame, so messages stream in until
+// the connection closes.
+func readWebSocket(s *wsSession) tea.Cmd {
+	return func() tea.Msg {
+		kind, data, err := s.conn.ReadMessage()
+		if err != nil {
+			return wsClosedMsg{session: s, err: err}
+		}
+		return wsFrameMsg{session: s, frame: wsFrame{At: time.Now(), Binary: kind == websocket.BinaryMessage, Text: string(data)}}
+	}
+}
+
+// sendWebSocket returns a command that sends text as a text message on s
+// and reports it as an outgoing frame.
+func sendWebSocket(s *wsSession, text string) tea.Cmd {
+	return func() tea.Msg {
+		s.mu.Lock()
+		err := s.conn.WriteMessage(websocket.TextMessage, []byte(text))
+		s.mu.Unlock()
+		if err != nil {
+			return wsFrameMsg{session: s, frame: wsFrame{At: time.Now(), Note: "Send failed: " + err.Error()}}
+		}
+		return wsFrameMsg{session: s, frame: wsFrame{At: time.Now(), Outgoing: true, Text: text}}
+	}
+}
+
+// close sends a normal close frame and closes the connection, which ends
+// the pending read with a wsClosedMsg.
+func (s *wsSession) close() {
+	deadline := time.Now().Add(time.Second)
+	_ = s.conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""), deadline)
+	_ = s.conn.Close()
+}
+
+// closeReason describes why a connection ended for the log.
+func closeReason(err error) string {
+	var closeErr *websocket.CloseError
+	if errors.As(err, &closeErr) {
+		reason := fmt.Sprintf("Connection closed (%d)", closeErr.Code)
+		if closeErr.Text != "" {
+			reason += ": " + closeErr.Text
+		}
+		return reason
+	}
+	return "Connection closed"
+}
+
+// appendWebSocketFrame adds f to the log, keeping the view at the bottom
+// if it was there so new messages scroll into view.
+func (m *Model) appendWebSocketFrame(f wsFrame) {
+	follow := m.responseView.AtBottom()
+	m.wsFrames = append(m.wsFrames, f)
+	m.refreshResponseView()
+	if follow {
+		m.responseView.GotoBottom()
+	}
+}
+
+// formatWebSocketLog renders the frames of the current or last connection,
+// one per line with a timestamp and an arrow for the direction.
+func formatWebSocketLog(st *styles, frames []wsFrame, open bool) string {
+	var sb strings.Builder
+	state := "closed"
+	if open {
+		state = "open"
+	}
+	sb.WriteString(fmt.Sprintf("WebSocket (%s)\n\n", state))
+	for _, f := range frames {
+		stamp := f.At.Format("15:04:05.000")
+		// Frames and close reasons come from the server, so they mustn't
+		// reach the terminal with escape sequences intact
+		text := sanitizeForTerminal(f.Text)
+		switch {
+		case f.Note != "":
+			sb.WriteString(st.help.Render(stamp+" • "+sanitizeForTerminal(f.Note)) + "\n")
+		case f.Binary:
+			sb.WriteString(fmt.Sprintf("%s ← [binary, %s]\n", stamp, formatBytes(int64(len(f.Text)))))
+		case f.Outgoing:
+			sb.WriteString(st.prompt.Render(stamp+" →") + " " + text + "\n")
+		default:
+			sb.WriteString(stamp + " ← " + text + "\n")
+		}
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+// updateWebSocket handles the connection's messages.
+func (m Model) updateWebSocket(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case wsConnectedMsg:
+		m.loading = false
+		if m.cancelRequest != nil {
+			m.cancelRequest()
+			m.cancelRequest = nil
+		}
+		if msg.err != nil {
+			m.requestError = msg.err
+			return m, nil
+		}
+		m.showResponse(Response{})
+		m.ws = msg.session
+		m.appendWebSocketFrame(wsFrame{At: time.Now(), Note: "Connected to " + msg.session.url})
+		m.statusMessage = "WebSocket open: " + keys.SendMessage.Help().Key + " sends the body, esc closes"
+		return m, readWebSocket(msg.session)
+
+	case wsFrameMsg:
+		if msg.session != m.ws {
+			return m, nil
+		}
+		m.appendWebSocketFrame(msg.frame)
+		if msg.frame.Outgoing || msg.frame.Note != "" {
+			return m, nil
+		}
+		return m, readWebSocket(msg.session)
+
+	case wsClosedMsg:
+		if msg.session != m.ws {
+			return m, nil
+		}
+		m.ws = nil
+		m.appendWebSocketFrame(wsFrame{At: time.Now(), Note: closeReason(msg.err)})
+		m.statusMessage = "WebSocket closed"
+	}
+	return m, nil
+}
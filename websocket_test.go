@@ -0,0 +1,25 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFormatWebSocketLogSanitizes(t *testing.T) {
+	frames := []wsFrame{
+		{At: time.Now(), Text: "hi\x1b]0;pwned\x07there"},
+		{At: time.Now(), Note: "closed: \x1b[2Jbye"},
+	}
+
+	st := newStyles(themes[defaultTheme])
+	out := formatWebSocketLog(&st, frames, false)
+	for _, seq := range []string{"\x1b]0;", "\x07", "\x1b[2J"} {
+		if strings.Contains(out, seq) {
+			t.Errorf("log contains escape sequence %q:\n%q", seq, out)
+		}
+	}
+	if !strings.Contains(out, "pwned") || !strings.Contains(out, "bye") {
+		t.Errorf("log lost the frame text: %q", out)
+	}
+}